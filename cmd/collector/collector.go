@@ -68,7 +68,7 @@ func printIPFIXMessage(msg *entities.Message) {
 	fmt.Fprintf(&buf, "  Sequence No.: %v,  Observation Domain ID: %v\n", msg.GetSequenceNum(), msg.GetObsDomainID())
 
 	set := msg.GetSet()
-	if set.GetSetType() == entities.Template {
+	if set.GetSetType() == entities.Template || set.GetSetType() == entities.OptionsTemplate {
 		fmt.Fprint(&buf, "TEMPLATE SET:\n")
 		for i, record := range set.GetRecords() {
 			fmt.Fprintf(&buf, "  TEMPLATE RECORD-%d:\n", i)
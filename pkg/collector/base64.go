@@ -0,0 +1,46 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/vmware/go-ipfix/pkg/entities"
+)
+
+// DecodeBase64 decodes an IPFIX message encoded as a standard base64 string,
+// e.g. as found in logs. Templates are looked up in, and template sets are
+// added to, the given TemplateStore, so that the same store can be used to
+// decode a sequence of messages. If templates is nil, only messages which do
+// not depend on previously received templates can be decoded. If the message
+// has several sets, the message of the first set is returned.
+func DecodeBase64(s string, templates TemplateStore) (*entities.Message, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("error when decoding base64 message: %v", err)
+	}
+	if templates == nil {
+		templates = make(memoryTemplateStore)
+	}
+	// Templates added by the decoder never expire, as for TCP.
+	cp := &CollectingProcess{
+		templateStore: templates,
+		protocol:      "tcp",
+	}
+	return cp.decodeMessage(bytes.NewBuffer(data), "", time.Now())
+}
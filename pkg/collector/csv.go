@@ -0,0 +1,92 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/vmware/go-ipfix/pkg/entities"
+)
+
+// CSVSink writes decoded data records to an io.Writer as CSV rows. The columns
+// are the elements of the template of the record, named like in the JSON
+// encoding of the record, i.e. prefixed with the enterprise ID for
+// enterprise-specific elements. A header row with the column names is written
+// before the first record, and again whenever the columns differ from the
+// previous record's, e.g. when records of different templates are interleaved.
+// Values are formatted with entities.FormatElementValue.
+type CSVSink struct {
+	writer *csv.Writer
+	header []string
+}
+
+// NewCSVSink creates a CSVSink writing to w.
+func NewCSVSink(w io.Writer) *CSVSink {
+	return &CSVSink{writer: csv.NewWriter(w)}
+}
+
+// Consume writes the data records of the messages received from msgCh, e.g.
+// the channel returned by GetMsgChan, until the channel is closed. Template
+// messages are skipped. It returns the first error encountered when writing.
+func (s *CSVSink) Consume(msgCh <-chan *entities.Message) error {
+	for message := range msgCh {
+		if err := s.WriteMessage(message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteMessage writes the data records of the message and flushes the writer.
+// Messages which do not contain data records are ignored.
+func (s *CSVSink) WriteMessage(message *entities.Message) error {
+	set := message.GetSet()
+	if set == nil || set.GetSetType() != entities.Data {
+		return nil
+	}
+	for _, record := range set.GetRecords() {
+		if err := s.writeRecord(record); err != nil {
+			return err
+		}
+	}
+	s.writer.Flush()
+	if err := s.writer.Error(); err != nil {
+		return fmt.Errorf("error when writing CSV records: %v", err)
+	}
+	return nil
+}
+
+func (s *CSVSink) writeRecord(record entities.Record) error {
+	orderedElements := record.GetOrderedElementList()
+	header := make([]string, len(orderedElements))
+	row := make([]string, len(orderedElements))
+	for i, element := range orderedElements {
+		header[i] = entities.GetQualifiedName(element.GetInfoElement())
+		row[i] = entities.FormatElementValue(element)
+	}
+	if !slices.Equal(header, s.header) {
+		if err := s.writer.Write(header); err != nil {
+			return fmt.Errorf("error when writing CSV header: %v", err)
+		}
+		s.header = header
+	}
+	if err := s.writer.Write(row); err != nil {
+		return fmt.Errorf("error when writing CSV record: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/klog/v2"
+
+	"github.com/vmware/go-ipfix/pkg/entities"
+	"github.com/vmware/go-ipfix/pkg/registry"
+)
+
+// elementDefinition is an element described in an RFC 5610 type information
+// options record. index is the informationElementIndex of the record, or -1 if
+// it is not present.
+type elementDefinition struct {
+	element *entities.InfoElement
+	index   int
+}
+
+// updateElementDefinitions learns the elements described by the RFC 5610 type
+// information options records of the set, i.e. the records containing
+// informationElementId, informationElementDataType and informationElementName.
+// The enterprise of the element is given by privateEnterpriseNumber, and its
+// position by informationElementIndex. The elements are only known to the
+// collecting process, and the registry is left unchanged.
+func (cp *CollectingProcess) updateElementDefinitions(obsDomainID uint32, set entities.Set) {
+	for _, record := range set.GetRecords() {
+		definition, err := getElementDefinition(record)
+		if err != nil {
+			klog.ErrorS(err, "Invalid element definition in options record", "observationDomainID", obsDomainID)
+			continue
+		}
+		if definition == nil {
+			continue
+		}
+		cp.mutex.Lock()
+		cp.addElementDefinitionLocked(obsDomainID, definition)
+		cp.mutex.Unlock()
+	}
+}
+
+func getElementDefinition(record entities.Record) (*elementDefinition, error) {
+	idElement, _, exist := record.GetInfoElementWithValue("informationElementId")
+	if !exist {
+		return nil, nil
+	}
+	dataTypeElement, _, dataTypeExist := record.GetInfoElementWithValue("informationElementDataType")
+	nameElement, _, nameExist := record.GetInfoElementWithValue("informationElementName")
+	if !dataTypeExist || !nameExist {
+		return nil, nil
+	}
+	var enterpriseID uint32
+	if enterpriseElement, _, exist := record.GetInfoElementWithValue("privateEnterpriseNumber"); exist {
+		enterpriseID = enterpriseElement.GetUnsigned32Value()
+	}
+	dataType := entities.IEDataType(dataTypeElement.GetUnsigned8Value())
+	length, exist := entities.InfoElementLength[dataType]
+	if !exist {
+		return nil, fmt.Errorf("element %s has unsupported data type %d", nameElement.GetStringValue(), dataType)
+	}
+	definition := &elementDefinition{
+		element: entities.NewInfoElement(nameElement.GetStringValue(), idElement.GetUnsigned16Value(), dataType, enterpriseID, length),
+		index:   -1,
+	}
+	if indexElement, _, exist := record.GetInfoElementWithValue("informationElementIndex"); exist {
+		definition.index = int(indexElement.GetUnsigned16Value())
+	}
+	return definition, nil
+}
+
+// getInfoElementFromID returns the element with the given ID from the registry.
+// Elements missing from the registry are looked up in the elements learned from
+// the options records of the observation domain.
+func (cp *CollectingProcess) getInfoElementFromID(obsDomainID uint32, elementID uint16, enterpriseID uint32) (*entities.InfoElement, error) {
+	element, err := registry.GetInfoElementFromID(elementID, enterpriseID)
+	if err == nil || !cp.registerElementsFromOptions {
+		return element, err
+	}
+	cp.mutex.RLock()
+	defer cp.mutex.RUnlock()
+	for _, definition := range cp.elementDefinitions[obsDomainID] {
+		if definition.element.ElementId == elementID && definition.element.EnterpriseId == enterpriseID {
+			return definition.element, nil
+		}
+	}
+	return nil, err
+}
+
+func (cp *CollectingProcess) addElementDefinitionLocked(obsDomainID uint32, definition *elementDefinition) {
+	if cp.elementDefinitions == nil {
+		cp.elementDefinitions = make(map[uint32][]*elementDefinition)
+	}
+	definitions := cp.elementDefinitions[obsDomainID]
+	for i, existing := range definitions {
+		if existing.element.ElementId == definition.element.ElementId && existing.element.EnterpriseId == definition.element.EnterpriseId {
+			definitions = append(definitions[:i], definitions[i+1:]...)
+			break
+		}
+	}
+	definitions = append(definitions, definition)
+	// Elements with an informationElementIndex are ordered by index, followed
+	// by the other elements in the order in which they were received.
+	sort.SliceStable(definitions, func(i, j int) bool {
+		if definitions[i].index < 0 || definitions[j].index < 0 {
+			return definitions[j].index < 0 && definitions[i].index >= 0
+		}
+		return definitions[i].index < definitions[j].index
+	})
+	cp.elementDefinitions[obsDomainID] = definitions
+}
+
+// GetElementDefinitions returns the elements learned from the RFC 5610 type
+// information options records of the observation domain, ordered by their
+// informationElementIndex. Elements without an index follow in the order in
+// which they were received.
+func (cp *CollectingProcess) GetElementDefinitions(obsDomainID uint32) []*entities.InfoElement {
+	cp.mutex.RLock()
+	defer cp.mutex.RUnlock()
+	definitions := cp.elementDefinitions[obsDomainID]
+	elements := make([]*entities.InfoElement, len(definitions))
+	for i, definition := range definitions {
+		elements[i] = definition.element
+	}
+	return elements
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"k8s.io/klog/v2"
+
+	"github.com/vmware/go-ipfix/pkg/entities"
+	"github.com/vmware/go-ipfix/pkg/registry"
+)
+
+// FlowEndClass describes why the metering process exported a flow record,
+// which tells whether more records of the same flow are expected.
+type FlowEndClass uint8
+
+const (
+	// FlowEndUnknown is returned when the record does not indicate why it was
+	// exported.
+	FlowEndUnknown FlowEndClass = iota
+	// FlowEndNatural is returned when the end of the flow was detected, e.g.
+	// with a TCP FIN or RST flag. No more records of the flow are expected.
+	FlowEndNatural
+	// FlowEndIdleTimeout is returned when the flow was exported after being
+	// inactive for the idle timeout. Packets received later with the same
+	// flow key are accounted as a new flow.
+	FlowEndIdleTimeout
+	// FlowEndActiveTimeout is returned when the flow was exported because it
+	// lasted for the active timeout. The flow is still active and its next
+	// records should be merged with this one.
+	FlowEndActiveTimeout
+	// FlowEndForced is returned when the flow was cut by the metering process
+	// itself, e.g. on shutdown or because of a lack of resources.
+	FlowEndForced
+)
+
+// flowTimeElementSuffixes are the suffixes of the flowStart and flowEnd
+// elements from which the duration of a flow is computed.
+var flowTimeElementSuffixes = []string{"Seconds", "Milliseconds", "Microseconds", "Nanoseconds"}
+
+// ClassifyFlowEnd classifies the end of the flow of a decoded data record
+// from the given observation domain. The flowEndReason element of the record
+// is used if present. Otherwise, the flow is considered cut by the active
+// timeout if its duration, computed from its flowStart and flowEnd elements,
+// reaches the active timeout received in options records for the observation
+// domain (see GetFlowTimeouts).
+func (cp *CollectingProcess) ClassifyFlowEnd(record entities.Record, obsDomainID uint32) FlowEndClass {
+	if reason, _, exist := record.GetInfoElementWithValue("flowEndReason"); exist && reason.GetDataType() == entities.Unsigned8 {
+		switch reason.GetUnsigned8Value() {
+		case registry.EndOfFlowReason:
+			return FlowEndNatural
+		case registry.IdleTimeoutReason:
+			return FlowEndIdleTimeout
+		case registry.ActiveTimeoutReason:
+			return FlowEndActiveTimeout
+		case registry.ForcedEndReason, registry.LackOfResourcesReason:
+			return FlowEndForced
+		}
+	}
+	timeouts, exist := cp.GetFlowTimeouts(obsDomainID)
+	if !exist || timeouts.ActiveTimeout == 0 {
+		return FlowEndUnknown
+	}
+	for _, suffix := range flowTimeElementSuffixes {
+		flowStart, _, startExist := record.GetInfoElementWithValue("flowStart" + suffix)
+		flowEnd, _, endExist := record.GetInfoElementWithValue("flowEnd" + suffix)
+		if !startExist || !endExist {
+			continue
+		}
+		startTime, err := entities.GetTimeValue(flowStart)
+		if err != nil {
+			klog.V(4).InfoS("Cannot get flow start time", "element", flowStart.GetInfoElement().Name, "err", err)
+			return FlowEndUnknown
+		}
+		endTime, err := entities.GetTimeValue(flowEnd)
+		if err != nil {
+			klog.V(4).InfoS("Cannot get flow end time", "element", flowEnd.GetInfoElement().Name, "err", err)
+			return FlowEndUnknown
+		}
+		if endTime.Sub(startTime) >= timeouts.ActiveTimeout {
+			return FlowEndActiveTimeout
+		}
+		return FlowEndUnknown
+	}
+	return FlowEndUnknown
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"net"
+)
+
+// ListenerOptions configures the listening socket of the TCP collecting process.
+type ListenerOptions struct {
+	// Backlog is the maximum length of the queue of pending connections. If
+	// it is 0, the system default is used.
+	Backlog int
+	// ReuseAddress sets SO_REUSEADDR on the listening socket.
+	ReuseAddress bool
+	// ReusePort sets SO_REUSEPORT on the listening socket, which allows
+	// multiple collecting processes to listen on the same port.
+	ReusePort bool
+}
+
+func (o ListenerOptions) isDefault() bool {
+	return o == ListenerOptions{}
+}
+
+// listenTCP creates the TCP listener with the configured listener options.
+func (cp *CollectingProcess) listenTCP() (net.Listener, error) {
+	if cp.listenerOptions.isDefault() {
+		return net.Listen("tcp", cp.address)
+	}
+	lc := net.ListenConfig{
+		Control: cp.listenerOptions.control,
+	}
+	listener, err := lc.Listen(context.Background(), "tcp", cp.address)
+	if err != nil {
+		return nil, err
+	}
+	if cp.listenerOptions.Backlog > 0 {
+		if err := setListenBacklog(listener, cp.listenerOptions.Backlog); err != nil {
+			listener.Close()
+			return nil, err
+		}
+	}
+	return listener, nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTCPCollectingProcess_ReusePort(t *testing.T) {
+	input := getCollectorInput(tcpTransport, false, false)
+	input.ListenerOptions = ListenerOptions{
+		Backlog:      1024,
+		ReuseAddress: true,
+		ReusePort:    true,
+	}
+	cp1, err := InitCollectingProcess(input)
+	require.NoError(t, err)
+	go cp1.Start()
+	waitForCollectorReady(t, cp1)
+	defer cp1.Stop()
+
+	// The second collecting process listens on the same port as the first one.
+	input.Address = cp1.GetAddress().String()
+	cp2, err := InitCollectingProcess(input)
+	require.NoError(t, err)
+	go cp2.Start()
+	waitForCollectorReady(t, cp2)
+	defer cp2.Stop()
+	assert.Equal(t, cp1.GetAddress().String(), cp2.GetAddress().String())
+}
@@ -0,0 +1,31 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !unix
+
+package collector
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+)
+
+func (o ListenerOptions) control(network, address string, c syscall.RawConn) error {
+	return fmt.Errorf("listener options are not supported on this platform")
+}
+
+func setListenBacklog(listener net.Listener, backlog int) error {
+	return fmt.Errorf("listener backlog is not supported on this platform")
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+package collector
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// control sets the socket options before the listening socket is bound.
+func (o ListenerOptions) control(network, address string, c syscall.RawConn) error {
+	if o.ReusePort && soReusePort == 0 {
+		return fmt.Errorf("SO_REUSEPORT is not supported on this platform")
+	}
+	var sockErr error
+	err := c.Control(func(fd uintptr) {
+		if o.ReuseAddress {
+			if sockErr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1); sockErr != nil {
+				return
+			}
+		}
+		if o.ReusePort {
+			sockErr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, soReusePort, 1)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return sockErr
+}
+
+// setListenBacklog updates the backlog of the listening socket. The Go
+// runtime always listens with the system default, so listen is called again
+// on the socket with the requested backlog.
+func setListenBacklog(listener net.Listener, backlog int) error {
+	tcpListener, ok := listener.(*net.TCPListener)
+	if !ok {
+		return fmt.Errorf("listener for %s is not a TCP listener", listener.Addr())
+	}
+	rawConn, err := tcpListener.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var listenErr error
+	if err := rawConn.Control(func(fd uintptr) {
+		listenErr = syscall.Listen(int(fd), backlog)
+	}); err != nil {
+		return err
+	}
+	return listenErr
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/vmware/go-ipfix/pkg/entities"
+)
+
+const metricsNamespace = "ipfix_collector"
+
+// collectorMetrics are the Prometheus metrics of the collecting process. All
+// the methods are no-ops when metrics are not registered.
+type collectorMetrics struct {
+	messagesReceived     prometheus.Counter
+	recordsDecoded       prometheus.Counter
+	decodeErrors         prometheus.Counter
+	unknownTemplateDrops prometheus.Counter
+	clients              prometheus.GaugeFunc
+}
+
+// RegisterMetrics registers the Prometheus metrics of the collecting process
+// with the given registerer: the number of messages received, of data records
+// decoded, of messages which could not be decoded, of data sets dropped
+// because their template is unknown, and the number of connected clients as
+// returned by GetNumConnToCollector. It must be called before Start.
+func (cp *CollectingProcess) RegisterMetrics(registerer prometheus.Registerer) error {
+	metrics := &collectorMetrics{
+		messagesReceived: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "messages_received_total",
+			Help:      "Number of IPFIX messages received.",
+		}),
+		recordsDecoded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "records_decoded_total",
+			Help:      "Number of data records decoded.",
+		}),
+		decodeErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "decode_errors_total",
+			Help:      "Number of IPFIX messages which could not be decoded, including the messages with unknown templates.",
+		}),
+		unknownTemplateDrops: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "unknown_template_drops_total",
+			Help:      "Number of data sets dropped because their template is unknown.",
+		}),
+		clients: prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "clients",
+			Help:      "Number of clients connected to the collector.",
+		}, func() float64 {
+			return float64(cp.GetNumConnToCollector())
+		}),
+	}
+	for _, collector := range []prometheus.Collector{metrics.messagesReceived, metrics.recordsDecoded, metrics.decodeErrors, metrics.unknownTemplateDrops, metrics.clients} {
+		if err := registerer.Register(collector); err != nil {
+			return err
+		}
+	}
+	cp.metrics = metrics
+	return nil
+}
+
+// observeMessages updates the metrics for a received message, given the
+// messages of its sets, which are empty if it could not be decoded.
+func (m *collectorMetrics) observeMessages(messages []*entities.Message) {
+	if m == nil {
+		return
+	}
+	m.messagesReceived.Inc()
+	if len(messages) == 0 {
+		m.decodeErrors.Inc()
+		return
+	}
+	for _, message := range messages {
+		if set := message.GetSet(); set != nil && set.GetSetType() == entities.Data {
+			m.recordsDecoded.Add(float64(set.GetNumberOfRecords()))
+		}
+	}
+}
+
+func (m *collectorMetrics) incUnknownTemplateDrops() {
+	if m != nil {
+		m.unknownTemplateDrops.Inc()
+	}
+}
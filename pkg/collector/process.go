@@ -17,13 +17,18 @@ package collector
 import (
 	"bufio"
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
 	"encoding/binary"
 	"fmt"
+	"io"
 	"net"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/pion/dtls/v2"
 	"k8s.io/klog/v2"
 
 	"github.com/vmware/go-ipfix/pkg/entities"
@@ -31,9 +36,77 @@ import (
 	"github.com/vmware/go-ipfix/pkg/util"
 )
 
+// DeliveryMode specifies how decoded messages are delivered to the message
+// channel returned by GetMsgChan when the consumer is not keeping up.
+type DeliveryMode uint8
+
+const (
+	// DeliveryModeBlock blocks the decoding of the next message until the
+	// consumer has read the current one from the message channel. This applies
+	// backpressure to the exporter: for TCP, the exporter is slowed down by the
+	// flow control of the connection; for UDP, packets are dropped by the kernel
+	// once the socket receive buffer is full. This is the default mode.
+	DeliveryModeBlock DeliveryMode = iota
+	// DeliveryModeDrop drops the decoded message if the message channel is full,
+	// so that decoding is never blocked by the consumer. The number of dropped
+	// messages is available through GetNumMessagesDropped.
+	DeliveryModeDrop
+)
+
+// TrailingBytesElementName is the name of the octetArray element added to the
+// last data record of a data set when CaptureTrailingBytes is set and the set
+// contains bytes beyond its last complete record.
+const TrailingBytesElementName = "trailingBytes"
+
+// templateKey identifies a template received by the collecting process.
+type templateKey struct {
+	obsDomainID uint32
+	templateID  uint16
+	// exporter is the IP address of the exporter when templates are isolated
+	// by exporter, and empty otherwise.
+	exporter string
+}
+
+// TemplateSchemaAlert describes a received template which does not contain all
+// the elements set with SetRequiredElements.
+type TemplateSchemaAlert struct {
+	ObsDomainID     uint32
+	TemplateID      uint16
+	MissingElements []string
+}
+
+// TemplateSchemaAlertHandler is called for every received template which does
+// not contain all the required elements.
+type TemplateSchemaAlertHandler func(alert TemplateSchemaAlert)
+
+// FlowTimeouts are the flow timeouts of the metering process of an
+// observation domain, as exported in options records.
+type FlowTimeouts struct {
+	// ActiveTimeout is the time after which a long-lasting active flow is
+	// exported; 0 if unknown.
+	ActiveTimeout time.Duration
+	// IdleTimeout is the time after which an inactive flow is exported; 0 if
+	// unknown.
+	IdleTimeout time.Duration
+}
+
+// TemplateAddedHandler is called for every template added or updated by the
+// collecting process.
+type TemplateAddedHandler func(obsDomainID uint32, templateID uint16, elements []*entities.InfoElement)
+
+// TemplateExpiredHandler is called for every UDP template which expired
+// because the exporter did not refresh it within the template TTL.
+type TemplateExpiredHandler func(obsDomainID uint32, templateID uint16)
+
+// UndecodableHandler is called with the address of the exporter and the raw
+// bytes of every received message which cannot be decoded.
+type UndecodableHandler func(addr string, data []byte)
+
 type CollectingProcess struct {
-	// for each obsDomainID, there is a map of templates
-	templatesMap map[uint32]map[uint16][]*entities.InfoElement
+	// templatesMap is the default in-memory template store
+	templatesMap memoryTemplateStore
+	// templateStore is the template store given in CollectorInput, if any
+	templateStore TemplateStore
 	// mutex allows multiple readers or one writer at the same time
 	mutex sync.RWMutex
 	// template lifetime
@@ -63,15 +136,159 @@ type CollectingProcess struct {
 	serverKey            []byte
 	wg                   sync.WaitGroup
 	numOfRecordsReceived uint64
+	// decompressMessages indicates whether received messages are gzip-compressed
+	// and wrapped with a header by the exporter.
+	decompressMessages bool
+	// deliveryMode specifies whether to block or drop when messageChan is full
+	deliveryMode         DeliveryMode
+	numOfMessagesDropped uint64
+	// requiredElements is the list of element names that every received
+	// template must contain
+	requiredElements []string
+	// schemaAlertHandler is called for templates missing required elements
+	schemaAlertHandler TemplateSchemaAlertHandler
+	// undecodableHandler is called for messages which cannot be decoded
+	undecodableHandler UndecodableHandler
+	// templateAddedHandler is called for every added template
+	templateAddedHandler TemplateAddedHandler
+	// templateExpiredHandler is called when a UDP template expires
+	templateExpiredHandler TemplateExpiredHandler
+	// templateTTLByObsDomain overrides templateTTL for some observation
+	// domains
+	templateTTLByObsDomain map[uint32]uint32
+	// deduplicateTemplates indicates whether templates identical to the stored
+	// ones are handled as refreshes
+	deduplicateTemplates bool
+	// registerElementsFromOptions indicates whether the elements described in
+	// options records are used to decode templates
+	registerElementsFromOptions bool
+	// elementDefinitions maps obsDomainIDs to the elements learned from
+	// options records, ordered by informationElementIndex
+	elementDefinitions map[uint32][]*elementDefinition
+	// tlsConfig is the TLS configuration given in CollectorInput, if any
+	tlsConfig *tls.Config
+	// numTLSHandshakeErrors is the number of TLS connections closed because
+	// of a failed handshake
+	numTLSHandshakeErrors uint64
+	// multicastInterface is the interface on which the multicast group is
+	// joined
+	multicastInterface string
+	// dtlsConfig is the DTLS configuration given in CollectorInput, if any
+	dtlsConfig *dtls.Config
+	// sessionTemplates maps the addresses of the DTLS sessions to the
+	// templates received in the sessions
+	sessionTemplates map[string]map[templateKey]struct{}
+	// normalizeNumericTypes indicates whether decoded numeric values are
+	// widened to 64 bits
+	normalizeNumericTypes bool
+	// scopeFieldCounts maps the options templates to their number of scope
+	// fields. The entries of deleted templates are ignored.
+	scopeFieldCounts map[templateKey]uint16
+	// recordsChan receives the records flattened from the message channel
+	// once Records is called
+	recordsChan chan *DecodedRecord
+	recordsOnce sync.Once
+	// strictLength indicates whether messages whose length does not match
+	// the received bytes are rejected
+	strictLength bool
+	// roundRobinChans are the channels returned by GetRoundRobinChan, to
+	// which the messages are delivered in turn instead of messageChan
+	roundRobinChans []chan *entities.Message
+	roundRobinIndex int
+	// metrics are the Prometheus metrics registered with RegisterMetrics
+	metrics *collectorMetrics
+	// isolateTemplatesByExporter indicates whether the templates of every
+	// exporter are stored separately, in exporterTemplates
+	isolateTemplatesByExporter bool
+	exporterTemplates          map[string]memoryTemplateStore
+	// draining indicates whether the collecting process is being stopped by
+	// StopAndWait, in which case the received bytes are decoded and delivered
+	// before the clients return
+	draining bool
+	// proxyProtocol indicates whether TCP connections start with a PROXY
+	// protocol v2 header
+	proxyProtocol bool
+	// skipStructuredData indicates whether structured data elements are
+	// delivered without decoding their contents
+	skipStructuredData bool
+	// captureTrailingBytes indicates whether the bytes of a data set beyond
+	// its last complete record are appended to that record
+	captureTrailingBytes bool
+	// dataSetIDOverrides maps non-standard set IDs to the ID of the template
+	// used to decode them as data sets
+	dataSetIDOverrides map[uint16]uint16
+	// maxBufferedMessages is the maximum number of messages buffered across
+	// messageChan and templateChans; 0 means no limit.
+	maxBufferedMessages        int
+	bufferMutex                sync.Mutex
+	numBufferedMessagesDropped uint64
+	// rejectTemplatesMissingRequiredElements indicates whether templates missing
+	// required elements are discarded instead of being used for decoding.
+	rejectTemplatesMissingRequiredElements bool
+	// listenerOptions configures the listening socket for TCP
+	listenerOptions ListenerOptions
+	// maxStructuredDataDepth is the maximum nesting depth of structured data
+	maxStructuredDataDepth int
+	// templateGenerations is updated every time a UDP template is added or
+	// refreshed, so that an expiry timer of a refreshed template is ignored.
+	templateGenerations map[templateKey]uint64
+	// lastTemplateGeneration is the last generation given to a UDP template.
+	// Generations are not reused, so that the expiry timer of a withdrawn
+	// template is ignored if the template is added again.
+	lastTemplateGeneration uint64
+	// templateExpiries counts the UDP templates which expired before being
+	// refreshed by the exporter.
+	templateExpiries map[templateKey]uint64
+	// applicationNames maps application IDs to the application names received
+	// in options records.
+	applicationNames map[string]string
+	// flowTimeouts maps obsDomainIDs to the flow timeouts received in options
+	// records.
+	flowTimeouts map[uint32]FlowTimeouts
+	// reorderWindowSize is the maximum number of UDP messages held per exporter
+	// to be delivered in sequence order; 0 disables reordering.
+	reorderWindowSize int
+	reorderTimeout    time.Duration
+	reorderBuffers    map[string]*reorderBuffer
+	// nextSequenceNums are the sequence numbers expected for the next message
+	// of every exporter and observation domain
+	nextSequenceNums  map[sequenceKey]uint32
+	numMissingRecords uint64
+	// recordElementOffsets indicates whether the byte offsets of the decoded
+	// elements within their data record are recorded
+	recordElementOffsets bool
+	// templateChans are the channels returned by GetTemplateChan, to which the
+	// data messages of the corresponding templates are delivered
+	templateChans map[templateKey]chan *entities.Message
+	// messageChanSize is the buffer size of the message channels
+	messageChanSize int
+	// maxObsDomains is the maximum number of observation domains for which
+	// templates are stored; 0 means no limit.
+	maxObsDomains int
+	// obsDomainLastUsed stores when every observation domain was last used,
+	// as a value of obsDomainClock, for LRU eviction.
+	obsDomainLastUsed    map[uint32]uint64
+	obsDomainClock       uint64
+	numObsDomainsEvicted uint64
+	// decodeErrorsByTemplate counts the data sets which could not be decoded,
+	// for each obsDomainID and known template ID.
+	decodeErrorsByTemplate map[uint32]map[uint16]int64
+	// numOfRecordsDropped is the number of data records in the messages
+	// dropped because the message channel was full
+	numOfRecordsDropped uint64
+	// statsExportConfig configures the export of the collector statistics
+	statsExportConfig *StatsExportConfig
+	statsTemplateID   uint16
 }
 
 type CollectorInput struct {
 	IsIPv6      bool
 	IsEncrypted bool
-	// Address needs to be provided in hostIP:port format.
+	// Address needs to be provided in hostIP:port format. With the "udp"
+	// protocol, it can be a multicast group address to join.
 	Address string
 	// Protocol needs to be provided in lower case format.
-	// We support "tcp" and "udp" protocols.
+	// We support "tcp", "udp" and "sctp" protocols.
 	Protocol      string
 	MaxBufferSize uint16
 	TemplateTTL   uint32
@@ -80,37 +297,224 @@ type CollectorInput struct {
 	ServerCert       []byte
 	ServerKey        []byte
 	NumExtraElements int
+	// DecompressMessages should be set when the exporter is configured with
+	// CompressMessages. Every received message is then expected to be preceded
+	// by a wrapper header and is decompressed before decoding. This is not part
+	// of RFC7011 and is disabled by default.
+	DecompressMessages bool
+	// DeliveryMode specifies the behavior when the message channel is full.
+	// Default is DeliveryModeBlock.
+	DeliveryMode DeliveryMode
+	// MessageChanSize is the buffer size of the message channel returned by
+	// GetMsgChan. Default is 0 (unbuffered).
+	MessageChanSize int
+	// RejectTemplatesMissingRequiredElements specifies whether templates which
+	// do not contain all the elements set with SetRequiredElements are
+	// discarded. Data records for such templates then cannot be decoded. By
+	// default, these templates only trigger an alert and are still used.
+	RejectTemplatesMissingRequiredElements bool
+	// ListenerOptions configures the listening socket (accept backlog,
+	// SO_REUSEADDR and SO_REUSEPORT). It only applies to the "tcp" protocol.
+	ListenerOptions ListenerOptions
+	// MaxStructuredDataDepth is the maximum nesting depth of structured data
+	// (basicList and subTemplateList) in data records. Records exceeding it
+	// cannot be decoded. Default is entities.DefaultMaxStructuredDataDepth.
+	MaxStructuredDataDepth int
+	// ReorderWindowSize enables reordering of out-of-order UDP messages when
+	// greater than 0. Up to ReorderWindowSize messages per exporter and
+	// observation domain are held until the missing messages arrive, so that
+	// messages are delivered in sequence number order. It only applies to the
+	// "udp" protocol.
+	ReorderWindowSize int
+	// ReorderTimeout is the maximum time a message is held in the reorder
+	// buffer. Default is DefaultReorderTimeout.
+	ReorderTimeout time.Duration
+	// RecordElementOffsets enables recording the start and end byte offsets of
+	// every decoded element within its data record, which are available
+	// through InfoElementWithValue.GetByteOffsets. This is meant for debugging
+	// decoding issues and is disabled by default.
+	RecordElementOffsets bool
+	// MaxObservationDomains limits the number of distinct observation domains
+	// for which templates are stored. When a template is received for a new
+	// observation domain while the limit is reached, all the templates of the
+	// least recently used observation domain are evicted, and the data records
+	// of that domain cannot be decoded until its templates are received again.
+	// Default is 0 (no limit).
+	MaxObservationDomains int
+	// StatsExportConfig enables the periodic export of the collector
+	// statistics to an upstream collector as an IPFIX options record. See
+	// CollectingProcess.ExportStats. Default is nil (disabled).
+	StatsExportConfig *StatsExportConfig
+	// TemplateStore stores the received templates, e.g. in a backend shared
+	// by several collecting processes. Default is an in-memory store.
+	TemplateStore TemplateStore
+	// MaxBufferedMessages limits the total number of decoded messages buffered
+	// in the message channel and in the channels returned by GetTemplateChan.
+	// When the limit is reached, the oldest message of the channel buffering
+	// the most messages is dropped to make room for the new one. Default is 0
+	// (no limit other than the channel sizes).
+	MaxBufferedMessages int
+	// DeduplicateTemplates specifies whether a received template identical to
+	// the stored template with the same ID is handled as a refresh, without
+	// calling the handler set with SetTemplateAddedHandler. This avoids noise
+	// when TCP exporters reconnect frequently and resend their templates.
+	DeduplicateTemplates bool
+	// RegisterElementsFromOptions specifies whether the elements described in
+	// RFC 5610 type information options records are learned, so that the
+	// templates of the observation domain using them can be decoded. The
+	// elements are kept by the collecting process and are not added to the
+	// registry.
+	RegisterElementsFromOptions bool
+	// TLSConfig is set to accept TLS connections with the given configuration
+	// when the protocol is "tcp", instead of the configuration created from
+	// CACert, ServerCert and ServerKey. Client certificate verification is
+	// configured with its ClientAuth and ClientCAs fields, e.g. to enforce
+	// mutual TLS with the exporters.
+	TLSConfig *tls.Config
+	// MulticastInterface is the name of the interface on which the multicast
+	// group is joined when the protocol is "udp" and Address is a multicast
+	// group address. By default, the interface is chosen by the system.
+	MulticastInterface string
+	// DTLSConfig is set to accept DTLS sessions with the given configuration
+	// when the protocol is "udp", instead of the configuration created from
+	// ServerCert and ServerKey. The templates received in a DTLS session are
+	// deleted when the session is closed.
+	DTLSConfig *dtls.Config
+	// NormalizeNumericTypes specifies whether the decoded values of integer
+	// elements are widened to uint64 or int64, and the values of float elements
+	// to float64, along with the data types of the decoded elements. This
+	// simplifies type switches on the values.
+	NormalizeNumericTypes bool
+	// StrictLength rejects messages for which the message length in the
+	// header is not exactly the number of bytes received, e.g. messages with
+	// trailing bytes, as well as messages whose set does not span the whole
+	// message. By default, trailing bytes are decoded as part of the set.
+	StrictLength bool
+	// RoundRobinChannels spreads the decoded messages across the given number
+	// of channels, returned by GetRoundRobinChan, instead of the channel
+	// returned by GetMsgChan. Each message is delivered to the next channel in
+	// turn, regardless of its exporter or observation domain, so this is meant
+	// for consumers which do not keep any state across messages. The channels
+	// have the same size as the message channel. Default is 0 (disabled).
+	RoundRobinChannels int
+	// IsolateTemplatesByExporter stores the templates of every exporter
+	// separately, keyed by the IP address of the exporter in addition to the
+	// obsDomainID, so that exporters using the same obsDomainID with
+	// conflicting template definitions do not decode each other's data
+	// records. It cannot be used with TemplateStore. The methods which take
+	// an obsDomainID and template ID, such as GetScopeFieldCount, only refer
+	// to templates which are not isolated.
+	IsolateTemplatesByExporter bool
+	// ProxyProtocol specifies whether the TCP connections start with a PROXY
+	// protocol v2 header, as sent by TCP load balancers, in which case the
+	// source address in the header is used as the address of the exporter.
+	// Connections without a valid header are closed. The header precedes the
+	// TLS handshake if TLS is used.
+	ProxyProtocol bool
+	// SkipStructuredData specifies whether the contents of basicList,
+	// subTemplateList and subTemplateMultiList elements are skipped instead of
+	// being decoded, which saves CPU when they are not needed. The skipped
+	// elements are delivered with a nil value, e.g. GetSubTemplateListValue
+	// returns nil.
+	SkipStructuredData bool
+	// TemplateTTLByObsDomain sets the template TTL, in seconds, of the UDP
+	// templates of specific observation domains, e.g. for exporters which
+	// resend their templates less often than others. The templates of other
+	// observation domains use TemplateTTL.
+	TemplateTTLByObsDomain map[uint32]uint32
+	// CaptureTrailingBytes specifies whether the bytes of a data set which
+	// are too few to be decoded as another record with the set template, e.g.
+	// because of an exporter sending more data than its template describes,
+	// are captured instead of failing the decoding of the whole set. They are
+	// appended as an octetArray element named TrailingBytesElementName to the
+	// last decoded record of the set, so that the records can be inspected.
+	// The set still cannot be decoded if it does not contain any complete
+	// record.
+	CaptureTrailingBytes bool
 }
 
 type clientHandler struct {
-	packetChan chan *bytes.Buffer
+	packetChan chan *receivedPacket
+}
+
+// receivedPacket is a packet read from a UDP client along with the time when
+// it was read.
+type receivedPacket struct {
+	buffer      *bytes.Buffer
+	receiveTime time.Time
 }
 
 func InitCollectingProcess(input CollectorInput) (*CollectingProcess, error) {
+	if input.IsolateTemplatesByExporter && input.TemplateStore != nil {
+		return nil, fmt.Errorf("IsolateTemplatesByExporter cannot be used with a TemplateStore")
+	}
 	collectProc := &CollectingProcess{
-		templatesMap:     make(map[uint32]map[uint16][]*entities.InfoElement),
-		mutex:            sync.RWMutex{},
-		templateTTL:      input.TemplateTTL,
-		address:          input.Address,
-		protocol:         input.Protocol,
-		maxBufferSize:    input.MaxBufferSize,
-		stopChan:         make(chan struct{}),
-		messageChan:      make(chan *entities.Message),
-		clients:          make(map[string]*clientHandler),
-		isEncrypted:      input.IsEncrypted,
-		caCert:           input.CACert,
-		serverCert:       input.ServerCert,
-		serverKey:        input.ServerKey,
-		numExtraElements: input.NumExtraElements,
+		templatesMap:                           make(memoryTemplateStore),
+		templateStore:                          input.TemplateStore,
+		mutex:                                  sync.RWMutex{},
+		templateTTL:                            input.TemplateTTL,
+		address:                                input.Address,
+		protocol:                               input.Protocol,
+		maxBufferSize:                          input.MaxBufferSize,
+		stopChan:                               make(chan struct{}),
+		messageChan:                            make(chan *entities.Message, input.MessageChanSize),
+		clients:                                make(map[string]*clientHandler),
+		isEncrypted:                            input.IsEncrypted,
+		caCert:                                 input.CACert,
+		serverCert:                             input.ServerCert,
+		serverKey:                              input.ServerKey,
+		numExtraElements:                       input.NumExtraElements,
+		decompressMessages:                     input.DecompressMessages,
+		deliveryMode:                           input.DeliveryMode,
+		listenerOptions:                        input.ListenerOptions,
+		maxStructuredDataDepth:                 input.MaxStructuredDataDepth,
+		rejectTemplatesMissingRequiredElements: input.RejectTemplatesMissingRequiredElements,
+		recordElementOffsets:                   input.RecordElementOffsets,
+		messageChanSize:                        input.MessageChanSize,
+		maxObsDomains:                          input.MaxObservationDomains,
+		statsExportConfig:                      input.StatsExportConfig,
+		maxBufferedMessages:                    input.MaxBufferedMessages,
+		deduplicateTemplates:                   input.DeduplicateTemplates,
+		registerElementsFromOptions:            input.RegisterElementsFromOptions,
+		tlsConfig:                              input.TLSConfig,
+		multicastInterface:                     input.MulticastInterface,
+		dtlsConfig:                             input.DTLSConfig,
+		normalizeNumericTypes:                  input.NormalizeNumericTypes,
+		strictLength:                           input.StrictLength,
+		isolateTemplatesByExporter:             input.IsolateTemplatesByExporter,
+		proxyProtocol:                          input.ProxyProtocol,
+		skipStructuredData:                     input.SkipStructuredData,
+		captureTrailingBytes:                   input.CaptureTrailingBytes,
+	}
+	if len(input.TemplateTTLByObsDomain) > 0 {
+		collectProc.templateTTLByObsDomain = make(map[uint32]uint32, len(input.TemplateTTLByObsDomain))
+		for obsDomainID, ttl := range input.TemplateTTLByObsDomain {
+			collectProc.templateTTLByObsDomain[obsDomainID] = ttl
+		}
+	}
+	for i := 0; i < input.RoundRobinChannels; i++ {
+		collectProc.roundRobinChans = append(collectProc.roundRobinChans, make(chan *entities.Message, input.MessageChanSize))
+	}
+	if input.Protocol == "udp" && input.ReorderWindowSize > 0 {
+		collectProc.reorderWindowSize = input.ReorderWindowSize
+		collectProc.reorderTimeout = input.ReorderTimeout
+		if collectProc.reorderTimeout == 0 {
+			collectProc.reorderTimeout = DefaultReorderTimeout
+		}
 	}
 	return collectProc, nil
 }
 
 func (cp *CollectingProcess) Start() {
+	if cp.statsExportConfig != nil {
+		cp.startStatsExport()
+	}
 	if cp.protocol == "tcp" {
 		cp.startTCPServer()
 	} else if cp.protocol == "udp" {
 		cp.startUDPServer()
+	} else if cp.protocol == "sctp" {
+		cp.startSCTPServer()
 	}
 }
 
@@ -121,6 +525,39 @@ func (cp *CollectingProcess) Stop() {
 	klog.Info("stopping the collecting process")
 }
 
+// StopAndWait stops the collecting process like Stop, but the messages already
+// received are decoded and delivered before returning: new connections are no
+// longer accepted, the data buffered for every client is decoded, the messages
+// held for reordering are flushed, and finally the message channels are
+// closed, so that consumers can read until the channels are closed. If the
+// context expires before the drain completes, e.g. because nobody reads from
+// the message channel with DeliveryModeBlock, the context error is returned
+// and the message channels are left open.
+func (cp *CollectingProcess) StopAndWait(ctx context.Context) error {
+	cp.mutex.Lock()
+	cp.draining = true
+	cp.mutex.Unlock()
+	done := make(chan struct{})
+	go func() {
+		cp.Stop()
+		cp.flushReorderBuffers()
+		close(done)
+	}()
+	select {
+	case <-done:
+		cp.CloseMsgChan()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (cp *CollectingProcess) isDraining() bool {
+	cp.mutex.RLock()
+	defer cp.mutex.RUnlock()
+	return cp.draining
+}
+
 func (cp *CollectingProcess) GetAddress() net.Addr {
 	cp.mutex.RLock()
 	defer cp.mutex.RUnlock()
@@ -131,10 +568,43 @@ func (cp *CollectingProcess) GetMsgChan() chan *entities.Message {
 	return cp.messageChan
 }
 
+// GetTemplateChan returns a channel which receives the data messages of the
+// given template for the given observation domain. Once the channel is
+// requested, these messages are no longer delivered to the channel returned by
+// GetMsgChan, so that a consumer can be dedicated to each template. Calling it
+// again for the same template returns the same channel.
+func (cp *CollectingProcess) GetTemplateChan(obsDomainID uint32, templateID uint16) chan *entities.Message {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	if cp.templateChans == nil {
+		cp.templateChans = make(map[templateKey]chan *entities.Message)
+	}
+	key := templateKey{obsDomainID: obsDomainID, templateID: templateID}
+	if _, exist := cp.templateChans[key]; !exist {
+		cp.templateChans[key] = make(chan *entities.Message, cp.messageChanSize)
+	}
+	return cp.templateChans[key]
+}
+
+// GetRoundRobinChan returns the i-th channel of the RoundRobinChannels
+// channels, or nil if i is out of range.
+func (cp *CollectingProcess) GetRoundRobinChan(i int) chan *entities.Message {
+	if i < 0 || i >= len(cp.roundRobinChans) {
+		return nil
+	}
+	return cp.roundRobinChans[i]
+}
+
 func (cp *CollectingProcess) CloseMsgChan() {
 	cp.mutex.Lock()
 	defer cp.mutex.Unlock()
 	close(cp.messageChan)
+	for _, roundRobinChan := range cp.roundRobinChans {
+		close(roundRobinChan)
+	}
+	for _, templateChan := range cp.templateChans {
+		close(templateChan)
+	}
 }
 
 func (cp *CollectingProcess) GetNumRecordsReceived() int64 {
@@ -143,6 +613,69 @@ func (cp *CollectingProcess) GetNumRecordsReceived() int64 {
 	return int64(cp.numOfRecordsReceived)
 }
 
+// GetNumMessagesDropped returns the number of decoded messages which were dropped
+// because the message channel was full. It is always 0 with DeliveryModeBlock.
+func (cp *CollectingProcess) GetNumMessagesDropped() int64 {
+	cp.mutex.RLock()
+	defer cp.mutex.RUnlock()
+	return int64(cp.numOfMessagesDropped)
+}
+
+// SetRequiredElements sets the names of the information elements that every
+// received template is expected to contain. Templates lacking any of them
+// trigger an alert, see SetTemplateSchemaAlertHandler.
+func (cp *CollectingProcess) SetRequiredElements(names []string) {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	cp.requiredElements = append([]string(nil), names...)
+}
+
+// SetTemplateSchemaAlertHandler sets the handler which is called for every
+// received template missing some of the required elements.
+func (cp *CollectingProcess) SetTemplateSchemaAlertHandler(handler TemplateSchemaAlertHandler) {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	cp.schemaAlertHandler = handler
+}
+
+// SetTemplateAddedHandler sets the handler which is called for every template
+// received or imported by the collecting process.
+func (cp *CollectingProcess) SetTemplateAddedHandler(handler TemplateAddedHandler) {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	cp.templateAddedHandler = handler
+}
+
+// SetTemplateExpiredHandler sets the handler which is called for every UDP
+// template which expires before being refreshed by the exporter.
+func (cp *CollectingProcess) SetTemplateExpiredHandler(handler TemplateExpiredHandler) {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	cp.templateExpiredHandler = handler
+}
+
+// SetUndecodableHandler sets the handler which is called with the raw bytes of
+// every message which cannot be decoded, e.g. to quarantine them for analysis.
+// The handler owns the given bytes.
+func (cp *CollectingProcess) SetUndecodableHandler(handler UndecodableHandler) {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	cp.undecodableHandler = handler
+}
+
+// SetDataSetIDOverride sets the set IDs which are decoded as data sets, for
+// exporters which send data records with non-standard set IDs. The map gives,
+// for every such set ID, the ID of the template used to decode its records.
+// The overrides take precedence over the standard set IDs.
+func (cp *CollectingProcess) SetDataSetIDOverride(overrides map[uint16]uint16) {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	cp.dataSetIDOverrides = make(map[uint16]uint16, len(overrides))
+	for setID, templateID := range overrides {
+		cp.dataSetIDOverrides[setID] = templateID
+	}
+}
+
 func (cp *CollectingProcess) GetNumConnToCollector() int64 {
 	cp.mutex.RLock()
 	defer cp.mutex.RUnlock()
@@ -155,9 +688,124 @@ func (cp *CollectingProcess) incrementNumRecordsReceived() {
 	cp.numOfRecordsReceived = cp.numOfRecordsReceived + 1
 }
 
+func (cp *CollectingProcess) incrementNumTLSHandshakeErrors() {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	cp.numTLSHandshakeErrors++
+}
+
+// GetNumTLSHandshakeErrors returns the number of TLS connections which were
+// closed because the handshake failed, e.g. because the exporter did not
+// present a valid client certificate.
+func (cp *CollectingProcess) GetNumTLSHandshakeErrors() int64 {
+	cp.mutex.RLock()
+	defer cp.mutex.RUnlock()
+	return int64(cp.numTLSHandshakeErrors)
+}
+
+func (cp *CollectingProcess) incrementNumMessagesDropped(message *entities.Message) {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	cp.numOfMessagesDropped = cp.numOfMessagesDropped + 1
+	if set := message.GetSet(); set != nil && set.GetSetType() == entities.Data {
+		cp.numOfRecordsDropped += uint64(set.GetNumberOfRecords())
+	}
+}
+
+// deliverMessage sends the message to the message channel following the
+// configured delivery mode.
+func (cp *CollectingProcess) deliverMessage(message *entities.Message) {
+	messageChan := cp.getMessageChan(message)
+	if cp.maxBufferedMessages > 0 {
+		cp.enforceMaxBufferedMessages()
+	}
+	if cp.deliveryMode == DeliveryModeDrop {
+		select {
+		case messageChan <- message:
+			cp.incrementNumRecordsReceived()
+		default:
+			klog.V(2).InfoS("Message channel is full, dropping message", "observationDomainID", message.GetObsDomainID())
+			cp.incrementNumMessagesDropped(message)
+		}
+		return
+	}
+	// the thread(s)/client(s) executing the code will get blocked until the message is consumed/read in other goroutines.
+	messageChan <- message
+	cp.incrementNumRecordsReceived()
+}
+
+// enforceMaxBufferedMessages drops buffered messages until there is room for
+// one more message within maxBufferedMessages. The dropped message is the
+// oldest message of the channel buffering the most messages.
+func (cp *CollectingProcess) enforceMaxBufferedMessages() {
+	cp.bufferMutex.Lock()
+	defer cp.bufferMutex.Unlock()
+	for {
+		cp.mutex.RLock()
+		longestChan := cp.messageChan
+		numBuffered := len(cp.messageChan)
+		for _, templateChan := range cp.templateChans {
+			numBuffered += len(templateChan)
+			if len(templateChan) > len(longestChan) {
+				longestChan = templateChan
+			}
+		}
+		for _, roundRobinChan := range cp.roundRobinChans {
+			numBuffered += len(roundRobinChan)
+			if len(roundRobinChan) > len(longestChan) {
+				longestChan = roundRobinChan
+			}
+		}
+		cp.mutex.RUnlock()
+		if numBuffered < cp.maxBufferedMessages {
+			return
+		}
+		select {
+		case dropped := <-longestChan:
+			klog.V(2).InfoS("Maximum number of buffered messages reached, dropping oldest message", "observationDomainID", dropped.GetObsDomainID())
+			cp.mutex.Lock()
+			cp.numBufferedMessagesDropped++
+			cp.mutex.Unlock()
+		default:
+			// the message was consumed in the meantime
+		}
+	}
+}
+
+// GetNumBufferedMessagesDropped returns the number of buffered messages which
+// were dropped because of MaxBufferedMessages.
+func (cp *CollectingProcess) GetNumBufferedMessagesDropped() int64 {
+	cp.mutex.RLock()
+	defer cp.mutex.RUnlock()
+	return int64(cp.numBufferedMessagesDropped)
+}
+
+// getMessageChan returns the channel requested with GetTemplateChan for the
+// template of the data message if any. Otherwise, it returns the next round
+// robin channel if RoundRobinChannels is set, and messageChan if not.
+func (cp *CollectingProcess) getMessageChan(message *entities.Message) chan *entities.Message {
+	if set := message.GetSet(); set != nil && set.GetSetType() == entities.Data && set.GetNumberOfRecords() > 0 {
+		key := templateKey{obsDomainID: message.GetObsDomainID(), templateID: set.GetRecords()[0].GetTemplateID()}
+		cp.mutex.RLock()
+		templateChan, exist := cp.templateChans[key]
+		cp.mutex.RUnlock()
+		if exist {
+			return templateChan
+		}
+	}
+	if len(cp.roundRobinChans) == 0 {
+		return cp.messageChan
+	}
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	messageChan := cp.roundRobinChans[cp.roundRobinIndex]
+	cp.roundRobinIndex = (cp.roundRobinIndex + 1) % len(cp.roundRobinChans)
+	return messageChan
+}
+
 func (cp *CollectingProcess) createClient() *clientHandler {
 	return &clientHandler{
-		packetChan: make(chan *bytes.Buffer),
+		packetChan: make(chan *receivedPacket),
 	}
 }
 
@@ -171,62 +819,214 @@ func (cp *CollectingProcess) deleteClient(name string) {
 	cp.mutex.Lock()
 	defer cp.mutex.Unlock()
 	delete(cp.clients, name)
+	for key := range cp.nextSequenceNums {
+		if key.exportAddress == name {
+			delete(cp.nextSequenceNums, key)
+		}
+	}
+}
+
+// DecodeHeader decodes only the IPFIX message header at the beginning of data,
+// without parsing the sets. It can be used to cheaply route or drop messages
+// before decoding them.
+func DecodeHeader(data []byte) (version uint16, length uint16, exportTime uint32, sequence uint32, domain uint32, err error) {
+	if len(data) < entities.MsgHeaderLength {
+		return 0, 0, 0, 0, 0, fmt.Errorf("message length %d is shorter than the IPFIX message header", len(data))
+	}
+	version = binary.BigEndian.Uint16(data[0:2])
+	if version != uint16(10) {
+		return 0, 0, 0, 0, 0, fmt.Errorf("collector only supports IPFIX (v10); invalid version %d received", version)
+	}
+	length = binary.BigEndian.Uint16(data[2:4])
+	exportTime = binary.BigEndian.Uint32(data[4:8])
+	sequence = binary.BigEndian.Uint32(data[8:12])
+	domain = binary.BigEndian.Uint32(data[12:16])
+	return version, length, exportTime, sequence, domain, nil
 }
 
 func (cp *CollectingProcess) decodePacket(packetBuffer *bytes.Buffer, exportAddress string) (*entities.Message, error) {
-	var length, version, setID, setLen uint16
+	return cp.decodePacketWithReceiveTime(packetBuffer, exportAddress, time.Now())
+}
+
+// decodePacketWithReceiveTime decodes the packet, and sets the receive time of
+// the messages to the time when the packet was read. A message is delivered
+// for each set of the packet, and the message of the first set is returned.
+func (cp *CollectingProcess) decodePacketWithReceiveTime(packetBuffer *bytes.Buffer, exportAddress string, receiveTime time.Time) (*entities.Message, error) {
+	// The buffer is consumed while decoding, keep the raw bytes for the handler.
+	data := packetBuffer.Bytes()
+	messages, err := cp.decodeMessages(packetBuffer, exportAddress, receiveTime)
+	cp.metrics.observeMessages(messages)
+	if err != nil {
+		cp.mutex.RLock()
+		handler := cp.undecodableHandler
+		cp.mutex.RUnlock()
+		if handler != nil {
+			handler(exportAddress, append([]byte(nil), data...))
+		}
+		return nil, err
+	}
+	for _, message := range messages {
+		cp.checkSequenceNum(exportAddress, message)
+		if cp.reorderWindowSize > 0 {
+			cp.reorderMessage(exportAddress, message)
+		} else {
+			cp.deliverMessage(message)
+		}
+	}
+	return messages[0], nil
+}
+
+// decodeMessage decodes the packet without delivering it, and returns the
+// message of its first set.
+func (cp *CollectingProcess) decodeMessage(packetBuffer *bytes.Buffer, exportAddress string, receiveTime time.Time) (*entities.Message, error) {
+	messages, err := cp.decodeMessages(packetBuffer, exportAddress, receiveTime)
+	if err != nil {
+		return nil, err
+	}
+	return messages[0], nil
+}
+
+// decodeMessages decodes the packet without delivering it. The sets of the
+// packet are decoded into one message each, which share the message header.
+// The sequence number of each message is increased by the number of data
+// records in the preceding sets, so that the messages are in sequence.
+func (cp *CollectingProcess) decodeMessages(packetBuffer *bytes.Buffer, exportAddress string, receiveTime time.Time) ([]*entities.Message, error) {
+	sessionAddress := exportAddress
+	packetLen := packetBuffer.Len()
+	var length, version uint16
 	var exportTime, sequencNum, obsDomainID uint32
-	if err := util.Decode(packetBuffer, binary.BigEndian, &version, &length, &exportTime, &sequencNum, &obsDomainID, &setID, &setLen); err != nil {
+	if err := util.Decode(packetBuffer, binary.BigEndian, &version, &length, &exportTime, &sequencNum, &obsDomainID); err != nil {
 		return nil, err
 	}
 	if version != uint16(10) {
 		return nil, fmt.Errorf("collector only supports IPFIX (v10); invalid version %d received", version)
 	}
-
-	message := entities.NewMessage(true)
-	message.SetVersion(version)
-	message.SetMessageLen(length)
-	message.SetExportTime(exportTime)
-	message.SetSequenceNum(sequencNum)
-	message.SetObsDomainID(obsDomainID)
+	if cp.strictLength && int(length) != packetLen {
+		return nil, fmt.Errorf("message length %d does not match the %d bytes received", length, packetLen)
+	}
+	if cp.maxObsDomains > 0 {
+		cp.touchObsDomain(obsDomainID)
+	}
 
 	// handle IPv6 address which may involve []
-	portIndex := strings.LastIndex(exportAddress, ":")
-	exportAddress = exportAddress[:portIndex]
+	if portIndex := strings.LastIndex(exportAddress, ":"); portIndex >= 0 {
+		exportAddress = exportAddress[:portIndex]
+	}
 	exportAddress = strings.Replace(exportAddress, "[", "", -1)
 	exportAddress = strings.Replace(exportAddress, "]", "", -1)
-	message.SetExportAddress(exportAddress)
+	exporter := cp.getTemplateExporter(exportAddress)
 
-	var set entities.Set
-	var err error
-	if setID == entities.TemplateSetID {
-		set, err = cp.decodeTemplateSet(packetBuffer, obsDomainID)
+	var messages []*entities.Message
+	for len(messages) == 0 || packetBuffer.Len() >= entities.SetHeaderLen {
+		var setID, setLen uint16
+		if err := util.Decode(packetBuffer, binary.BigEndian, &setID, &setLen); err != nil {
+			return nil, err
+		}
+		setBodyLen := int(setLen) - entities.SetHeaderLen
+		isValidSetLen := setBodyLen >= 0 && setBodyLen <= packetBuffer.Len()
+		if cp.strictLength && !isValidSetLen {
+			return nil, fmt.Errorf("set length %d does not match the message length %d", setLen, length)
+		}
+		// Unless the length is checked strictly, the last set of the message, or
+		// a set with an invalid length, extends to the end of the packet.
+		isLastSet := packetLen-packetBuffer.Len()+setBodyLen >= int(length)
+		if !isValidSetLen || (!cp.strictLength && isLastSet) {
+			setBodyLen = packetBuffer.Len()
+		}
+		setBuffer := bytes.NewBuffer(packetBuffer.Next(setBodyLen))
+		// The sets must fill the message.
+		if remaining := packetBuffer.Len(); cp.strictLength && remaining > 0 && remaining < entities.SetHeaderLen {
+			return nil, fmt.Errorf("set length %d does not match the message length %d", setLen, length)
+		}
+		set, err := cp.decodeSet(setBuffer, sessionAddress, exporter, obsDomainID, setID)
 		if err != nil {
 			return nil, fmt.Errorf("error in decoding message: %v", err)
 		}
-	} else {
-		set, err = cp.decodeDataSet(packetBuffer, obsDomainID, setID)
+		message := entities.NewMessage(true)
+		message.SetVersion(version)
+		message.SetMessageLen(length)
+		message.SetExportTime(exportTime)
+		message.SetSequenceNum(sequencNum)
+		message.SetObsDomainID(obsDomainID)
+		message.SetReceiveTime(receiveTime)
+		message.SetExportAddress(exportAddress)
+		message.AddSet(set)
+		messages = append(messages, message)
+		if set.GetSetType() == entities.Data {
+			sequencNum += uint32(set.GetNumberOfRecords())
+		}
+	}
+	return messages, nil
+}
+
+// decodeSet decodes the set with the given set ID from the buffer.
+func (cp *CollectingProcess) decodeSet(setBuffer *bytes.Buffer, sessionAddress string, exporter string, obsDomainID uint32, setID uint16) (entities.Set, error) {
+	cp.mutex.RLock()
+	templateID, overridden := cp.dataSetIDOverrides[setID]
+	cp.mutex.RUnlock()
+	if overridden {
+		klog.V(4).InfoS("Decoding set as data set", "setID", setID, "templateID", templateID)
+		setID = templateID
+	}
+
+	if !overridden && (setID == entities.TemplateSetID || setID == entities.OptionsTemplateSetID) {
+		set, err := cp.decodeTemplateSet(setBuffer, exporter, obsDomainID, setID == entities.OptionsTemplateSetID)
 		if err != nil {
-			return nil, fmt.Errorf("error in decoding message: %v", err)
+			return nil, err
 		}
+		cp.addSessionTemplates(sessionAddress, exporter, obsDomainID, set)
+		return set, nil
+	}
+	set, err := cp.decodeDataSet(setBuffer, exporter, obsDomainID, setID)
+	if err != nil {
+		return nil, err
+	}
+	cp.updateApplicationNames(set)
+	cp.updateFlowTimeouts(obsDomainID, set)
+	if cp.registerElementsFromOptions {
+		cp.updateElementDefinitions(obsDomainID, set)
 	}
-	message.AddSet(set)
+	return set, nil
+}
 
-	// the thread(s)/client(s) executing the code will get blocked until the message is consumed/read in other goroutines.
-	cp.messageChan <- message
-	cp.incrementNumRecordsReceived()
-	return message, nil
+// getTemplateExporter returns the exporter under which the templates received
+// from the given exporter address are stored, which is empty unless templates
+// are isolated by exporter.
+func (cp *CollectingProcess) getTemplateExporter(exportAddress string) string {
+	if !cp.isolateTemplatesByExporter {
+		return ""
+	}
+	return exportAddress
 }
 
-func (cp *CollectingProcess) decodeTemplateSet(templateBuffer *bytes.Buffer, obsDomainID uint32) (entities.Set, error) {
+// decodeTemplateSet decodes a template set, or an options template set if
+// isOptionsTemplate is true. The scope fields of an options template record
+// are decoded like the other fields.
+func (cp *CollectingProcess) decodeTemplateSet(templateBuffer *bytes.Buffer, exporter string, obsDomainID uint32, isOptionsTemplate bool) (entities.Set, error) {
 	var templateID uint16
 	var fieldCount uint16
 	if err := util.Decode(templateBuffer, binary.BigEndian, &templateID, &fieldCount); err != nil {
 		return nil, err
 	}
+	if fieldCount == 0 {
+		return cp.withdrawTemplates(exporter, obsDomainID, templateID, isOptionsTemplate)
+	}
+	var scopeFieldCount uint16
+	if isOptionsTemplate {
+		if err := util.Decode(templateBuffer, binary.BigEndian, &scopeFieldCount); err != nil {
+			return nil, err
+		}
+		if scopeFieldCount == 0 || scopeFieldCount > fieldCount {
+			return nil, fmt.Errorf("invalid scope field count %d for options template %d with field count %d", scopeFieldCount, templateID, fieldCount)
+		}
+	}
 
 	templateSet := entities.NewSet(true)
-	if err := templateSet.PrepareSet(entities.Template, templateID); err != nil {
+	setType := entities.Template
+	if isOptionsTemplate {
+		setType = entities.OptionsTemplate
+	}
+	if err := templateSet.PrepareSet(setType, templateID); err != nil {
 		return nil, err
 	}
 	elementsWithValue := make([]entities.InfoElementWithValue, int(fieldCount))
@@ -245,7 +1045,7 @@ func (cp *CollectingProcess) decodeTemplateSet(templateBuffer *bytes.Buffer, obs
 		if !isNonIANARegistry {
 			elementID = binary.BigEndian.Uint16(elementid)
 			enterpriseID = registry.IANAEnterpriseID
-			element, err = registry.GetInfoElementFromID(elementID, enterpriseID)
+			element, err = cp.getInfoElementFromID(obsDomainID, elementID, enterpriseID)
 			if err != nil {
 				return nil, err
 			}
@@ -271,27 +1071,66 @@ func (cp *CollectingProcess) decodeTemplateSet(templateBuffer *bytes.Buffer, obs
 			}
 			elementid[0] = elementid[0] ^ 0x80
 			elementID = binary.BigEndian.Uint16(elementid)
-			element, err = registry.GetInfoElementFromID(elementID, enterpriseID)
+			element, err = cp.getInfoElementFromID(obsDomainID, elementID, enterpriseID)
 			if err != nil {
 				return nil, err
 			}
 		}
+		// A field of length 0 would not consume any byte of the data records,
+		// and the data sets for the template could not be decoded.
+		if elementLength == 0 {
+			return nil, fmt.Errorf("invalid length 0 for element %s", element.Name)
+		}
+		if err := entities.ValidateAddressLength(element, int(elementLength)); err != nil {
+			return nil, err
+		}
+		// A fixed-length element cannot be longer than its registered length, the
+		// field would otherwise be misaligned with the following ones.
+		if element.Len != entities.VariableLength && elementLength > element.Len {
+			return nil, fmt.Errorf("invalid length %d for element %s: exceeds its length %d", elementLength, element.Name, element.Len)
+		}
+		// The exporter may use reduced-size encoding (RFC 7011 section 6.2), in which
+		// case the field length in the template is smaller than the one in the registry.
+		if elementLength < element.Len && entities.IsReducedSizeEncodingSupported(element.DataType) {
+			element = withLength(element, elementLength)
+		}
+		// Variable-length octet arrays may be declared with a fixed length in the template.
+		if element.DataType == entities.OctetArray && element.Len == entities.VariableLength && elementLength != entities.VariableLength {
+			element = withLength(element, elementLength)
+		}
 		if elementsWithValue[i], err = entities.DecodeAndCreateInfoElementWithValue(element, nil); err != nil {
 			return nil, err
 		}
 	}
-	err := templateSet.AddRecord(elementsWithValue, templateID)
+	if missingElements := cp.checkRequiredElements(obsDomainID, templateID, elementsWithValue); len(missingElements) > 0 && cp.rejectTemplatesMissingRequiredElements {
+		return nil, fmt.Errorf("template %d with obsDomainID %d is missing required elements %v", templateID, obsDomainID, missingElements)
+	}
+	var err error
+	if isOptionsTemplate {
+		err = templateSet.AddOptionsTemplateRecord(elementsWithValue, int(scopeFieldCount), templateID)
+	} else {
+		err = templateSet.AddRecord(elementsWithValue, templateID)
+	}
 	if err != nil {
 		return nil, err
 	}
-	cp.addTemplate(obsDomainID, templateID, elementsWithValue)
+	cp.addOptionsTemplate(exporter, obsDomainID, templateID, elementsWithValue, scopeFieldCount)
 	return templateSet, nil
 }
 
-func (cp *CollectingProcess) decodeDataSet(dataBuffer *bytes.Buffer, obsDomainID uint32, templateID uint16) (entities.Set, error) {
+// withLength returns a copy of the element with the given length, keeping the
+// rest of its registry metadata.
+func withLength(element *entities.InfoElement, length uint16) *entities.InfoElement {
+	elementWithLength := *element
+	elementWithLength.Len = length
+	return &elementWithLength
+}
+
+func (cp *CollectingProcess) decodeDataSet(dataBuffer *bytes.Buffer, exporter string, obsDomainID uint32, templateID uint16) (entities.Set, error) {
 	// make sure template exists
-	template, err := cp.getTemplate(obsDomainID, templateID)
+	template, err := cp.getTemplate(exporter, obsDomainID, templateID)
 	if err != nil {
+		cp.metrics.incUnknownTemplateDrops()
 		return nil, fmt.Errorf("template %d with obsDomainID %d does not exist", templateID, obsDomainID)
 	}
 	dataSet := entities.NewSet(true)
@@ -299,40 +1138,272 @@ func (cp *CollectingProcess) decodeDataSet(dataBuffer *bytes.Buffer, obsDomainID
 		return nil, err
 	}
 
+	structuredDataDecoder := &entities.StructuredDataDecoder{
+		GetInfoElement: func(elementID uint16, enterpriseID uint32) (*entities.InfoElement, error) {
+			return cp.getInfoElementFromID(obsDomainID, elementID, enterpriseID)
+		},
+		GetTemplate: func(templateID uint16) ([]*entities.InfoElement, error) {
+			return cp.getTemplate(exporter, obsDomainID, templateID)
+		},
+		MaxDepth: cp.maxStructuredDataDepth,
+	}
 	for dataBuffer.Len() > 0 {
 		elements := make([]entities.InfoElementWithValue, len(template))
+		recordLen := dataBuffer.Len()
+		remaining := dataBuffer.Bytes()
 		for i, element := range template {
+			start := recordLen - dataBuffer.Len()
 			var length int
 			if element.Len == entities.VariableLength { // string
-				length = getFieldLength(dataBuffer)
+				length, err = getFieldLength(dataBuffer)
 			} else {
 				length = int(element.Len)
 			}
-			if elements[i], err = entities.DecodeAndCreateInfoElementWithValue(element, dataBuffer.Next(length)); err != nil {
+			value := dataBuffer.Next(length)
+			if (err != nil || len(value) < length) && cp.captureTrailingBytes && dataSet.GetNumberOfRecords() > 0 {
+				if err = cp.addTrailingBytes(dataSet, remaining, obsDomainID, templateID); err != nil {
+					return nil, err
+				}
+				return dataSet, nil
+			}
+			if err != nil {
+				err = fmt.Errorf("invalid length for element %s: %v", element.Name, err)
+			} else if len(value) < length {
+				err = fmt.Errorf("insufficient data for element %s: expected %d bytes, got %d", element.Name, length, len(value))
+			} else if entities.IsStructuredDataType(element.DataType) && cp.skipStructuredData {
+				// the element is created with a nil value
+				elements[i], err = entities.DecodeAndCreateInfoElementWithValue(element, nil)
+			} else if entities.IsStructuredDataType(element.DataType) {
+				elements[i], err = structuredDataDecoder.Decode(element, value)
+			} else {
+				elements[i], err = entities.DecodeAndCreateInfoElementWithValue(element, value)
+			}
+			if err != nil {
+				cp.incrementDecodeErrors(obsDomainID, templateID)
 				return nil, err
 			}
+			if cp.normalizeNumericTypes {
+				elements[i] = entities.NormalizeNumericType(elements[i])
+			}
+			if cp.recordElementOffsets {
+				elements[i].SetByteOffsets(start, recordLen-dataBuffer.Len())
+			}
+		}
+		// Guard against looping forever on a template whose records are empty.
+		if dataBuffer.Len() == recordLen {
+			cp.incrementDecodeErrors(obsDomainID, templateID)
+			return nil, fmt.Errorf("data record for template %d does not contain any byte", templateID)
 		}
 		err = dataSet.AddRecordWithExtraElements(elements, cp.numExtraElements, templateID)
 		if err != nil {
+			cp.incrementDecodeErrors(obsDomainID, templateID)
 			return nil, err
 		}
 	}
 	return dataSet, nil
 }
 
-func (cp *CollectingProcess) addTemplate(obsDomainID uint32, templateID uint16, elementsWithValue []entities.InfoElementWithValue) {
+// addTrailingBytes appends the trailing bytes of a data set as an octetArray
+// element to the last record of the set.
+func (cp *CollectingProcess) addTrailingBytes(dataSet entities.Set, trailingBytes []byte, obsDomainID uint32, templateID uint16) error {
+	klog.V(2).InfoS("Captured trailing bytes of data set", "obsDomainID", obsDomainID, "templateID", templateID, "length", len(trailingBytes))
+	element := entities.NewInfoElement(TrailingBytesElementName, 0, entities.OctetArray, registry.IANAEnterpriseID, entities.VariableLength)
+	records := dataSet.GetRecords()
+	return records[len(records)-1].AddInfoElement(entities.NewOctetArrayInfoElement(element, trailingBytes))
+}
+
+func (cp *CollectingProcess) incrementDecodeErrors(obsDomainID uint32, templateID uint16) {
 	cp.mutex.Lock()
 	defer cp.mutex.Unlock()
-	if _, exists := cp.templatesMap[obsDomainID]; !exists {
-		cp.templatesMap[obsDomainID] = make(map[uint16][]*entities.InfoElement)
+	if cp.decodeErrorsByTemplate == nil {
+		cp.decodeErrorsByTemplate = make(map[uint32]map[uint16]int64)
+	}
+	if _, exist := cp.decodeErrorsByTemplate[obsDomainID]; !exist {
+		cp.decodeErrorsByTemplate[obsDomainID] = make(map[uint16]int64)
+	}
+	cp.decodeErrorsByTemplate[obsDomainID][templateID]++
+}
+
+// GetDecodeErrorsByTemplate returns the number of data sets which could not be
+// decoded, for each obsDomainID and template ID. Only data sets for which the
+// template was known are counted, e.g. data sets truncated or inconsistent
+// with their template.
+func (cp *CollectingProcess) GetDecodeErrorsByTemplate() map[uint32]map[uint16]int64 {
+	cp.mutex.RLock()
+	defer cp.mutex.RUnlock()
+	decodeErrors := make(map[uint32]map[uint16]int64, len(cp.decodeErrorsByTemplate))
+	for obsDomainID, errorsByTemplate := range cp.decodeErrorsByTemplate {
+		decodeErrors[obsDomainID] = make(map[uint16]int64, len(errorsByTemplate))
+		for templateID, count := range errorsByTemplate {
+			decodeErrors[obsDomainID][templateID] = count
+		}
+	}
+	return decodeErrors
+}
+
+// checkRequiredElements returns the required elements missing from the template
+// and raises an alert if there is any.
+func (cp *CollectingProcess) checkRequiredElements(obsDomainID uint32, templateID uint16, elementsWithValue []entities.InfoElementWithValue) []string {
+	cp.mutex.RLock()
+	requiredElements := cp.requiredElements
+	handler := cp.schemaAlertHandler
+	cp.mutex.RUnlock()
+	if len(requiredElements) == 0 {
+		return nil
+	}
+	elementNames := make(map[string]bool, len(elementsWithValue))
+	for _, elementWithValue := range elementsWithValue {
+		elementNames[elementWithValue.GetInfoElement().Name] = true
+	}
+	var missingElements []string
+	for _, name := range requiredElements {
+		if !elementNames[name] {
+			missingElements = append(missingElements, name)
+		}
+	}
+	if len(missingElements) == 0 {
+		return nil
+	}
+	klog.InfoS("Template is missing required elements", "obsDomainID", obsDomainID, "templateID", templateID, "missingElements", missingElements)
+	if handler != nil {
+		handler(TemplateSchemaAlert{
+			ObsDomainID:     obsDomainID,
+			TemplateID:      templateID,
+			MissingElements: missingElements,
+		})
+	}
+	return missingElements
+}
+
+// updateApplicationNames stores the application names from the records which
+// map an applicationId to an applicationName, which are typically options
+// records describing the applications.
+func (cp *CollectingProcess) updateApplicationNames(set entities.Set) {
+	for _, record := range set.GetRecords() {
+		applicationID, _, exist := record.GetInfoElementWithValue("applicationId")
+		if !exist || applicationID.GetDataType() != entities.OctetArray {
+			continue
+		}
+		applicationName, _, exist := record.GetInfoElementWithValue("applicationName")
+		if !exist {
+			continue
+		}
+		cp.mutex.Lock()
+		if cp.applicationNames == nil {
+			cp.applicationNames = make(map[string]string)
+		}
+		cp.applicationNames[string(applicationID.GetOctetArrayValue())] = applicationName.GetStringValue()
+		cp.mutex.Unlock()
+	}
+}
+
+// GetApplicationName returns the application name for the given applicationId,
+// as received in options records from the exporters. The applicationId
+// consists of the classificationEngineId followed by the selector ID.
+func (cp *CollectingProcess) GetApplicationName(applicationID []byte) (string, bool) {
+	cp.mutex.RLock()
+	defer cp.mutex.RUnlock()
+	name, exist := cp.applicationNames[string(applicationID)]
+	return name, exist
+}
+
+// updateFlowTimeouts stores the flowActiveTimeout and flowIdleTimeout values
+// of the records, which are typically options records describing the metering
+// process of the observation domain.
+func (cp *CollectingProcess) updateFlowTimeouts(obsDomainID uint32, set entities.Set) {
+	for _, record := range set.GetRecords() {
+		activeTimeout, _, activeExist := record.GetInfoElementWithValue("flowActiveTimeout")
+		idleTimeout, _, idleExist := record.GetInfoElementWithValue("flowIdleTimeout")
+		if !activeExist && !idleExist {
+			continue
+		}
+		cp.mutex.Lock()
+		if cp.flowTimeouts == nil {
+			cp.flowTimeouts = make(map[uint32]FlowTimeouts)
+		}
+		timeouts := cp.flowTimeouts[obsDomainID]
+		if activeExist && activeTimeout.GetDataType() == entities.Unsigned16 {
+			timeouts.ActiveTimeout = time.Duration(activeTimeout.GetUnsigned16Value()) * time.Second
+		}
+		if idleExist && idleTimeout.GetDataType() == entities.Unsigned16 {
+			timeouts.IdleTimeout = time.Duration(idleTimeout.GetUnsigned16Value()) * time.Second
+		}
+		cp.flowTimeouts[obsDomainID] = timeouts
+		cp.mutex.Unlock()
 	}
+}
+
+// GetFlowTimeouts returns the flow timeouts of the observation domain, as
+// received in options records from the exporters. A flow should have been
+// exported at the latest after the active timeout, or after the idle timeout
+// once inactive.
+func (cp *CollectingProcess) GetFlowTimeouts(obsDomainID uint32) (FlowTimeouts, bool) {
+	cp.mutex.RLock()
+	defer cp.mutex.RUnlock()
+	timeouts, exist := cp.flowTimeouts[obsDomainID]
+	return timeouts, exist
+}
+
+func (cp *CollectingProcess) addTemplate(exporter string, obsDomainID uint32, templateID uint16, elementsWithValue []entities.InfoElementWithValue) {
+	cp.addOptionsTemplate(exporter, obsDomainID, templateID, elementsWithValue, 0)
+}
+
+// addOptionsTemplate adds an options template whose first scopeFieldCount
+// elements are the scope fields, or a normal template if scopeFieldCount is 0.
+func (cp *CollectingProcess) addOptionsTemplate(exporter string, obsDomainID uint32, templateID uint16, elementsWithValue []entities.InfoElementWithValue, scopeFieldCount uint16) {
 	elements := make([]*entities.InfoElement, 0)
 	for _, elementWithValue := range elementsWithValue {
 		elements = append(elements, elementWithValue.GetInfoElement())
 	}
-	cp.templatesMap[obsDomainID][templateID] = elements
-	// template lifetime management
-	if cp.protocol == "tcp" {
+	cp.addTemplateElements(exporter, obsDomainID, templateID, elements, scopeFieldCount)
+}
+
+func (cp *CollectingProcess) addTemplateElements(exporter string, obsDomainID uint32, templateID uint16, elements []*entities.InfoElement, scopeFieldCount uint16) {
+	var handler TemplateAddedHandler
+	// Deferred before unlocking the mutex, so that the handler is called
+	// after the mutex is released.
+	defer func() {
+		if handler != nil {
+			handler(obsDomainID, templateID, elements)
+		}
+	}()
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	handler = cp.templateAddedHandler
+	if cp.deduplicateTemplates {
+		if existing, exists := cp.getTemplateStore(exporter).Get(obsDomainID, templateID); exists && isSameTemplate(existing, elements) {
+			handler = nil
+		}
+	}
+	if cp.maxObsDomains > 0 {
+		if _, exists := cp.obsDomainLastUsed[obsDomainID]; !exists {
+			if len(cp.obsDomainLastUsed) >= cp.maxObsDomains {
+				cp.evictLeastRecentlyUsedObsDomain()
+			}
+			cp.touchObsDomainLocked(obsDomainID)
+		}
+	}
+	if exporter != "" {
+		if cp.exporterTemplates == nil {
+			cp.exporterTemplates = make(map[string]memoryTemplateStore)
+		}
+		if _, exists := cp.exporterTemplates[exporter]; !exists {
+			cp.exporterTemplates[exporter] = make(memoryTemplateStore)
+		}
+	}
+	cp.getTemplateStore(exporter).Put(obsDomainID, templateID, elements)
+	key := templateKey{obsDomainID, templateID, exporter}
+	if scopeFieldCount > 0 {
+		if cp.scopeFieldCounts == nil {
+			cp.scopeFieldCounts = make(map[templateKey]uint16)
+		}
+		cp.scopeFieldCounts[key] = scopeFieldCount
+	} else {
+		delete(cp.scopeFieldCounts, key)
+	}
+	// template lifetime management: templates do not expire with reliable
+	// transports.
+	if cp.protocol == "tcp" || cp.protocol == "sctp" {
 		return
 	}
 
@@ -340,32 +1411,245 @@ func (cp *CollectingProcess) addTemplate(obsDomainID uint32, templateID uint16,
 	if cp.templateTTL == 0 {
 		cp.templateTTL = entities.TemplateTTL // Default value
 	}
+	if cp.templateGenerations == nil {
+		cp.templateGenerations = make(map[templateKey]uint64)
+	}
+	cp.lastTemplateGeneration++
+	generation := cp.lastTemplateGeneration
+	cp.templateGenerations[key] = generation
+	templateTTL := cp.templateTTL
+	if ttl, exists := cp.templateTTLByObsDomain[obsDomainID]; exists && ttl > 0 {
+		templateTTL = ttl
+	}
 	go func() {
-		ticker := time.NewTicker(time.Duration(cp.templateTTL) * time.Second)
+		ticker := time.NewTicker(time.Duration(templateTTL) * time.Second)
 		defer ticker.Stop()
 		select {
 		case <-ticker.C:
-			klog.Infof("Template with id %d, and obsDomainID %d is expired.", templateID, obsDomainID)
-			cp.deleteTemplate(obsDomainID, templateID)
+			cp.expireTemplate(key, generation)
 			break
 		}
 	}()
 }
 
-func (cp *CollectingProcess) getTemplate(obsDomainID uint32, templateID uint16) ([]*entities.InfoElement, error) {
+// isSameTemplate returns whether the templates have the same field specifiers,
+// i.e. whether their template records are byte-identical.
+func isSameTemplate(elements1, elements2 []*entities.InfoElement) bool {
+	if len(elements1) != len(elements2) {
+		return false
+	}
+	for i := range elements1 {
+		if elements1[i].ElementId != elements2[i].ElementId || elements1[i].EnterpriseId != elements2[i].EnterpriseId || elements1[i].Len != elements2[i].Len {
+			return false
+		}
+	}
+	return true
+}
+
+// expireTemplate deletes the template if it has not been refreshed since the
+// given generation.
+func (cp *CollectingProcess) expireTemplate(key templateKey, generation uint64) {
+	var handler TemplateExpiredHandler
+	// Deferred before unlocking the mutex, so that the handler is called
+	// after the mutex is released.
+	defer func() {
+		if handler != nil {
+			handler(key.obsDomainID, key.templateID)
+		}
+	}()
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	if cp.templateGenerations[key] != generation {
+		return
+	}
+	handler = cp.templateExpiredHandler
+	klog.Infof("Template with id %d, and obsDomainID %d is expired.", key.templateID, key.obsDomainID)
+	cp.getTemplateStore(key.exporter).Expire(key.obsDomainID, key.templateID)
+	delete(cp.templateGenerations, key)
+	if cp.templateExpiries == nil {
+		cp.templateExpiries = make(map[templateKey]uint64)
+	}
+	cp.templateExpiries[key]++
+}
+
+// GetNumTemplateExpiries returns how many times the UDP template with the given
+// obsDomainID and template ID expired before being refreshed by the exporter.
+// A non-zero value usually means that the template refresh interval of the
+// exporter is larger than the template TTL of the collecting process.
+func (cp *CollectingProcess) GetNumTemplateExpiries(obsDomainID uint32, templateID uint16) uint64 {
 	cp.mutex.RLock()
 	defer cp.mutex.RUnlock()
-	if elements, exists := cp.templatesMap[obsDomainID][templateID]; exists {
+	var numExpiries uint64
+	for key, count := range cp.templateExpiries {
+		if key.obsDomainID == obsDomainID && key.templateID == templateID {
+			numExpiries += count
+		}
+	}
+	return numExpiries
+}
+
+// touchObsDomain marks the observation domain as used if templates were
+// received for it.
+func (cp *CollectingProcess) touchObsDomain(obsDomainID uint32) {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	if _, exists := cp.obsDomainLastUsed[obsDomainID]; exists {
+		cp.touchObsDomainLocked(obsDomainID)
+	}
+}
+
+func (cp *CollectingProcess) touchObsDomainLocked(obsDomainID uint32) {
+	if cp.obsDomainLastUsed == nil {
+		cp.obsDomainLastUsed = make(map[uint32]uint64)
+	}
+	cp.obsDomainClock++
+	cp.obsDomainLastUsed[obsDomainID] = cp.obsDomainClock
+}
+
+// evictLeastRecentlyUsedObsDomain deletes all the templates of the least
+// recently used observation domain. The caller must hold the mutex.
+func (cp *CollectingProcess) evictLeastRecentlyUsedObsDomain() {
+	var evicted uint32
+	found := false
+	for obsDomainID := range cp.obsDomainLastUsed {
+		if !found || cp.obsDomainLastUsed[obsDomainID] < cp.obsDomainLastUsed[evicted] {
+			evicted = obsDomainID
+			found = true
+		}
+	}
+	if !found {
+		return
+	}
+	klog.InfoS("Maximum number of observation domains reached, evicting the templates of the least recently used observation domain",
+		"maxObservationDomains", cp.maxObsDomains, "observationDomainID", evicted)
+	for exporter, store := range cp.getTemplateStores() {
+		for _, templateID := range store.TemplateIDs(evicted) {
+			store.Delete(evicted, templateID)
+			delete(cp.templateGenerations, templateKey{evicted, templateID, exporter})
+		}
+	}
+	delete(cp.obsDomainLastUsed, evicted)
+	cp.numObsDomainsEvicted++
+}
+
+// GetNumObsDomainsEvicted returns the number of observation domains whose
+// templates were evicted because of MaxObservationDomains.
+func (cp *CollectingProcess) GetNumObsDomainsEvicted() int64 {
+	cp.mutex.RLock()
+	defer cp.mutex.RUnlock()
+	return int64(cp.numObsDomainsEvicted)
+}
+
+func (cp *CollectingProcess) getTemplate(exporter string, obsDomainID uint32, templateID uint16) ([]*entities.InfoElement, error) {
+	cp.mutex.RLock()
+	defer cp.mutex.RUnlock()
+	if elements, exists := cp.getTemplateStore(exporter).Get(obsDomainID, templateID); exists {
 		return elements, nil
 	} else {
 		return nil, fmt.Errorf("template %d with obsDomainID %d does not exist", templateID, obsDomainID)
 	}
 }
 
-func (cp *CollectingProcess) deleteTemplate(obsDomainID uint32, templateID uint16) {
+// withdrawTemplates handles a template withdrawal (RFC 7011 section 8.1), i.e.
+// a template record with a field count of 0, and returns the decoded set. A
+// template set holds a single record without elements, while an options
+// template set holds no records as options template records always have scope
+// fields. If the template ID is the set ID, i.e. 2 for a template
+// set or 3 for an options template set, all the templates, or all the options
+// templates, of the observation domain are withdrawn. Otherwise the template
+// with the given ID is withdrawn.
+func (cp *CollectingProcess) withdrawTemplates(exporter string, obsDomainID uint32, templateID uint16, isOptionsTemplate bool) (entities.Set, error) {
+	setType := entities.Template
+	setID := entities.TemplateSetID
+	if isOptionsTemplate {
+		setType = entities.OptionsTemplate
+		setID = entities.OptionsTemplateSetID
+	}
+	templateSet := entities.NewSet(true)
+	if err := templateSet.PrepareSet(setType, templateID); err != nil {
+		return nil, err
+	}
+	if !isOptionsTemplate {
+		if err := templateSet.AddRecord(nil, templateID); err != nil {
+			return nil, err
+		}
+	}
 	cp.mutex.Lock()
 	defer cp.mutex.Unlock()
-	delete(cp.templatesMap[obsDomainID], templateID)
+	store := cp.getTemplateStore(exporter)
+	if templateID != setID {
+		klog.V(2).InfoS("Withdrawing template", "observationDomainID", obsDomainID, "templateID", templateID)
+		store.Delete(obsDomainID, templateID)
+		delete(cp.scopeFieldCounts, templateKey{obsDomainID, templateID, exporter})
+		delete(cp.templateGenerations, templateKey{obsDomainID, templateID, exporter})
+		return templateSet, nil
+	}
+	klog.V(2).InfoS("Withdrawing all templates", "observationDomainID", obsDomainID, "setID", setID)
+	for _, id := range store.TemplateIDs(obsDomainID) {
+		if _, isOptions := cp.getScopeFieldCountLocked(exporter, obsDomainID, id); isOptions == isOptionsTemplate {
+			store.Delete(obsDomainID, id)
+			delete(cp.scopeFieldCounts, templateKey{obsDomainID, id, exporter})
+			delete(cp.templateGenerations, templateKey{obsDomainID, id, exporter})
+		}
+	}
+	return templateSet, nil
+}
+
+// GetScopeFieldCount returns the number of scope fields of the template if it
+// is an options template. It returns false for a normal template, or if the
+// template does not exist.
+func (cp *CollectingProcess) GetScopeFieldCount(obsDomainID uint32, templateID uint16) (int, bool) {
+	cp.mutex.RLock()
+	defer cp.mutex.RUnlock()
+	return cp.getScopeFieldCountLocked("", obsDomainID, templateID)
+}
+
+func (cp *CollectingProcess) getScopeFieldCountLocked(exporter string, obsDomainID uint32, templateID uint16) (int, bool) {
+	if _, exists := cp.getTemplateStore(exporter).Get(obsDomainID, templateID); !exists {
+		return 0, false
+	}
+	scopeFieldCount, exists := cp.scopeFieldCounts[templateKey{obsDomainID, templateID, exporter}]
+	return int(scopeFieldCount), exists
+}
+
+func (cp *CollectingProcess) deleteTemplate(exporter string, obsDomainID uint32, templateID uint16) {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	cp.getTemplateStore(exporter).Delete(obsDomainID, templateID)
+}
+
+func (cp *CollectingProcess) addSession(address string) {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	if cp.sessionTemplates == nil {
+		cp.sessionTemplates = make(map[string]map[templateKey]struct{})
+	}
+	cp.sessionTemplates[address] = make(map[templateKey]struct{})
+}
+
+// addSessionTemplates records the templates of the set as received in the DTLS
+// session with the given address, if any.
+func (cp *CollectingProcess) addSessionTemplates(address string, exporter string, obsDomainID uint32, set entities.Set) {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	templates, exist := cp.sessionTemplates[address]
+	if !exist {
+		return
+	}
+	for _, record := range set.GetRecords() {
+		templates[templateKey{obsDomainID, record.GetTemplateID(), exporter}] = struct{}{}
+	}
+}
+
+// deleteSession deletes the templates received in the DTLS session with the
+// given address.
+func (cp *CollectingProcess) deleteSession(address string) {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	for key := range cp.sessionTemplates[address] {
+		cp.getTemplateStore(key.exporter).Delete(key.obsDomainID, key.templateID)
+	}
+	delete(cp.sessionTemplates, address)
 }
 
 func (cp *CollectingProcess) updateAddress(address net.Addr) {
@@ -388,14 +1672,72 @@ func getMessageLength(reader *bufio.Reader) (int, error) {
 	return int(msgLen), nil
 }
 
-// getFieldLength returns string field length for data record
+// maxCompressedPayloadLength is the maximum length of the payload of a
+// compressed message. It leaves room for the gzip overhead of an IPFIX message
+// of the maximum size which cannot be compressed.
+const maxCompressedPayloadLength = entities.MaxSocketMsgSize + 1024
+
+// getCompressedMessageLength returns the length of the compressed message,
+// including the wrapper header, by decoding the wrapper header.
+func getCompressedMessageLength(reader *bufio.Reader) (int, error) {
+	header, err := reader.Peek(entities.CompressedMsgHeaderLength)
+	if err != nil {
+		return 0, err
+	}
+	var payloadLen uint32
+	err = util.Decode(bytes.NewBuffer(header[2:]), binary.BigEndian, &payloadLen)
+	if err != nil {
+		return 0, fmt.Errorf("cannot decode compressed message header: %w", err)
+	}
+	if int(payloadLen) > maxCompressedPayloadLength {
+		return 0, fmt.Errorf("compressed message length %d exceeds the maximum length %d", payloadLen, maxCompressedPayloadLength)
+	}
+	return entities.CompressedMsgHeaderLength + int(payloadLen), nil
+}
+
+// decompressMessage strips the wrapper header from the message and returns the
+// decompressed IPFIX message.
+func decompressMessage(msg []byte) ([]byte, error) {
+	if len(msg) < entities.CompressedMsgHeaderLength {
+		return nil, fmt.Errorf("compressed message is shorter than the wrapper header")
+	}
+	flags := binary.BigEndian.Uint16(msg[0:2])
+	payloadLen := int(binary.BigEndian.Uint32(msg[2:6]))
+	if payloadLen != len(msg)-entities.CompressedMsgHeaderLength {
+		return nil, fmt.Errorf("compressed message length %d does not match the wrapper header %d", len(msg)-entities.CompressedMsgHeaderLength, payloadLen)
+	}
+	payload := msg[entities.CompressedMsgHeaderLength:]
+	if flags&entities.CompressedMsgFlagGzip == 0 {
+		return payload, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("error when decompressing message: %v", err)
+	}
+	defer reader.Close()
+	decompressed, err := io.ReadAll(io.LimitReader(reader, int64(entities.MaxSocketMsgSize)+1))
+	if err != nil {
+		return nil, fmt.Errorf("error when decompressing message: %v", err)
+	}
+	if len(decompressed) > entities.MaxSocketMsgSize {
+		return nil, fmt.Errorf("decompressed message size exceeds max message size")
+	}
+	return decompressed, nil
+}
+
+// getFieldLength returns string field length for data record, which is encoded
+// in 1 byte, or in 3 bytes if the first byte is 255
 // (encoding reference: https://tools.ietf.org/html/rfc7011#appendix-A.5)
-func getFieldLength(dataBuffer *bytes.Buffer) int {
-	oneByte, _ := dataBuffer.ReadByte()
+func getFieldLength(dataBuffer *bytes.Buffer) (int, error) {
+	oneByte, err := dataBuffer.ReadByte()
+	if err != nil {
+		return 0, fmt.Errorf("no data left for the length of the variable-length field")
+	}
 	if oneByte < 255 { // string length is less than 255
-		return int(oneByte)
+		return int(oneByte), nil
+	}
+	if dataBuffer.Len() < 2 {
+		return 0, fmt.Errorf("insufficient data for the 3-byte length of the variable-length field: got %d bytes", dataBuffer.Len()+1)
 	}
-	var lengthTwoBytes uint16
-	util.Decode(dataBuffer, binary.BigEndian, &lengthTwoBytes)
-	return int(lengthTwoBytes)
+	return int(binary.BigEndian.Uint16(dataBuffer.Next(2))), nil
 }
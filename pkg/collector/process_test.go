@@ -15,19 +15,32 @@
 package collector
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
 	"net"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/ishidawataru/sctp"
 	"github.com/pion/dtls/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/util/wait"
 
 	"github.com/vmware/go-ipfix/pkg/entities"
+	"github.com/vmware/go-ipfix/pkg/exporter"
 	"github.com/vmware/go-ipfix/pkg/registry"
 	testcerts "github.com/vmware/go-ipfix/pkg/test/certs"
 )
@@ -74,11 +87,149 @@ func TestTCPCollectingProcess_ReceiveTemplateRecord(t *testing.T) {
 	}()
 	<-cp.GetMsgChan()
 	cp.Stop()
-	template, _ := cp.getTemplate(1, 256)
+	template, _ := cp.getTemplate("", 1, 256)
 	assert.NotNil(t, template, "TCP Collecting Process should receive and store the received template.")
 	assert.Equal(t, int64(1), cp.GetNumRecordsReceived())
 }
 
+func TestUDPCollectingProcess_Multicast(t *testing.T) {
+	input := getCollectorInput(udpTransport, false, false)
+	input.Address = "239.255.0.1:0"
+	cp, err := InitCollectingProcess(input)
+	require.NoError(t, err)
+	go cp.Start()
+	defer cp.Stop()
+	var collectorAddr *net.UDPAddr
+	err = wait.Poll(10*time.Millisecond, time.Second, func() (bool, error) {
+		collectorAddr, _ = cp.GetAddress().(*net.UDPAddr)
+		return collectorAddr != nil && collectorAddr.Port != 0, nil
+	})
+	if err != nil {
+		t.Skip("Cannot join multicast group")
+	}
+	assert.True(t, collectorAddr.IP.IsMulticast())
+	// The message sent to the group is looped back to the local member.
+	conn, err := net.DialUDP(udpTransport, nil, collectorAddr)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write(validTemplatePacket)
+	require.NoError(t, err)
+	select {
+	case <-cp.GetMsgChan():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Template sent to the multicast group was not received")
+	}
+	template, _ := cp.getTemplate("", 1, 256)
+	assert.NotNil(t, template)
+}
+
+func TestTCPCollectingProcess_TLSConfig(t *testing.T) {
+	caCert, caKey, caData, err := testcerts.GenerateCACert()
+	require.NoError(t, err)
+	serverCertData, serverKeyData, err := testcerts.GenerateServerCert(caCert, caKey, testcerts.AddIPAddress(net.ParseIP("127.0.0.1")))
+	require.NoError(t, err)
+	clientCertData, clientKeyData, err := testcerts.GenerateClientCert(caCert, caKey)
+	require.NoError(t, err)
+	serverCert, err := tls.X509KeyPair(serverCertData, serverKeyData)
+	require.NoError(t, err)
+	clientCert, err := tls.X509KeyPair(clientCertData, clientKeyData)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(caData))
+
+	input := getCollectorInput(tcpTransport, false, false)
+	input.TLSConfig = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    roots,
+		MinVersion:   tls.VersionTLS12,
+	}
+	cp, err := InitCollectingProcess(input)
+	require.NoError(t, err)
+	go cp.Start()
+	defer cp.Stop()
+	err = wait.Poll(10*time.Millisecond, time.Second, func() (bool, error) {
+		_, ok := cp.GetAddress().(*net.TCPAddr)
+		return ok, nil
+	})
+	require.NoError(t, err)
+	collectorAddr := cp.GetAddress()
+
+	// The handshake fails without client certificate.
+	conn, err := tls.Dial(collectorAddr.Network(), collectorAddr.String(), &tls.Config{RootCAs: roots})
+	if err == nil {
+		conn.Write(validTemplatePacket)
+		defer conn.Close()
+	}
+	err = wait.Poll(10*time.Millisecond, time.Second, func() (bool, error) {
+		return cp.GetNumTLSHandshakeErrors() == 1, nil
+	})
+	require.NoError(t, err, "Failed handshake should be counted")
+	assert.Equal(t, int64(0), cp.GetNumConnToCollector())
+
+	conn, err = tls.Dial(collectorAddr.Network(), collectorAddr.String(), &tls.Config{
+		RootCAs:      roots,
+		Certificates: []tls.Certificate{clientCert},
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write(validTemplatePacket)
+	require.NoError(t, err)
+	<-cp.GetMsgChan()
+	template, _ := cp.getTemplate("", 1, 256)
+	assert.NotNil(t, template)
+	assert.Equal(t, int64(1), cp.GetNumTLSHandshakeErrors())
+}
+
+func TestSCTPCollectingProcess_TemplateExpiry(t *testing.T) {
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		protocol:     "sctp",
+		messageChan:  make(chan *entities.Message, 1),
+	}
+	_, err := cp.decodePacket(bytes.NewBuffer(validTemplatePacket), "127.0.0.1:4739")
+	require.NoError(t, err)
+	template, _ := cp.getTemplate("", 1, 256)
+	assert.NotNil(t, template)
+	// Templates received over SCTP do not expire.
+	assert.Empty(t, cp.templateGenerations)
+}
+
+func TestSCTPCollectingProcess_ReceiveTemplateRecord(t *testing.T) {
+	address, err := sctp.ResolveSCTPAddr("sctp", hostPortIPv4)
+	require.NoError(t, err)
+	listener, err := sctp.ListenSCTP("sctp", address)
+	if err != nil {
+		t.Skipf("SCTP is not supported: %v", err)
+	}
+	listener.Close()
+
+	input := CollectorInput{
+		Address:       hostPortIPv4,
+		Protocol:      "sctp",
+		MaxBufferSize: 1024,
+	}
+	cp, err := InitCollectingProcess(input)
+	require.NoError(t, err)
+	go cp.Start()
+	var collectorAddr *sctp.SCTPAddr
+	err = wait.Poll(10*time.Millisecond, time.Second, func() (bool, error) {
+		collectorAddr, _ = cp.GetAddress().(*sctp.SCTPAddr)
+		return collectorAddr != nil, nil
+	})
+	require.NoError(t, err)
+	conn, err := sctp.DialSCTP("sctp", nil, collectorAddr)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write(validTemplatePacket)
+	require.NoError(t, err)
+	<-cp.GetMsgChan()
+	assert.Equal(t, int64(1), cp.GetNumConnToCollector())
+	cp.Stop()
+	template, _ := cp.getTemplate("", 1, 256)
+	assert.NotNil(t, template, "SCTP Collecting Process should receive and store the received template.")
+}
+
 func TestUDPCollectingProcess_ReceiveTemplateRecord(t *testing.T) {
 	input := getCollectorInput(udpTransport, false, false)
 	cp, err := InitCollectingProcess(input)
@@ -103,7 +254,7 @@ func TestUDPCollectingProcess_ReceiveTemplateRecord(t *testing.T) {
 	}()
 	<-cp.GetMsgChan()
 	cp.Stop()
-	template, _ := cp.getTemplate(1, 256)
+	template, _ := cp.getTemplate("", 1, 256)
 	assert.NotNil(t, template, "UDP Collecting Process should receive and store the received template.")
 	assert.Equal(t, int64(1), cp.GetNumRecordsReceived())
 }
@@ -112,7 +263,7 @@ func TestTCPCollectingProcess_ReceiveDataRecord(t *testing.T) {
 	input := getCollectorInput(tcpTransport, false, false)
 	cp, err := InitCollectingProcess(input)
 	// Add the templates before sending data record
-	cp.addTemplate(uint32(1), uint16(256), elementsWithValueIPv4)
+	cp.addTemplate("", uint32(1), uint16(256), elementsWithValueIPv4)
 	if err != nil {
 		t.Fatalf("TCP Collecting Process does not start correctly: %v", err)
 	}
@@ -148,12 +299,12 @@ func TestUDPCollectingProcess_ReceiveDataRecord(t *testing.T) {
 	input := getCollectorInput(udpTransport, false, false)
 	cp, err := InitCollectingProcess(input)
 	// Add the templates before sending data record
-	cp.addTemplate(uint32(1), uint16(256), elementsWithValueIPv4)
+	cp.addTemplate("", uint32(1), uint16(256), elementsWithValueIPv4)
 	if err != nil {
 		t.Fatalf("UDP Collecting Process does not start correctly: %v", err)
 	}
 	// Add the templates before sending data record
-	cp.addTemplate(uint32(1), uint16(256), elementsWithValueIPv4)
+	cp.addTemplate("", uint32(1), uint16(256), elementsWithValueIPv4)
 
 	go cp.Start()
 	// wait until collector is ready
@@ -282,6 +433,1769 @@ func TestCollectingProcess_DecodeTemplateRecord(t *testing.T) {
 	}
 }
 
+func TestCollectingProcess_RequiredElements(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	for _, reject := range []bool{false, true} {
+		cp := CollectingProcess{
+			templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+			netAddress:   address,
+			messageChan:  make(chan *entities.Message, 1),
+
+			rejectTemplatesMissingRequiredElements: reject,
+		}
+		var alerts []TemplateSchemaAlert
+		cp.SetTemplateSchemaAlertHandler(func(alert TemplateSchemaAlert) {
+			alerts = append(alerts, alert)
+		})
+		cp.SetRequiredElements([]string{"sourceIPv4Address", "octetDeltaCount"})
+		_, err = cp.decodePacket(bytes.NewBuffer(validTemplatePacket), address.String())
+		expectedAlert := TemplateSchemaAlert{ObsDomainID: 1, TemplateID: 256, MissingElements: []string{"octetDeltaCount"}}
+		assert.Equal(t, []TemplateSchemaAlert{expectedAlert}, alerts)
+		_, templateErr := cp.getTemplate("", 1, 256)
+		if reject {
+			assert.Error(t, err)
+			assert.Error(t, templateErr, "Template missing required elements should not be used for decoding")
+		} else {
+			assert.NoError(t, err)
+			assert.NoError(t, templateErr)
+		}
+	}
+}
+
+func TestCollectingProcess_DecodeApplicationNameOptions(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+	}
+	// Options template 258 with applicationId (fixed length 4) as scope field
+	// and applicationName (variable length).
+	optionsTemplatePacket := []byte{0, 10, 0, 34, 95, 154, 107, 127, 0, 0, 0, 1, 0, 0, 0, 1, 0, 3, 0, 18, 1, 2, 0, 2, 0, 1, 0, 95, 0, 4, 0, 96, 255, 255}
+	// Options data record mapping applicationId 3:80 to "http".
+	optionsDataPacket := []byte{0, 10, 0, 29, 95, 154, 107, 127, 0, 0, 0, 2, 0, 0, 0, 1, 1, 2, 0, 13, 3, 0, 0, 80, 4, 104, 116, 116, 112}
+	_, err = cp.decodePacket(bytes.NewBuffer(optionsTemplatePacket), address.String())
+	require.NoError(t, err)
+	_, err = cp.decodePacket(bytes.NewBuffer(optionsDataPacket), address.String())
+	require.NoError(t, err)
+	name, exist := cp.GetApplicationName([]byte{3, 0, 0, 80})
+	assert.True(t, exist)
+	assert.Equal(t, "http", name)
+	_, exist = cp.GetApplicationName([]byte{3, 0, 1, 187})
+	assert.False(t, exist)
+}
+
+func TestCollectingProcess_DecodeFlowTimeoutOptions(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+	}
+	// Options template 256 with scope meteringProcessId, flowActiveTimeout and flowIdleTimeout.
+	optionsTemplatePacket := []byte{0, 10, 0, 38, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 2, 0, 3, 0, 22, 1, 0, 0, 3, 0, 1, 0, 143, 0, 4, 0, 36, 0, 2, 0, 37, 0, 2}
+	optionsDataPacket := []byte{0, 10, 0, 28, 95, 154, 108, 18, 0, 0, 0, 0, 0, 0, 0, 2, 1, 0, 0, 12, 0, 0, 0, 1, 0, 60, 0, 15}
+	_, err = cp.decodePacket(bytes.NewBuffer(optionsTemplatePacket), address.String())
+	require.NoError(t, err)
+	_, exist := cp.GetFlowTimeouts(2)
+	assert.False(t, exist)
+	_, err = cp.decodePacket(bytes.NewBuffer(optionsDataPacket), address.String())
+	require.NoError(t, err)
+	timeouts, exist := cp.GetFlowTimeouts(2)
+	require.True(t, exist)
+	assert.Equal(t, FlowTimeouts{ActiveTimeout: 60 * time.Second, IdleTimeout: 15 * time.Second}, timeouts)
+	_, exist = cp.GetFlowTimeouts(1)
+	assert.False(t, exist)
+}
+
+func TestCollectingProcess_ClassifyFlowEnd(t *testing.T) {
+	cp := CollectingProcess{
+		flowTimeouts: map[uint32]FlowTimeouts{
+			1: {ActiveTimeout: 60 * time.Second, IdleTimeout: 15 * time.Second},
+		},
+	}
+	newRecord := func(flowEndReason *uint8, duration time.Duration) entities.Record {
+		var elements []entities.InfoElementWithValue
+		if flowEndReason != nil {
+			element, err := registry.GetInfoElement("flowEndReason", registry.IANAEnterpriseID)
+			require.NoError(t, err)
+			elements = append(elements, entities.NewUnsigned8InfoElement(element, *flowEndReason))
+		}
+		startTime := time.Unix(1700000000, 0)
+		for name, value := range map[string]time.Time{"flowStartMilliseconds": startTime, "flowEndMilliseconds": startTime.Add(duration)} {
+			element, err := registry.GetInfoElement(name, registry.IANAEnterpriseID)
+			require.NoError(t, err)
+			ie, err := entities.NewDateTimeInfoElement(element, value)
+			require.NoError(t, err)
+			elements = append(elements, ie)
+		}
+		set := entities.NewSet(true)
+		require.NoError(t, set.PrepareSet(entities.Data, 256))
+		require.NoError(t, set.AddRecord(elements, 256))
+		return set.GetRecords()[0]
+	}
+	reason := func(reason uint8) *uint8 {
+		return &reason
+	}
+
+	testCases := []struct {
+		name          string
+		flowEndReason *uint8
+		duration      time.Duration
+		obsDomainID   uint32
+		expected      FlowEndClass
+	}{
+		{"active timeout reason", reason(registry.ActiveTimeoutReason), 30 * time.Second, 1, FlowEndActiveTimeout},
+		{"idle timeout reason", reason(registry.IdleTimeoutReason), 30 * time.Second, 1, FlowEndIdleTimeout},
+		{"end of flow reason", reason(registry.EndOfFlowReason), 60 * time.Second, 1, FlowEndNatural},
+		{"lack of resources reason", reason(registry.LackOfResourcesReason), time.Second, 1, FlowEndForced},
+		{"duration reaching active timeout", nil, 60 * time.Second, 1, FlowEndActiveTimeout},
+		{"duration below active timeout", nil, 59 * time.Second, 1, FlowEndUnknown},
+		{"unknown flow timeouts", nil, 60 * time.Second, 2, FlowEndUnknown},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, cp.ClassifyFlowEnd(newRecord(tc.flowEndReason, tc.duration), tc.obsDomainID))
+		})
+	}
+}
+
+func TestUDPCollectingProcess_ReorderMessages(t *testing.T) {
+	input := getCollectorInput(udpTransport, false, false)
+	input.MessageChanSize = 3
+	input.ReorderWindowSize = 4
+	input.ReorderTimeout = time.Minute
+	cp, err := InitCollectingProcess(input)
+	require.NoError(t, err)
+	address, err := net.ResolveUDPAddr(udpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	dataPacket := func(sequenceNum byte) []byte {
+		packet := make([]byte, len(validDataPacket))
+		copy(packet, validDataPacket)
+		packet[11] = sequenceNum
+		return packet
+	}
+	_, err = cp.decodePacket(bytes.NewBuffer(validTemplatePacket), address.String())
+	require.NoError(t, err)
+	template := <-cp.GetMsgChan()
+	assert.Equal(t, uint32(0), template.GetSequenceNum())
+
+	// The second data message arrives first and is held until the first one
+	// is received.
+	_, err = cp.decodePacket(bytes.NewBuffer(dataPacket(1)), address.String())
+	require.NoError(t, err)
+	assert.Len(t, cp.GetMsgChan(), 0)
+	_, err = cp.decodePacket(bytes.NewBuffer(dataPacket(0)), address.String())
+	require.NoError(t, err)
+	require.Len(t, cp.GetMsgChan(), 2)
+	assert.Equal(t, uint32(0), (<-cp.GetMsgChan()).GetSequenceNum())
+	assert.Equal(t, uint32(1), (<-cp.GetMsgChan()).GetSequenceNum())
+}
+
+func TestCollectingProcess_RecordElementOffsets(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+
+		recordElementOffsets: true,
+	}
+	_, err = cp.decodePacket(bytes.NewBuffer(validTemplatePacket), address.String())
+	require.NoError(t, err)
+	message, err := cp.decodePacket(bytes.NewBuffer(validDataPacket), address.String())
+	require.NoError(t, err)
+	elements := message.GetSet().GetRecords()[0].GetOrderedElementList()
+	expectedOffsets := [][2]int{{0, 4}, {4, 8}, {8, 13}}
+	require.Len(t, elements, len(expectedOffsets))
+	for i, element := range elements {
+		start, end, exist := element.GetByteOffsets()
+		assert.True(t, exist)
+		assert.Equal(t, expectedOffsets[i], [2]int{start, end}, "Unexpected offsets for element %s", element.GetName())
+	}
+}
+
+func TestCollectingProcess_DecodeLargeOctetArray(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+	}
+	// Template 256 with a variable-length applicationId (octetArray).
+	templatePacket := []byte{0, 10, 0, 28, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 12, 1, 0, 0, 1, 0, 95, 255, 255}
+	value := make([]byte, 500)
+	for i := range value {
+		value[i] = byte(i)
+	}
+	// The 500-byte value is preceded by the 3-byte length prefix (255, 0x01f4).
+	dataPacket := []byte{0, 10, 0, 0, 95, 154, 108, 18, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 0, 255, 1, 244}
+	dataPacket = append(dataPacket, value...)
+	binary.BigEndian.PutUint16(dataPacket[2:4], uint16(len(dataPacket)))
+	binary.BigEndian.PutUint16(dataPacket[18:20], uint16(len(dataPacket)-entities.MsgHeaderLength))
+
+	_, err = cp.decodePacket(bytes.NewBuffer(templatePacket), address.String())
+	require.NoError(t, err)
+	message, err := cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+	require.NoError(t, err)
+	require.Len(t, message.GetSet().GetRecords(), 1)
+	element, _, exist := message.GetSet().GetRecords()[0].GetInfoElementWithValue("applicationId")
+	require.True(t, exist)
+	assert.Equal(t, value, element.GetOctetArrayValue())
+	assert.Equal(t, len(value)+3, element.GetLength())
+}
+
+func TestUDPCollectingProcess_ReceiveTime(t *testing.T) {
+	input := getCollectorInput(udpTransport, false, false)
+	cp, err := InitCollectingProcess(input)
+	require.NoError(t, err)
+	go cp.Start()
+	// wait until collector is ready
+	waitForCollectorReady(t, cp)
+	collectorAddr := cp.GetAddress()
+	conn, err := net.Dial(collectorAddr.Network(), collectorAddr.String())
+	require.NoError(t, err)
+	defer conn.Close()
+	sendTime := time.Now()
+	_, err = conn.Write(validTemplatePacket)
+	require.NoError(t, err)
+	message := <-cp.GetMsgChan()
+	cp.Stop()
+	assert.False(t, message.GetReceiveTime().Before(sendTime))
+	assert.WithinDuration(t, time.Now(), message.GetReceiveTime(), time.Second)
+}
+
+func TestCollectingProcess_GetTemplateChan(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap:    make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:      address,
+		messageChan:     make(chan *entities.Message, 4),
+		messageChanSize: 4,
+	}
+	templateChan := cp.GetTemplateChan(1, 257)
+	assert.Equal(t, templateChan, cp.GetTemplateChan(1, 257))
+	// Same elements as validTemplatePacket and validDataPacket, with template 257.
+	templatePacket := make([]byte, len(validTemplatePacket))
+	copy(templatePacket, validTemplatePacket)
+	templatePacket[21] = 1
+	dataPacket := make([]byte, len(validDataPacket))
+	copy(dataPacket, validDataPacket)
+	dataPacket[17] = 1
+	for _, packet := range [][]byte{validTemplatePacket, templatePacket, validDataPacket, dataPacket} {
+		_, err = cp.decodePacket(bytes.NewBuffer(packet), address.String())
+		require.NoError(t, err)
+	}
+	require.Len(t, templateChan, 1)
+	message := <-templateChan
+	assert.Equal(t, uint16(257), message.GetSet().GetRecords()[0].GetTemplateID())
+	// Templates and data of other templates are delivered to the message channel.
+	require.Len(t, cp.GetMsgChan(), 3)
+	for i := 0; i < 3; i++ {
+		message = <-cp.GetMsgChan()
+		if message.GetSet().GetSetType() == entities.Data {
+			assert.Equal(t, uint16(256), message.GetSet().GetRecords()[0].GetTemplateID())
+		}
+	}
+}
+
+func TestCollectingProcess_MaxObservationDomains(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap:  make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:    address,
+		messageChan:   make(chan *entities.Message, 8),
+		protocol:      tcpTransport,
+		maxObsDomains: 2,
+	}
+	withObsDomainID := func(packet []byte, obsDomainID byte) []byte {
+		p := make([]byte, len(packet))
+		copy(p, packet)
+		p[15] = obsDomainID
+		return p
+	}
+	for _, packet := range [][]byte{
+		withObsDomainID(validTemplatePacket, 1),
+		withObsDomainID(validTemplatePacket, 2),
+		// domain 1 is used again, so that domain 2 is the least recently used
+		withObsDomainID(validDataPacket, 1),
+		withObsDomainID(validTemplatePacket, 3),
+	} {
+		_, err = cp.decodePacket(bytes.NewBuffer(packet), address.String())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int64(1), cp.GetNumObsDomainsEvicted())
+	_, err = cp.getTemplate("", 2, 256)
+	assert.Error(t, err, "Templates of the least recently used domain should be evicted")
+	_, err = cp.decodePacket(bytes.NewBuffer(withObsDomainID(validDataPacket, 2)), address.String())
+	assert.Error(t, err)
+	for _, obsDomainID := range []byte{1, 3} {
+		_, err = cp.decodePacket(bytes.NewBuffer(withObsDomainID(validDataPacket, obsDomainID)), address.String())
+		assert.NoError(t, err)
+	}
+}
+
+func TestCollectingProcess_DecodeCustomRegistryElement(t *testing.T) {
+	customEnterpriseID := uint32(9999)
+	require.NoError(t, registry.InitNewRegistry(customEnterpriseID))
+	require.NoError(t, registry.PutInfoElement(*entities.NewInfoElement("httpRequestHost", 460, entities.String, customEnterpriseID, entities.VariableLength), customEnterpriseID))
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+	}
+	// Template 256 with the variable-length httpRequestHost element of enterprise 9999.
+	templatePacket := []byte{0, 10, 0, 32, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 16, 1, 0, 0, 1, 0x81, 0xcc, 255, 255, 0, 0, 0x27, 0x0f}
+	dataPacket := []byte{0, 10, 0, 32, 95, 154, 108, 18, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 16, 11}
+	dataPacket = append(dataPacket, []byte("example.com")...)
+	_, err = cp.decodePacket(bytes.NewBuffer(templatePacket), address.String())
+	require.NoError(t, err)
+	message, err := cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+	require.NoError(t, err)
+	record := message.GetSet().GetRecords()[0]
+	element, _, exist := record.GetInfoElementWithValue("httpRequestHost")
+	require.True(t, exist)
+	assert.Equal(t, customEnterpriseID, element.GetInfoElement().EnterpriseId)
+	assert.Equal(t, "example.com", element.GetStringValue())
+	assert.Equal(t, map[string]interface{}{"httpRequestHost": "example.com"}, record.GetElementMap())
+}
+
+func TestCollectingProcess_DecodeCertificateFingerprint(t *testing.T) {
+	customEnterpriseID := uint32(9998)
+	require.NoError(t, registry.InitNewRegistry(customEnterpriseID))
+	require.NoError(t, registry.PutInfoElement(*entities.NewInfoElement("exporterCertificateFingerprint", 1, entities.OctetArray, customEnterpriseID, entities.VariableLength), customEnterpriseID))
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+	}
+	// Template 256 with exporterCertificateFingerprint of enterprise 9998 with a fixed length of 32 bytes.
+	templatePacket := []byte{0, 10, 0, 32, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 16, 1, 0, 0, 1, 0x80, 0x01, 0, 32, 0, 0, 0x27, 0x0e}
+	fingerprint := make([]byte, 32)
+	for i := range fingerprint {
+		fingerprint[i] = byte(0xe0 + i)
+	}
+	dataPacket := append([]byte{0, 10, 0, 52, 95, 154, 108, 18, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 36}, fingerprint...)
+	_, err = cp.decodePacket(bytes.NewBuffer(templatePacket), address.String())
+	require.NoError(t, err)
+	message, err := cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+	require.NoError(t, err)
+	element, _, exist := message.GetSet().GetRecords()[0].GetInfoElementWithValue("exporterCertificateFingerprint")
+	require.True(t, exist)
+	assert.Equal(t, entities.OctetArray, element.GetDataType())
+	assert.Equal(t, fingerprint, element.GetOctetArrayValue())
+}
+
+func TestCollectingProcess_GetDecodeErrorsByTemplate(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+	}
+	_, err = cp.decodePacket(bytes.NewBuffer(validTemplatePacket), address.String())
+	require.NoError(t, err)
+	// Data set for template 256 truncated in the middle of destinationIPv4Address.
+	malformedDataPacket := []byte{0, 10, 0, 26, 95, 154, 108, 18, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 10, 1, 2, 3, 4, 5, 6}
+	for i := 0; i < 2; i++ {
+		_, err = cp.decodePacket(bytes.NewBuffer(malformedDataPacket), address.String())
+		assert.Error(t, err)
+	}
+	// Data set for an unknown template is not attributed to any template.
+	unknownTemplatePacket := make([]byte, len(validDataPacket))
+	copy(unknownTemplatePacket, validDataPacket)
+	unknownTemplatePacket[17] = 9
+	_, err = cp.decodePacket(bytes.NewBuffer(unknownTemplatePacket), address.String())
+	assert.Error(t, err)
+	_, err = cp.decodePacket(bytes.NewBuffer(validDataPacket), address.String())
+	require.NoError(t, err)
+	assert.Equal(t, map[uint32]map[uint16]int64{1: {256: 2}}, cp.GetDecodeErrorsByTemplate())
+}
+
+type fakeStatsExporter struct {
+	sets []entities.Set
+}
+
+func (e *fakeStatsExporter) SendSet(set entities.Set) (int, error) {
+	e.sets = append(e.sets, set)
+	return set.GetSetLength(), nil
+}
+
+func (e *fakeStatsExporter) NewTemplateID() uint16 {
+	return 300
+}
+
+func TestCollectingProcess_ExportStats(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	statsExporter := &fakeStatsExporter{}
+	cp := CollectingProcess{
+		templatesMap:      make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:        address,
+		messageChan:       make(chan *entities.Message),
+		deliveryMode:      DeliveryModeDrop,
+		statsExportConfig: &StatsExportConfig{Exporter: statsExporter},
+	}
+	_, err = cp.decodePacket(bytes.NewBuffer(validTemplatePacket), address.String())
+	require.NoError(t, err)
+	// Nobody reads messageChan, so the data record is dropped.
+	_, err = cp.decodePacket(bytes.NewBuffer(validDataPacket), address.String())
+	require.NoError(t, err)
+	require.NoError(t, cp.ExportStats())
+	require.Len(t, statsExporter.sets, 2)
+
+	// Decode the emitted statistics with another collecting process.
+	upstream := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+	}
+	for i, set := range statsExporter.sets {
+		msg, err := exporter.CreateIPFIXMsg(set, 1, uint32(i), time.Now())
+		require.NoError(t, err)
+		_, err = upstream.decodePacket(bytes.NewBuffer(msg), address.String())
+		require.NoError(t, err)
+	}
+	<-upstream.messageChan
+	message := <-upstream.messageChan
+	require.Equal(t, entities.Data, message.GetSet().GetSetType())
+	record := message.GetSet().GetRecords()[0]
+	assert.Equal(t, uint16(300), record.GetTemplateID())
+	ie, _, exist := record.GetInfoElementWithValue("collectorIPv4Address")
+	require.True(t, exist)
+	assert.Equal(t, net.ParseIP("127.0.0.1").To4(), ie.GetIPAddressValue())
+	ie, _, exist = record.GetInfoElementWithValue("collectorTransportPort")
+	require.True(t, exist)
+	assert.Equal(t, uint16(address.Port), ie.GetUnsigned16Value())
+	ie, _, exist = record.GetInfoElementWithValue("ignoredDataRecordTotalCount")
+	require.True(t, exist)
+	assert.Equal(t, uint64(1), ie.GetUnsigned64Value())
+}
+
+func TestCollectingProcess_DecodeTLSServerName(t *testing.T) {
+	// tlsServerName is not part of the Antrea registry, and is decoded through
+	// a custom registry.
+	customEnterpriseID := uint32(9996)
+	require.NoError(t, registry.InitNewRegistry(customEnterpriseID))
+	require.NoError(t, registry.PutInfoElement(*entities.NewInfoElement("tlsServerName", 157, entities.String, customEnterpriseID, entities.VariableLength), customEnterpriseID))
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+	}
+	// Template 256 with sourceIPv4Address and tlsServerName (id 157, enterprise 9996).
+	templatePacket := []byte{0, 10, 0, 36, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 20, 1, 0, 0, 2, 0, 8, 0, 4, 0x80, 0x9d, 0xff, 0xff, 0, 0, 0x27, 0x0c}
+	dataPacket := append([]byte{0, 10, 0, 36, 95, 154, 108, 18, 0, 0, 0, 1, 0, 0, 0, 1, 1, 0, 0, 20, 1, 2, 3, 4, 11}, []byte("example.com")...)
+	_, err = cp.decodePacket(bytes.NewBuffer(templatePacket), address.String())
+	require.NoError(t, err)
+	message, err := cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+	require.NoError(t, err)
+	record := message.GetSet().GetRecords()[0]
+	ie, _, exist := record.GetInfoElementWithValue("tlsServerName")
+	require.True(t, exist)
+	assert.Equal(t, entities.String, ie.GetDataType())
+	assert.Equal(t, customEnterpriseID, ie.GetInfoElement().EnterpriseId)
+	assert.Equal(t, "example.com", ie.GetStringValue())
+	assert.Equal(t, "example.com", record.GetElementMap()["tlsServerName"])
+	// The CEF key is mapped by element name, regardless of the enterprise.
+	assert.Equal(t, "CEF:0||||||0|src=1.2.3.4 dhost=example.com", record.ToCEF(entities.CEFOptions{}))
+}
+
+type fakeTemplateStore struct {
+	memoryTemplateStore
+	gets, puts, deletes int
+}
+
+func (s *fakeTemplateStore) Get(obsDomainID uint32, templateID uint16) ([]*entities.InfoElement, bool) {
+	s.gets++
+	return s.memoryTemplateStore.Get(obsDomainID, templateID)
+}
+
+func (s *fakeTemplateStore) Put(obsDomainID uint32, templateID uint16, elements []*entities.InfoElement) {
+	s.puts++
+	s.memoryTemplateStore.Put(obsDomainID, templateID, elements)
+}
+
+func (s *fakeTemplateStore) Delete(obsDomainID uint32, templateID uint16) {
+	s.deletes++
+	s.memoryTemplateStore.Delete(obsDomainID, templateID)
+}
+
+func TestCollectingProcess_TemplateStore(t *testing.T) {
+	store := &fakeTemplateStore{memoryTemplateStore: make(memoryTemplateStore)}
+	input := getCollectorInput(tcpTransport, false, false)
+	input.TemplateStore = store
+	cp, err := InitCollectingProcess(input)
+	require.NoError(t, err)
+	cp.netAddress, err = net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp.messageChan = make(chan *entities.Message, 2)
+
+	_, err = cp.decodePacket(bytes.NewBuffer(validTemplatePacket), cp.netAddress.String())
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.puts)
+	assert.Empty(t, cp.templatesMap, "Default store should not be used")
+	elements, exist := store.memoryTemplateStore.Get(1, 256)
+	require.True(t, exist)
+	assert.Len(t, elements, 3)
+
+	// Another collecting process sharing the store decodes the data records.
+	other, err := InitCollectingProcess(input)
+	require.NoError(t, err)
+	other.messageChan = make(chan *entities.Message, 1)
+	message, err := other.decodePacket(bytes.NewBuffer(validDataPacket), cp.netAddress.String())
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.gets)
+	assert.Equal(t, uint32(1), message.GetSet().GetNumberOfRecords())
+
+	state, err := other.ExportTemplateState()
+	require.NoError(t, err)
+	assert.Contains(t, string(state), `"templateID":256`)
+
+	other.deleteTemplate("", 1, 256)
+	assert.Equal(t, 1, store.deletes)
+	_, exist = store.memoryTemplateStore.Get(1, 256)
+	assert.False(t, exist)
+}
+
+func TestDecodeBase64(t *testing.T) {
+	templates := NewMemoryTemplateStore()
+	_, err := DecodeBase64(base64.StdEncoding.EncodeToString(validDataPacket), templates)
+	assert.Error(t, err, "Data should not be decoded before the template")
+	message, err := DecodeBase64(base64.StdEncoding.EncodeToString(validTemplatePacket), templates)
+	require.NoError(t, err)
+	assert.Equal(t, entities.Template, message.GetSet().GetSetType())
+
+	message, err = DecodeBase64(base64.StdEncoding.EncodeToString(validDataPacket), templates)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), message.GetObsDomainID())
+	assert.Equal(t, "", message.GetExportAddress())
+	record := message.GetSet().GetRecords()[0]
+	ie, _, exist := record.GetInfoElementWithValue("sourceIPv4Address")
+	require.True(t, exist)
+	assert.Equal(t, net.ParseIP("1.2.3.4").To4(), ie.GetIPAddressValue())
+	ie, _, exist = record.GetInfoElementWithValue("sourcePodName")
+	require.True(t, exist)
+	assert.Equal(t, "pod1", ie.GetStringValue())
+
+	_, err = DecodeBase64("not base64!", templates)
+	assert.Error(t, err)
+}
+
+func TestCollectingProcess_SetUndecodableHandler(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 1),
+	}
+	var handlerAddr string
+	var handlerData []byte
+	cp.SetUndecodableHandler(func(addr string, data []byte) {
+		handlerAddr = addr
+		handlerData = data
+	})
+	invalidVersionPacket := make([]byte, len(validTemplatePacket))
+	copy(invalidVersionPacket, validTemplatePacket)
+	invalidVersionPacket[1] = 9
+	_, err = cp.decodePacket(bytes.NewBuffer(invalidVersionPacket), "10.0.0.1:4739")
+	assert.Error(t, err)
+	assert.Equal(t, "10.0.0.1:4739", handlerAddr)
+	assert.Equal(t, invalidVersionPacket, handlerData)
+
+	handlerData = nil
+	_, err = cp.decodePacket(bytes.NewBuffer(validTemplatePacket), "10.0.0.1:4739")
+	require.NoError(t, err)
+	assert.Nil(t, handlerData, "Handler should not be called for valid messages")
+}
+
+func TestCollectingProcess_SetDataSetIDOverride(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+	}
+	_, err = cp.decodePacket(bytes.NewBuffer(validTemplatePacket), address.String())
+	require.NoError(t, err)
+	// Data set for template 256 sent with the reserved set ID 5.
+	dataPacket := make([]byte, len(validDataPacket))
+	copy(dataPacket, validDataPacket)
+	dataPacket[16], dataPacket[17] = 0, 5
+	_, err = cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+	assert.Error(t, err, "Set ID 5 should not be decoded without override")
+
+	cp.SetDataSetIDOverride(map[uint16]uint16{5: 256})
+	message, err := cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+	require.NoError(t, err)
+	assert.Equal(t, entities.Data, message.GetSet().GetSetType())
+	record := message.GetSet().GetRecords()[0]
+	assert.Equal(t, uint16(256), record.GetTemplateID())
+	ie, _, exist := record.GetInfoElementWithValue("sourcePodName")
+	require.True(t, exist)
+	assert.Equal(t, "pod1", ie.GetStringValue())
+}
+
+func TestCollectingProcess_MaxBufferedMessages(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap:        make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:          address,
+		messageChan:         make(chan *entities.Message, 4),
+		messageChanSize:     4,
+		maxBufferedMessages: 3,
+	}
+	templateChan := cp.GetTemplateChan(1, 256)
+	_, err = cp.decodePacket(bytes.NewBuffer(validTemplatePacket), address.String())
+	require.NoError(t, err)
+	for i := 1; i <= 4; i++ {
+		dataPacket := make([]byte, len(validDataPacket))
+		copy(dataPacket, validDataPacket)
+		dataPacket[11] = byte(i)
+		_, err = cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+		require.NoError(t, err)
+	}
+	// The two oldest data messages are dropped to keep 3 buffered messages.
+	assert.Equal(t, int64(2), cp.GetNumBufferedMessagesDropped())
+	assert.Len(t, cp.GetMsgChan(), 1)
+	require.Len(t, templateChan, 2)
+	assert.Equal(t, uint32(3), (<-templateChan).GetSequenceNum())
+	assert.Equal(t, uint32(4), (<-templateChan).GetSequenceNum())
+}
+
+func TestCollectingProcess_DeduplicateTemplates(t *testing.T) {
+	for _, deduplicate := range []bool{true, false} {
+		address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+		require.NoError(t, err)
+		cp := CollectingProcess{
+			templatesMap:         make(map[uint32]map[uint16][]*entities.InfoElement),
+			netAddress:           address,
+			messageChan:          make(chan *entities.Message, 4),
+			protocol:             tcpTransport,
+			deduplicateTemplates: deduplicate,
+		}
+		numAdded := 0
+		cp.SetTemplateAddedHandler(func(obsDomainID uint32, templateID uint16, elements []*entities.InfoElement) {
+			assert.Equal(t, uint32(1), obsDomainID)
+			assert.Equal(t, uint16(256), templateID)
+			numAdded++
+		})
+		// The exporter resends the identical template after reconnecting.
+		for i := 0; i < 2; i++ {
+			_, err = cp.decodePacket(bytes.NewBuffer(validTemplatePacket), address.String())
+			require.NoError(t, err)
+		}
+		if deduplicate {
+			assert.Equal(t, 1, numAdded)
+		} else {
+			assert.Equal(t, 2, numAdded)
+		}
+		// A different template with the same ID is always reported.
+		templatePacket := make([]byte, len(validTemplatePacket))
+		copy(templatePacket, validTemplatePacket)
+		templatePacket[25] = 10 // ingressInterface instead of sourceIPv4Address
+		_, err = cp.decodePacket(bytes.NewBuffer(templatePacket), address.String())
+		require.NoError(t, err)
+		if deduplicate {
+			assert.Equal(t, 2, numAdded)
+		} else {
+			assert.Equal(t, 3, numAdded)
+		}
+	}
+}
+
+func TestCollectingProcess_RegisterElementsFromOptions(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap:                make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:                  address,
+		messageChan:                 make(chan *entities.Message, 3),
+		registerElementsFromOptions: true,
+	}
+	// Options template 256 with scope informationElementId and privateEnterpriseNumber,
+	// followed by informationElementIndex, informationElementDataType and informationElementName.
+	optionsTemplatePacket := []byte{0, 10, 0, 46, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 3, 0, 30, 1, 0, 0, 5, 0, 2, 1, 47, 0, 2, 1, 90, 0, 4, 1, 31, 0, 2, 1, 83, 0, 1, 1, 85, 255, 255}
+	// elementB with index 1 is received before elementA with index 0.
+	optionsDataPacket := []byte{0, 10, 0, 56, 95, 154, 108, 18, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 40,
+		0, 1, 0, 0, 39, 13, 0, 1, 3, 8, 'e', 'l', 'e', 'm', 'e', 'n', 't', 'B',
+		0, 2, 0, 0, 39, 13, 0, 0, 2, 8, 'e', 'l', 'e', 'm', 'e', 'n', 't', 'A'}
+	_, err = cp.decodePacket(bytes.NewBuffer(optionsTemplatePacket), address.String())
+	require.NoError(t, err)
+	_, err = cp.decodePacket(bytes.NewBuffer(optionsDataPacket), address.String())
+	require.NoError(t, err)
+
+	elements := cp.GetElementDefinitions(1)
+	require.Len(t, elements, 2)
+	assert.Equal(t, entities.NewInfoElement("elementA", 2, entities.Unsigned16, 9997, 2), elements[0])
+	assert.Equal(t, entities.NewInfoElement("elementB", 1, entities.Unsigned32, 9997, 4), elements[1])
+	assert.Empty(t, cp.GetElementDefinitions(2))
+	// The elements are not added to the registry.
+	_, err = registry.GetInfoElementFromID(2, 9997)
+	assert.Error(t, err)
+
+	// Template 257 with elementA can be decoded in observation domain 1 only.
+	templatePacket := []byte{0, 10, 0, 32, 95, 154, 108, 18, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 16, 1, 1, 0, 1, 128, 2, 0, 2, 0, 0, 39, 13}
+	message, err := cp.decodePacket(bytes.NewBuffer(templatePacket), address.String())
+	require.NoError(t, err)
+	ie, _, exist := message.GetSet().GetRecords()[0].GetInfoElementWithValue("elementA")
+	require.True(t, exist)
+	assert.Equal(t, uint32(9997), ie.GetInfoElement().EnterpriseId)
+	templatePacket[15] = 2
+	_, err = cp.decodePacket(bytes.NewBuffer(templatePacket), address.String())
+	assert.Error(t, err)
+}
+
+func TestCollectingProcess_NormalizeNumericTypes(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap:          make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:            address,
+		messageChan:           make(chan *entities.Message, 2),
+		normalizeNumericTypes: true,
+	}
+	// Template 256 with sourceTransportPort (unsigned16) and ipClassOfService (unsigned8).
+	templatePacket := []byte{0, 10, 0, 32, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 16, 1, 0, 0, 2, 0, 7, 0, 2, 0, 5, 0, 1}
+	dataPacket := []byte{0, 10, 0, 23, 95, 154, 108, 18, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 7, 0x12, 0x34, 8}
+	_, err = cp.decodePacket(bytes.NewBuffer(templatePacket), address.String())
+	require.NoError(t, err)
+	message, err := cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+	require.NoError(t, err)
+	record := message.GetSet().GetRecords()[0]
+	ie, _, exist := record.GetInfoElementWithValue("sourceTransportPort")
+	require.True(t, exist)
+	assert.Equal(t, entities.Unsigned64, ie.GetDataType())
+	assert.Equal(t, uint64(0x1234), ie.GetUnsigned64Value())
+	assert.Equal(t, uint64(0x1234), record.GetElementMap()["sourceTransportPort"])
+	ie, _, exist = record.GetInfoElementWithValue("ipClassOfService")
+	require.True(t, exist)
+	assert.Equal(t, uint64(8), ie.GetUnsigned64Value())
+}
+
+func TestCollectingProcess_Records(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+		stopChan:     make(chan struct{}),
+	}
+	_, err = cp.decodePacket(bytes.NewBuffer(validTemplatePacket), address.String())
+	require.NoError(t, err)
+	_, err = cp.decodePacket(bytes.NewBuffer(validDataPacket), address.String())
+	require.NoError(t, err)
+
+	records := cp.Records()
+	assert.Equal(t, records, cp.Records())
+	record := <-records
+	assert.Equal(t, "127.0.0.1", record.ExportAddress)
+	assert.Equal(t, uint32(1), record.ObsDomainID)
+	assert.Equal(t, uint16(256), record.TemplateID)
+	require.Len(t, record.Elements, 3)
+	assert.Equal(t, net.IP{1, 2, 3, 4}, record.Elements["sourceIPv4Address"].GetIPAddressValue())
+	assert.Equal(t, "pod1", record.Elements["sourcePodName"].GetStringValue())
+
+	close(cp.stopChan)
+	select {
+	case _, ok := <-records:
+		assert.False(t, ok, "Records channel should be closed when the collecting process is stopped")
+	case <-time.After(time.Second):
+		t.Fatal("Records channel was not closed")
+	}
+}
+
+func TestCollectingProcess_DecodeDNSNames(t *testing.T) {
+	// dnsName and dnsQueryName are not part of the Antrea registry, and are
+	// decoded through a custom registry.
+	customEnterpriseID := uint32(9995)
+	require.NoError(t, registry.InitNewRegistry(customEnterpriseID))
+	require.NoError(t, registry.PutInfoElement(*entities.NewInfoElement("dnsName", 158, entities.String, customEnterpriseID, entities.VariableLength), customEnterpriseID))
+	require.NoError(t, registry.PutInfoElement(*entities.NewInfoElement("dnsQueryName", 159, entities.String, customEnterpriseID, entities.VariableLength), customEnterpriseID))
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+	}
+	// Template 256 with sourceIPv4Address, dnsName (id 158) and dnsQueryName (id 159), enterprise 9995.
+	templatePacket := []byte{0, 10, 0, 40, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 24, 1, 0, 0, 3, 0, 8, 0, 4, 0x80, 0x9e, 0xff, 0xff, 0, 0, 0x27, 0x0b, 0x80, 0x9f, 0xff, 0xff, 0, 0, 0x27, 0x0b}
+	_, err = cp.decodePacket(bytes.NewBuffer(templatePacket), address.String())
+	require.NoError(t, err)
+
+	// The data record is encoded by the exporter.
+	srcIPElement, err := registry.GetInfoElement("sourceIPv4Address", registry.IANAEnterpriseID)
+	require.NoError(t, err)
+	dnsNameElement, err := registry.GetInfoElement("dnsName", customEnterpriseID)
+	require.NoError(t, err)
+	dnsQueryNameElement, err := registry.GetInfoElement("dnsQueryName", customEnterpriseID)
+	require.NoError(t, err)
+	elements := []entities.InfoElementWithValue{
+		entities.NewIPAddressInfoElement(srcIPElement, net.ParseIP("1.2.3.4").To4()),
+		entities.NewStringInfoElement(dnsNameElement, "client.example.com"),
+		entities.NewStringInfoElement(dnsQueryNameElement, "www.example.com"),
+	}
+	dataSet := entities.NewSet(false)
+	require.NoError(t, dataSet.PrepareSet(entities.Data, 256))
+	require.NoError(t, dataSet.AddRecord(elements, 256))
+	dataSet.UpdateLenInHeader()
+	dataPacket, err := exporter.CreateIPFIXMsg(dataSet, 1, 0, time.Unix(1603955730, 0))
+	require.NoError(t, err)
+
+	message, err := cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+	require.NoError(t, err)
+	record := message.GetSet().GetRecords()[0]
+	ie, _, exist := record.GetInfoElementWithValue("dnsQueryName")
+	require.True(t, exist)
+	assert.Equal(t, entities.String, ie.GetDataType())
+	assert.Equal(t, "www.example.com", ie.GetStringValue())
+	ie, _, exist = record.GetInfoElementWithValue("dnsName")
+	require.True(t, exist)
+	assert.Equal(t, "client.example.com", ie.GetStringValue())
+	assert.Equal(t, "www.example.com", record.GetElementMap()["dnsQueryName"])
+	// The CEF keys are mapped by element name, regardless of the enterprise.
+	assert.Equal(t, "CEF:0||||||0|src=1.2.3.4 sourceDnsDomain=client.example.com destinationDnsDomain=www.example.com", record.ToCEF(entities.CEFOptions{}))
+}
+
+func TestCollectingProcess_StrictLength(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	newCollectingProcess := func(strictLength bool) *CollectingProcess {
+		return &CollectingProcess{
+			templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+			netAddress:   address,
+			messageChan:  make(chan *entities.Message, 2),
+			strictLength: strictLength,
+		}
+	}
+	// Trailing bytes beyond the message length are decoded as part of the
+	// template set by default.
+	templatePacket := append(append([]byte(nil), validTemplatePacket...), 0, 0, 0, 0)
+	cp := newCollectingProcess(false)
+	_, err = cp.decodePacket(bytes.NewBuffer(templatePacket), address.String())
+	require.NoError(t, err)
+
+	cp = newCollectingProcess(true)
+	_, err = cp.decodePacket(bytes.NewBuffer(templatePacket), address.String())
+	assert.ErrorContains(t, err, "message length 40 does not match the 44 bytes received")
+	_, err = cp.getTemplate("", 1, 256)
+	assert.Error(t, err)
+	_, err = cp.decodePacket(bytes.NewBuffer(validTemplatePacket), address.String())
+	require.NoError(t, err)
+	dataPacket := append(append([]byte(nil), validDataPacket...), 0)
+	_, err = cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+	assert.ErrorContains(t, err, "message length 33 does not match the 34 bytes received")
+	_, err = cp.decodePacket(bytes.NewBuffer(validDataPacket), address.String())
+	require.NoError(t, err)
+	// The set length must match the message length.
+	dataPacket = append(append([]byte(nil), validDataPacket...), 0)
+	binary.BigEndian.PutUint16(dataPacket[2:4], uint16(len(dataPacket)))
+	_, err = cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+	assert.ErrorContains(t, err, "set length 17 does not match the message length 34")
+}
+
+func TestCollectingProcess_DecodeBasicList(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+	}
+	// Template 256 with basicList (id 291).
+	templatePacket := []byte{0, 10, 0, 28, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 12, 1, 0, 0, 1, 1, 35, 255, 255}
+	// A basicList with allOf semantic of destinationTransportPort 80 and 443.
+	dataPacket := []byte{0, 10, 0, 30, 95, 154, 108, 18, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 14, 9, 3, 0, 11, 0, 2, 0, 80, 1, 187}
+	_, err = cp.decodePacket(bytes.NewBuffer(templatePacket), address.String())
+	require.NoError(t, err)
+	message, err := cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+	require.NoError(t, err)
+	record := message.GetSet().GetRecords()[0]
+	ie, _, exist := record.GetInfoElementWithValue("basicList")
+	require.True(t, exist)
+	basicList := ie.GetBasicListValue()
+	require.NotNil(t, basicList)
+	assert.Equal(t, uint8(0x03), basicList.Semantic)
+	assert.Equal(t, "destinationTransportPort", basicList.Element.Name)
+	var ports []uint16
+	for _, value := range basicList.Values {
+		ports = append(ports, value.GetUnsigned16Value())
+	}
+	assert.Equal(t, []uint16{80, 443}, ports)
+	assert.Equal(t, []interface{}{uint16(80), uint16(443)}, record.GetElementMap()["basicList"])
+}
+
+func TestCollectingProcess_DecodeSubTemplateList(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 5),
+	}
+	// Template 257 with sourceTransportPort and interfaceName, used by the
+	// subTemplateList, and template 256 with subTemplateList (id 292).
+	subTemplatePacket := []byte{0, 10, 0, 32, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 16, 1, 1, 0, 2, 0, 7, 0, 2, 0, 82, 255, 255}
+	templatePacket := []byte{0, 10, 0, 28, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 12, 1, 0, 0, 1, 1, 36, 255, 255}
+	for _, packet := range [][]byte{subTemplatePacket, templatePacket} {
+		_, err = cp.decodePacket(bytes.NewBuffer(packet), address.String())
+		require.NoError(t, err)
+	}
+	// A subTemplateList with allOf semantic of 2 records: 80 and "eth0", 443 and "eth1".
+	dataPacket := []byte{0, 10, 0, 38, 95, 154, 108, 18, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 22, 17, 3, 1, 1, 0, 80, 4, 'e', 't', 'h', '0', 1, 187, 4, 'e', 't', 'h', '1'}
+	message, err := cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+	require.NoError(t, err)
+	ie, _, exist := message.GetSet().GetRecords()[0].GetInfoElementWithValue("subTemplateList")
+	require.True(t, exist)
+	subTemplateList := ie.GetSubTemplateListValue()
+	require.NotNil(t, subTemplateList)
+	assert.Equal(t, uint16(257), subTemplateList.TemplateID)
+	records := subTemplateList.GetRecords()
+	require.Len(t, records, 2)
+	for i, expected := range []struct {
+		port          uint16
+		interfaceName string
+	}{{80, "eth0"}, {443, "eth1"}} {
+		port, _, exist := records[i].GetInfoElementWithValue("sourceTransportPort")
+		require.True(t, exist)
+		assert.Equal(t, expected.port, port.GetUnsigned16Value())
+		interfaceName, _, exist := records[i].GetInfoElementWithValue("interfaceName")
+		require.True(t, exist)
+		assert.Equal(t, expected.interfaceName, interfaceName.GetStringValue())
+	}
+
+	// An empty subTemplateList.
+	dataPacket = []byte{0, 10, 0, 24, 95, 154, 108, 18, 0, 0, 0, 1, 0, 0, 0, 1, 1, 0, 0, 8, 3, 3, 1, 1}
+	message, err = cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+	require.NoError(t, err)
+	ie, _, _ = message.GetSet().GetRecords()[0].GetInfoElementWithValue("subTemplateList")
+	assert.Empty(t, ie.GetSubTemplateListValue().GetRecords())
+
+	// A subTemplateList using an unknown template.
+	dataPacket = []byte{0, 10, 0, 26, 95, 154, 108, 18, 0, 0, 0, 2, 0, 0, 0, 1, 1, 0, 0, 10, 5, 3, 1, 2, 0, 80}
+	_, err = cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+	assert.ErrorContains(t, err, "template 258 with obsDomainID 1 does not exist")
+
+	// The subTemplateList is not decoded with SkipStructuredData.
+	cp.skipStructuredData = true
+	message, err = cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+	require.NoError(t, err)
+	ie, _, exist = message.GetSet().GetRecords()[0].GetInfoElementWithValue("subTemplateList")
+	require.True(t, exist)
+	assert.Equal(t, entities.SubTemplateList, ie.GetDataType())
+	assert.Nil(t, ie.GetSubTemplateListValue())
+}
+
+// newFixedLengthDataPacket returns the elements of a fixed-length template,
+// with ingressInterface (unsigned32), sourceIPv4Address and
+// sourceTransportPort (unsigned16), and a data packet with numRecords records
+// of template 256 using these elements.
+func newFixedLengthDataPacket(t testing.TB, numRecords int) ([]*entities.InfoElement, []byte) {
+	elements := make([]*entities.InfoElement, 3)
+	for i, name := range []string{"ingressInterface", "sourceIPv4Address", "sourceTransportPort"} {
+		var err error
+		elements[i], err = registry.GetInfoElement(name, registry.IANAEnterpriseID)
+		require.NoError(t, err)
+	}
+	dataSet := entities.NewSet(false)
+	require.NoError(t, dataSet.PrepareSet(entities.Data, 256))
+	for i := 0; i < numRecords; i++ {
+		require.NoError(t, dataSet.AddRecord([]entities.InfoElementWithValue{
+			entities.NewUnsigned32InfoElement(elements[0], uint32(i)),
+			entities.NewIPAddressInfoElement(elements[1], net.IP{10, 0, byte(i >> 8), byte(i)}),
+			entities.NewUnsigned16InfoElement(elements[2], uint16(i)),
+		}, 256))
+	}
+	dataSet.UpdateLenInHeader()
+	dataPacket, err := exporter.CreateIPFIXMsg(dataSet, 1, 0, time.Now())
+	require.NoError(t, err)
+	return elements, dataPacket
+}
+
+// TestCollectingProcess_DecodeFixedLengthRecords guards the decoding of
+// templates with only fixed-length elements, which is the most common case
+// and the one worth optimizing: the decoded records must be identical to the
+// ones obtained by decoding every field on its own.
+func TestCollectingProcess_DecodeFixedLengthRecords(t *testing.T) {
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		protocol:     tcpTransport,
+	}
+	const numRecords = 300
+	elements, dataPacket := newFixedLengthDataPacket(t, numRecords)
+	cp.addTemplateElements("", 1, 256, elements, 0)
+	message, err := cp.decodeMessage(bytes.NewBuffer(dataPacket), "127.0.0.1:4739", time.Now())
+	require.NoError(t, err)
+	records := message.GetSet().GetRecords()
+	require.Len(t, records, numRecords)
+
+	// Decode the records field by field, starting after the message and set headers.
+	const recordLen = 10
+	data := dataPacket[entities.MsgHeaderLength+entities.SetHeaderLen:]
+	require.Len(t, data, numRecords*recordLen)
+	for i, record := range records {
+		expected := make([]entities.InfoElementWithValue, len(elements))
+		offset := i * recordLen
+		for j, element := range elements {
+			expected[j], err = entities.DecodeAndCreateInfoElementWithValue(element, data[offset:offset+int(element.Len)])
+			require.NoError(t, err)
+			offset += int(element.Len)
+		}
+		require.Equal(t, expected, record.GetOrderedElementList(), "record %d", i)
+		assert.Equal(t, map[string]interface{}{
+			"ingressInterface":    uint32(i),
+			"sourceIPv4Address":   net.IP{10, 0, byte(i >> 8), byte(i)},
+			"sourceTransportPort": uint16(i),
+		}, record.GetElementMap(), "record %d", i)
+	}
+}
+
+func BenchmarkDecodeFixedLengthRecords(b *testing.B) {
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		protocol:     tcpTransport,
+	}
+	for _, numRecords := range []int{1, 100} {
+		elements, dataPacket := newFixedLengthDataPacket(b, numRecords)
+		cp.addTemplateElements("", 1, 256, elements, 0)
+		b.Run(fmt.Sprintf("Records=%d", numRecords), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(dataPacket)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := cp.decodeMessage(bytes.NewBuffer(dataPacket), "127.0.0.1:4739", time.Now()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDecodeSubTemplateList(b *testing.B) {
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		protocol:     tcpTransport,
+	}
+	// Template 257 with sourceTransportPort and interfaceName, used by the
+	// subTemplateList, and template 256 with subTemplateList.
+	subElements := make([]*entities.InfoElement, 2)
+	for i, name := range []string{"sourceTransportPort", "interfaceName"} {
+		subElements[i], _ = registry.GetInfoElement(name, registry.IANAEnterpriseID)
+	}
+	stlElement, _ := registry.GetInfoElement("subTemplateList", registry.IANAEnterpriseID)
+	cp.addTemplateElements("", 1, 257, subElements, 0)
+	cp.addTemplateElements("", 1, 256, []*entities.InfoElement{stlElement}, 0)
+	records := make([][]entities.InfoElementWithValue, 1000)
+	for i := range records {
+		records[i] = []entities.InfoElementWithValue{
+			entities.NewUnsigned16InfoElement(subElements[0], uint16(i)),
+			entities.NewStringInfoElement(subElements[1], "eth0"),
+		}
+	}
+	dataSet := entities.NewSet(false)
+	require.NoError(b, dataSet.PrepareSet(entities.Data, 256))
+	require.NoError(b, dataSet.AddRecord([]entities.InfoElementWithValue{
+		entities.NewSubTemplateListInfoElement(stlElement, &entities.SubTemplateListValue{
+			Semantic:   entities.SemanticAllOf,
+			TemplateID: 257,
+			Records:    records,
+		}),
+	}, 256))
+	dataSet.UpdateLenInHeader()
+	dataPacket, err := exporter.CreateIPFIXMsg(dataSet, 1, 0, time.Now())
+	require.NoError(b, err)
+
+	for _, skipStructuredData := range []bool{false, true} {
+		b.Run(fmt.Sprintf("SkipStructuredData=%t", skipStructuredData), func(b *testing.B) {
+			cp.skipStructuredData = skipStructuredData
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := cp.decodeMessage(bytes.NewBuffer(dataPacket), "127.0.0.1:4739", time.Now()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectingProcess_RoundRobinChannels(t *testing.T) {
+	input := getCollectorInput(tcpTransport, false, false)
+	input.RoundRobinChannels = 3
+	input.MessageChanSize = 10
+	cp, err := InitCollectingProcess(input)
+	require.NoError(t, err)
+	assert.Nil(t, cp.GetRoundRobinChan(3))
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	_, err = cp.decodePacket(bytes.NewBuffer(validTemplatePacket), address.String())
+	require.NoError(t, err)
+	for i := 0; i < 29; i++ {
+		_, err = cp.decodePacket(bytes.NewBuffer(validDataPacket), address.String())
+		require.NoError(t, err)
+	}
+	for i := 0; i < 3; i++ {
+		assert.Len(t, cp.GetRoundRobinChan(i), 10)
+	}
+	assert.Empty(t, cp.GetMsgChan())
+	// The template message is delivered to the first channel.
+	assert.Equal(t, entities.Template, (<-cp.GetRoundRobinChan(0)).GetSet().GetSetType())
+	assert.Equal(t, entities.Data, (<-cp.GetRoundRobinChan(1)).GetSet().GetSetType())
+}
+
+func TestCollectingProcess_DecodeVariableLengthPrefixes(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+	}
+	// Template 256 with destinationNodeName (id 105, enterprise 56506).
+	templatePacket := []byte{0, 10, 0, 32, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 16, 1, 0, 0, 1, 0x80, 0x69, 0xff, 0xff, 0, 0, 0xdc, 0xba}
+	_, err = cp.decodePacket(bytes.NewBuffer(templatePacket), address.String())
+	require.NoError(t, err)
+	newDataPacket := func(records ...[]byte) []byte {
+		packet := []byte{0, 10, 0, 0, 95, 154, 108, 18, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 0}
+		for _, record := range records {
+			packet = append(packet, record...)
+		}
+		binary.BigEndian.PutUint16(packet[2:4], uint16(len(packet)))
+		binary.BigEndian.PutUint16(packet[18:20], uint16(len(packet)-entities.MsgHeaderLength))
+		return packet
+	}
+	longName := strings.Repeat("n", 300)
+	dataPacket := newDataPacket(
+		append([]byte{6}, "node-a"...),
+		// The 3-byte form may also be used for short values.
+		append([]byte{255, 0, 6}, "node-b"...),
+		append([]byte{255, 1, 44}, longName...),
+	)
+	message, err := cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+	require.NoError(t, err)
+	records := message.GetSet().GetRecords()
+	require.Len(t, records, 3)
+	for i, expected := range []string{"node-a", "node-b", longName} {
+		ie, _, exist := records[i].GetInfoElementWithValue("destinationNodeName")
+		require.True(t, exist)
+		assert.Equal(t, expected, ie.GetStringValue())
+	}
+
+	// The declared length exceeds the remaining set bytes.
+	_, err = cp.decodePacket(bytes.NewBuffer(newDataPacket(append([]byte{255, 1, 44}, "node-a"...))), address.String())
+	assert.ErrorContains(t, err, "insufficient data for element destinationNodeName: expected 300 bytes, got 6")
+	// The 3-byte length is truncated.
+	_, err = cp.decodePacket(bytes.NewBuffer(newDataPacket([]byte{255, 1})), address.String())
+	assert.ErrorContains(t, err, "invalid length for element destinationNodeName")
+}
+
+func TestCollectingProcess_RegisterMetrics(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		clients:      make(map[string]*clientHandler),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 3),
+	}
+	metricsRegistry := prometheus.NewRegistry()
+	require.NoError(t, cp.RegisterMetrics(metricsRegistry))
+	// The data packet is decoded before its template is received.
+	_, err = cp.decodePacket(bytes.NewBuffer(validDataPacket), address.String())
+	require.Error(t, err)
+	_, err = cp.decodePacket(bytes.NewBuffer(validTemplatePacket), address.String())
+	require.NoError(t, err)
+	_, err = cp.decodePacket(bytes.NewBuffer(validDataPacket), address.String())
+	require.NoError(t, err)
+	// A packet with an invalid version.
+	invalidPacket := append([]byte(nil), validDataPacket...)
+	invalidPacket[1] = 9
+	_, err = cp.decodePacket(bytes.NewBuffer(invalidPacket), address.String())
+	require.Error(t, err)
+	cp.addClient("127.0.0.1:30000", cp.createClient())
+
+	assert.Equal(t, float64(4), testutil.ToFloat64(cp.metrics.messagesReceived))
+	assert.Equal(t, float64(1), testutil.ToFloat64(cp.metrics.recordsDecoded))
+	assert.Equal(t, float64(2), testutil.ToFloat64(cp.metrics.decodeErrors))
+	assert.Equal(t, float64(1), testutil.ToFloat64(cp.metrics.unknownTemplateDrops))
+	assert.Equal(t, float64(cp.GetNumConnToCollector()), testutil.ToFloat64(cp.metrics.clients))
+	assert.Equal(t, float64(1), testutil.ToFloat64(cp.metrics.clients))
+	count, err := testutil.GatherAndCount(metricsRegistry)
+	require.NoError(t, err)
+	assert.Equal(t, 5, count)
+	// The metrics cannot be registered twice.
+	assert.Error(t, cp.RegisterMetrics(metricsRegistry))
+}
+
+func TestCollectingProcess_DecodeElementMetadata(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+	}
+	// Template 256 with octetDeltaCount using reduced-size encoding (4 bytes) and packetDeltaCount (8 bytes).
+	templatePacket := []byte{0, 10, 0, 32, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 16, 1, 0, 0, 2, 0, 1, 0, 4, 0, 2, 0, 8}
+	_, err = cp.decodePacket(bytes.NewBuffer(templatePacket), address.String())
+	require.NoError(t, err)
+	dataPacket := []byte{0, 10, 0, 32, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 16, 0, 0, 0x05, 0xdc, 0, 0, 0, 0, 0, 0, 0, 10}
+	message, err := cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+	require.NoError(t, err)
+
+	record := message.GetSet().GetRecords()[0]
+	ie, _, exist := record.GetInfoElementWithValue("octetDeltaCount")
+	require.True(t, exist)
+	assert.Equal(t, uint64(1500), ie.GetUnsigned64Value())
+	assert.Equal(t, "octets", ie.GetUnits())
+	assert.Equal(t, "deltaCounter", ie.GetSemantics())
+	assert.Equal(t, uint16(4), ie.GetInfoElement().Len)
+	ie, _, exist = record.GetInfoElementWithValue("packetDeltaCount")
+	require.True(t, exist)
+	assert.Equal(t, "packets", ie.GetInfoElement().Units)
+}
+
+func TestCollectingProcess_IsolateTemplatesByExporter(t *testing.T) {
+	// Template 256 with obsDomainID 1 and sourceTransportPort, which conflicts
+	// with template 256 of validTemplatePacket.
+	portTemplatePacket := []byte{0, 10, 0, 28, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 12, 1, 0, 0, 1, 0, 7, 0, 2}
+	portDataPacket := []byte{0, 10, 0, 22, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 6, 0, 80}
+	exporter1 := "10.0.0.1:4739"
+	exporter2 := "10.0.0.2:4739"
+
+	cp, err := InitCollectingProcess(CollectorInput{
+		Address:                    hostPortIPv4,
+		Protocol:                   tcpTransport,
+		MessageChanSize:            4,
+		IsolateTemplatesByExporter: true,
+	})
+	require.NoError(t, err)
+	_, err = cp.decodePacket(bytes.NewBuffer(validTemplatePacket), exporter1)
+	require.NoError(t, err)
+	_, err = cp.decodePacket(bytes.NewBuffer(portTemplatePacket), exporter2)
+	require.NoError(t, err)
+	_, err = cp.getTemplate("", 1, 256)
+	assert.Error(t, err)
+	_, err = cp.getTemplate("10.0.0.1", 1, 256)
+	assert.NoError(t, err)
+
+	message, err := cp.decodePacket(bytes.NewBuffer(validDataPacket), exporter1)
+	require.NoError(t, err)
+	ie, _, exist := message.GetSet().GetRecords()[0].GetInfoElementWithValue("sourceIPv4Address")
+	require.True(t, exist)
+	assert.Equal(t, net.IP{1, 2, 3, 4}, ie.GetIPAddressValue())
+	// The port of the exporter does not matter.
+	message, err = cp.decodePacket(bytes.NewBuffer(portDataPacket), "10.0.0.2:4740")
+	require.NoError(t, err)
+	ie, _, exist = message.GetSet().GetRecords()[0].GetInfoElementWithValue("sourceTransportPort")
+	require.True(t, exist)
+	assert.Equal(t, uint16(80), ie.GetUnsigned16Value())
+	_, err = cp.decodePacket(bytes.NewBuffer(portDataPacket), "10.0.0.3:4739")
+	assert.ErrorContains(t, err, "template 256 with obsDomainID 1 does not exist")
+
+	// The templates are exported and imported along with their exporter.
+	state, err := cp.ExportTemplateState()
+	require.NoError(t, err)
+	newCP, err := InitCollectingProcess(CollectorInput{
+		Address:                    hostPortIPv4,
+		Protocol:                   tcpTransport,
+		MessageChanSize:            4,
+		IsolateTemplatesByExporter: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, newCP.ImportTemplateState(state))
+	template, err := newCP.getTemplate("10.0.0.2", 1, 256)
+	require.NoError(t, err)
+	assert.Equal(t, "sourceTransportPort", template[0].Name)
+
+	// Without isolation, the template of the second exporter replaces the
+	// template of the first one.
+	cp, err = InitCollectingProcess(CollectorInput{
+		Address:         hostPortIPv4,
+		Protocol:        tcpTransport,
+		MessageChanSize: 4,
+	})
+	require.NoError(t, err)
+	_, err = cp.decodePacket(bytes.NewBuffer(validTemplatePacket), exporter1)
+	require.NoError(t, err)
+	_, err = cp.decodePacket(bytes.NewBuffer(portTemplatePacket), exporter2)
+	require.NoError(t, err)
+	template, err = cp.getTemplate("", 1, 256)
+	require.NoError(t, err)
+	assert.Equal(t, "sourceTransportPort", template[0].Name)
+
+	_, err = InitCollectingProcess(CollectorInput{
+		Address:                    hostPortIPv4,
+		Protocol:                   tcpTransport,
+		TemplateStore:              NewMemoryTemplateStore(),
+		IsolateTemplatesByExporter: true,
+	})
+	assert.Error(t, err)
+}
+
+func TestCollectingProcess_StopAndWait(t *testing.T) {
+	startCollector := func() (*CollectingProcess, net.Conn) {
+		input := getCollectorInput(tcpTransport, false, false)
+		input.MessageChanSize = 1
+		cp, err := InitCollectingProcess(input)
+		require.NoError(t, err)
+		go cp.Start()
+		waitForCollectorReady(t, cp)
+		conn, err := net.Dial(cp.GetAddress().Network(), cp.GetAddress().String())
+		require.NoError(t, err)
+		// The messages are written at once, so that they are buffered by the
+		// collecting process while it is blocked delivering the first one.
+		packets := append(append(append([]byte{}, validTemplatePacket...), validDataPacket...), validDataPacket...)
+		_, err = conn.Write(packets)
+		require.NoError(t, err)
+		assert.Eventually(t, func() bool {
+			return len(cp.GetMsgChan()) == 1
+		}, time.Second, 10*time.Millisecond)
+		return cp, conn
+	}
+
+	t.Run("drain", func(t *testing.T) {
+		cp, conn := startCollector()
+		defer conn.Close()
+		errCh := make(chan error)
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			errCh <- cp.StopAndWait(ctx)
+		}()
+		var setTypes []entities.ContentType
+		// The loop ends when the message channel is closed.
+		for message := range cp.GetMsgChan() {
+			setTypes = append(setTypes, message.GetSet().GetSetType())
+		}
+		assert.NoError(t, <-errCh)
+		assert.Equal(t, []entities.ContentType{entities.Template, entities.Data, entities.Data}, setTypes)
+	})
+
+	t.Run("records", func(t *testing.T) {
+		cp, conn := startCollector()
+		defer conn.Close()
+		templateChan := cp.GetTemplateChan(2, 256)
+		records := cp.Records()
+		errCh := make(chan error)
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			errCh <- cp.StopAndWait(ctx)
+		}()
+		var templateIDs []uint16
+		// The loop ends when the records channel is closed.
+		for record := range records {
+			templateIDs = append(templateIDs, record.TemplateID)
+		}
+		assert.NoError(t, <-errCh)
+		assert.Equal(t, []uint16{256, 256}, templateIDs)
+		_, ok := <-templateChan
+		assert.False(t, ok, "Template channel should be closed")
+	})
+
+	t.Run("context expired", func(t *testing.T) {
+		cp, conn := startCollector()
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, cp.StopAndWait(ctx), context.DeadlineExceeded)
+		// The channel is not closed, and the remaining messages can be read.
+		for i := 0; i < 3; i++ {
+			message, ok := <-cp.GetMsgChan()
+			require.True(t, ok)
+			require.NotNil(t, message)
+		}
+	})
+}
+
+func TestTCPCollectingProcess_ProxyProtocol(t *testing.T) {
+	input := getCollectorInput(tcpTransport, false, false)
+	input.ProxyProtocol = true
+	cp, err := InitCollectingProcess(input)
+	require.NoError(t, err)
+	go cp.Start()
+	defer cp.Stop()
+	waitForCollectorReady(t, cp)
+	collectorAddr := cp.GetAddress()
+
+	// PROXY command for TCP over IPv4 from 192.0.2.10:40000 to 198.51.100.1:4739.
+	proxyHeader := append(append([]byte{}, proxyProtocolSignature...), 0x21, 0x11, 0, 12, 192, 0, 2, 10, 198, 51, 100, 1, 0x9c, 0x40, 0x12, 0x83)
+	conn, err := net.Dial(collectorAddr.Network(), collectorAddr.String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write(append(proxyHeader, validTemplatePacket...))
+	require.NoError(t, err)
+	message := <-cp.GetMsgChan()
+	assert.Equal(t, "192.0.2.10", message.GetExportAddress())
+	assert.Equal(t, entities.Template, message.GetSet().GetSetType())
+
+	// The messages of a connection without the header are not decoded.
+	invalidConn, err := net.Dial(collectorAddr.Network(), collectorAddr.String())
+	require.NoError(t, err)
+	defer invalidConn.Close()
+	_, err = invalidConn.Write(validTemplatePacket)
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	_, err = conn.Write(validDataPacket)
+	require.NoError(t, err)
+	message = <-cp.GetMsgChan()
+	assert.Equal(t, "192.0.2.10", message.GetExportAddress())
+	assert.Equal(t, entities.Data, message.GetSet().GetSetType())
+}
+
+func TestReadProxyProtocolHeader(t *testing.T) {
+	header := func(verCmd, family byte, addresses ...byte) []byte {
+		h := append([]byte{}, proxyProtocolSignature...)
+		h = append(h, verCmd, family, 0, byte(len(addresses)))
+		return append(h, addresses...)
+	}
+	ipv6Addresses := append(append(net.ParseIP("2001:db8::1").To16(), net.ParseIP("2001:db8::2").To16()...), 0x9c, 0x40, 0x12, 0x83)
+	for _, tc := range []struct {
+		name         string
+		header       []byte
+		expectedAddr net.Addr
+		expectedErr  string
+	}{
+		{"ipv4", header(0x21, 0x11, 192, 0, 2, 10, 198, 51, 100, 1, 0x9c, 0x40, 0x12, 0x83), &net.TCPAddr{IP: net.IP{192, 0, 2, 10}, Port: 40000}, ""},
+		{"ipv6", header(0x21, 0x21, ipv6Addresses...), &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 40000}, ""},
+		{"local", header(0x20, 0x00), nil, ""},
+		{"unix", header(0x21, 0x31, make([]byte, 216)...), nil, ""},
+		{"invalid signature", append([]byte{0x0d, 0x0a, 0x0d, 0x0a, 0, 0x0d, 0x0a, 0x51, 0x55, 0x49, 0x54, 0x0b}, 0x21, 0x11, 0, 0), nil, "invalid PROXY protocol v2 signature"},
+		{"version 1", header(0x11, 0x11), nil, "unsupported PROXY protocol version 1"},
+		{"short addresses", header(0x21, 0x11, 192, 0, 2, 10), nil, "PROXY protocol address length 4 is too short"},
+		{"truncated", header(0x21, 0x11, 192, 0, 2, 10)[:18], nil, "unexpected EOF"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			addr, err := readProxyProtocolHeader(bytes.NewReader(tc.header))
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedAddr, addr)
+		})
+	}
+}
+
+func TestCollectingProcess_DecodeInvalidAddressLength(t *testing.T) {
+	address, err := net.ResolveUDPAddr("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 1),
+	}
+	// Template 256 with sourceIPv4Address declared with a length of 3.
+	pkt := []byte{0, 10, 0, 28, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 12, 1, 0, 0, 1, 0, 8, 0, 3}
+	_, err = cp.decodePacket(bytes.NewBuffer(pkt), address.String())
+	assert.ErrorContains(t, err, "invalid length 3 for address element sourceIPv4Address: expected 4 bytes")
+	_, err = cp.getTemplate("", 1, 256)
+	assert.Error(t, err, "template with an invalid address length should not be added")
+}
+
+func TestCSVSink(t *testing.T) {
+	address, err := net.ResolveUDPAddr("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 4),
+	}
+	for _, pkt := range [][]byte{validTemplatePacket, validDataPacket, validDataPacket} {
+		_, err := cp.decodePacket(bytes.NewBuffer(pkt), address.String())
+		require.NoError(t, err)
+	}
+	close(cp.messageChan)
+
+	var output bytes.Buffer
+	require.NoError(t, NewCSVSink(&output).Consume(cp.GetMsgChan()))
+	assert.Equal(t, "sourceIPv4Address,destinationIPv4Address,56506:sourcePodName\n"+
+		"1.2.3.4,5.6.7.8,pod1\n"+
+		"1.2.3.4,5.6.7.8,pod1\n", output.String())
+}
+
+func TestCollectingProcess_DecodeReducedSizeFloat64(t *testing.T) {
+	address, err := net.ResolveUDPAddr("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+	}
+	// Template 256 with samplingProbability (float64) declared with a length of 4.
+	templatePkt := []byte{0, 10, 0, 28, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 12, 1, 0, 0, 1, 1, 55, 0, 4}
+	dataPkt := []byte{0, 10, 0, 24, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 8, 0x3e, 0x80, 0, 0}
+	_, err = cp.decodePacket(bytes.NewBuffer(templatePkt), address.String())
+	require.NoError(t, err)
+	message, err := cp.decodePacket(bytes.NewBuffer(dataPkt), address.String())
+	require.NoError(t, err)
+	ie, _, exist := message.GetSet().GetRecords()[0].GetInfoElementWithValue("samplingProbability")
+	require.True(t, exist)
+	assert.Equal(t, entities.Float64, ie.GetDataType())
+	assert.Equal(t, 0.25, ie.GetFloat64Value())
+}
+
+func TestCollectingProcess_DecodePayloadLengthIPv6(t *testing.T) {
+	address, err := net.ResolveUDPAddr("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 8),
+	}
+	// Template 256 with payloadLengthIPv6 and nextHeaderIPv6, and template 257
+	// with payloadLengthIPv6 reduced to 1 byte.
+	templatePkt := []byte{0, 10, 0, 32, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 16, 1, 0, 0, 2, 0, 191, 0, 2, 0, 193, 0, 1}
+	reducedSizeTemplatePkt := []byte{0, 10, 0, 28, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 12, 1, 1, 0, 1, 0, 191, 0, 1}
+	dataPkt := []byte{0, 10, 0, 23, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 7, 0x05, 0xb4, 6}
+	reducedSizeDataPkt := []byte{0, 10, 0, 21, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 0, 5, 0xc8}
+	_, err = cp.decodePacket(bytes.NewBuffer(templatePkt), address.String())
+	require.NoError(t, err)
+	_, err = cp.decodePacket(bytes.NewBuffer(reducedSizeTemplatePkt), address.String())
+	require.NoError(t, err)
+	message, err := cp.decodePacket(bytes.NewBuffer(dataPkt), address.String())
+	require.NoError(t, err)
+	ie, _, exist := message.GetSet().GetRecords()[0].GetInfoElementWithValue("payloadLengthIPv6")
+	require.True(t, exist)
+	assert.Equal(t, entities.Unsigned16, ie.GetDataType())
+	assert.Equal(t, uint16(1460), ie.GetUnsigned16Value())
+	ie, _, exist = message.GetSet().GetRecords()[0].GetInfoElementWithValue("nextHeaderIPv6")
+	require.True(t, exist)
+	assert.Equal(t, uint8(6), ie.GetUnsigned8Value())
+
+	message, err = cp.decodePacket(bytes.NewBuffer(reducedSizeDataPkt), address.String())
+	require.NoError(t, err)
+	ie, _, exist = message.GetSet().GetRecords()[0].GetInfoElementWithValue("payloadLengthIPv6")
+	require.True(t, exist)
+	assert.Equal(t, uint16(200), ie.GetUnsigned16Value())
+
+	// The declared length cannot exceed the length of unsigned16.
+	invalidTemplatePkt := []byte{0, 10, 0, 28, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 12, 1, 2, 0, 1, 0, 191, 0, 4}
+	_, err = cp.decodePacket(bytes.NewBuffer(invalidTemplatePkt), address.String())
+	assert.ErrorContains(t, err, "invalid length 4 for element payloadLengthIPv6: exceeds its length 2")
+}
+
+func TestCollectingProcess_DecodeAddressElements(t *testing.T) {
+	address, err := net.ResolveUDPAddr("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+	}
+	// Template 256 with sourceMacAddress, sourceIPv6Address and destinationIPv4Address.
+	templatePkt := []byte{0, 10, 0, 36, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 20, 1, 0, 0, 3, 0, 56, 0, 6, 0, 27, 0, 16, 0, 12, 0, 4}
+	dataPkt := []byte{0, 10, 0, 46, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 30,
+		0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
+		0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x01,
+		10, 0, 0, 1}
+	_, err = cp.decodePacket(bytes.NewBuffer(templatePkt), address.String())
+	require.NoError(t, err)
+	message, err := cp.decodePacket(bytes.NewBuffer(dataPkt), address.String())
+	require.NoError(t, err)
+	record := message.GetSet().GetRecords()[0]
+
+	ie, _, exist := record.GetInfoElementWithValue("sourceMacAddress")
+	require.True(t, exist)
+	assert.Equal(t, entities.MacAddress, ie.GetDataType())
+	assert.Equal(t, net.HardwareAddr{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}, ie.GetMacAddressValue())
+	assert.Equal(t, "aa:bb:cc:dd:ee:ff", ie.GetMacAddressValue().String())
+
+	ie, _, exist = record.GetInfoElementWithValue("sourceIPv6Address")
+	require.True(t, exist)
+	assert.Equal(t, entities.Ipv6Address, ie.GetDataType())
+	assert.Len(t, ie.GetIPAddressValue(), net.IPv6len)
+	assert.True(t, net.ParseIP("2001:db8::1").Equal(ie.GetIPAddressValue()))
+
+	ie, _, exist = record.GetInfoElementWithValue("destinationIPv4Address")
+	require.True(t, exist)
+	assert.Len(t, ie.GetIPAddressValue(), net.IPv4len)
+	assert.Equal(t, "10.0.0.1", ie.GetIPAddressValue().String())
+}
+
+func TestCollectingProcess_CaptureTrailingBytes(t *testing.T) {
+	address, err := net.ResolveUDPAddr("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	// Template 256 with sourceTransportPort and protocolIdentifier.
+	templatePkt := []byte{0, 10, 0, 28, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 12, 1, 0, 0, 2, 0, 7, 0, 2, 0, 4, 0, 1}
+	// Two records followed by 2 bytes which are not described by the template.
+	dataPkt := []byte{0, 10, 0, 28, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 12, 0, 80, 6, 1, 187, 17, 0xde, 0xad}
+	// A single record which is shorter than the template.
+	truncatedDataPkt := []byte{0, 10, 0, 22, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 6, 0, 80}
+
+	newCollectingProcess := func(captureTrailingBytes bool) *CollectingProcess {
+		cp := &CollectingProcess{
+			templatesMap:         make(map[uint32]map[uint16][]*entities.InfoElement),
+			netAddress:           address,
+			messageChan:          make(chan *entities.Message, 2),
+			captureTrailingBytes: captureTrailingBytes,
+		}
+		_, err := cp.decodePacket(bytes.NewBuffer(templatePkt), address.String())
+		require.NoError(t, err)
+		return cp
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		cp := newCollectingProcess(false)
+		_, err := cp.decodePacket(bytes.NewBuffer(dataPkt), address.String())
+		assert.ErrorContains(t, err, "insufficient data for element protocolIdentifier")
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		cp := newCollectingProcess(true)
+		message, err := cp.decodePacket(bytes.NewBuffer(dataPkt), address.String())
+		require.NoError(t, err)
+		records := message.GetSet().GetRecords()
+		require.Len(t, records, 2)
+		_, _, exist := records[0].GetInfoElementWithValue(TrailingBytesElementName)
+		assert.False(t, exist)
+		ie, _, exist := records[1].GetInfoElementWithValue("sourceTransportPort")
+		require.True(t, exist)
+		assert.Equal(t, uint16(443), ie.GetUnsigned16Value())
+		ie, _, exist = records[1].GetInfoElementWithValue(TrailingBytesElementName)
+		require.True(t, exist)
+		assert.Equal(t, entities.OctetArray, ie.GetDataType())
+		assert.Equal(t, []byte{0xde, 0xad}, ie.GetOctetArrayValue())
+
+		_, err = cp.decodePacket(bytes.NewBuffer(truncatedDataPkt), address.String())
+		assert.ErrorContains(t, err, "insufficient data for element protocolIdentifier")
+	})
+}
+
+func TestCollectingProcess_SequenceNumberWraparound(t *testing.T) {
+	address, err := net.ResolveUDPAddr("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 8),
+	}
+	element, err := registry.GetInfoElement("sourceTransportPort", registry.IANAEnterpriseID)
+	require.NoError(t, err)
+	cp.addTemplateElements("", 1, 256, []*entities.InfoElement{element}, 0)
+	sendDataMsg := func(seqNumber uint32, numRecords int) {
+		dataSet := entities.NewSet(false)
+		require.NoError(t, dataSet.PrepareSet(entities.Data, 256))
+		for i := 0; i < numRecords; i++ {
+			require.NoError(t, dataSet.AddRecord([]entities.InfoElementWithValue{entities.NewUnsigned16InfoElement(element, uint16(i))}, 256))
+		}
+		dataSet.UpdateLenInHeader()
+		dataPacket, err := exporter.CreateIPFIXMsg(dataSet, 1, seqNumber, time.Now())
+		require.NoError(t, err)
+		_, err = cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+		require.NoError(t, err)
+	}
+
+	// The sequence number wraps around to 0 after 2^32-1.
+	sendDataMsg(math.MaxUint32-1, 3)
+	sendDataMsg(1, 1)
+	sendDataMsg(2, 2)
+	assert.Equal(t, int64(0), cp.GetNumMissingRecords())
+	// The records with sequence numbers 4 to 9 are missing.
+	sendDataMsg(10, 1)
+	assert.Equal(t, int64(6), cp.GetNumMissingRecords())
+	// A late message does not change the expected sequence number.
+	sendDataMsg(4, 2)
+	sendDataMsg(11, 1)
+	assert.Equal(t, int64(6), cp.GetNumMissingRecords())
+	assert.Len(t, cp.GetMsgChan(), 6)
+}
+
+func TestCollectingProcess_DecodeZeroLengthField(t *testing.T) {
+	address, err := net.ResolveUDPAddr("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+	}
+	// Template 256 with only octetDeltaCount declared with a length of 0.
+	templatePkt := []byte{0, 10, 0, 28, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 12, 1, 0, 0, 1, 0, 1, 0, 0}
+	dataPkt := []byte{0, 10, 0, 24, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 8, 0, 0, 0, 1}
+	_, err = cp.decodePacket(bytes.NewBuffer(templatePkt), address.String())
+	assert.ErrorContains(t, err, "invalid length 0 for element octetDeltaCount")
+	_, err = cp.decodePacket(bytes.NewBuffer(dataPkt), address.String())
+	assert.ErrorContains(t, err, "template 256 with obsDomainID 1 does not exist")
+
+	// Records which do not consume any byte are rejected instead of being
+	// decoded forever.
+	element, err := registry.GetInfoElement("octetDeltaCount", registry.IANAEnterpriseID)
+	require.NoError(t, err)
+	cp.addTemplateElements("", 1, 256, []*entities.InfoElement{withLength(element, 0)}, 0)
+	_, err = cp.decodePacket(bytes.NewBuffer(dataPkt), address.String())
+	assert.ErrorContains(t, err, "data record for template 256 does not contain any byte")
+}
+
+func TestCollectingProcess_DecodeMultipleSets(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+		strictLength: true,
+	}
+	// Message with the template set of validTemplatePacket followed by the
+	// data set of validDataPacket.
+	packet := append(append(append([]byte(nil), validTemplatePacket[:16]...), validTemplatePacket[16:]...), validDataPacket[16:]...)
+	binary.BigEndian.PutUint16(packet[2:4], uint16(len(packet)))
+	message, err := cp.decodePacket(bytes.NewBuffer(packet), address.String())
+	require.NoError(t, err)
+	assert.Equal(t, entities.Template, message.GetSet().GetSetType())
+	require.Len(t, cp.messageChan, 2)
+	<-cp.messageChan
+	message = <-cp.messageChan
+	assert.Equal(t, entities.Data, message.GetSet().GetSetType())
+	ie, _, exist := message.GetSet().GetRecords()[0].GetInfoElementWithValue("sourceIPv4Address")
+	require.True(t, exist)
+	assert.Equal(t, net.IP{1, 2, 3, 4}, ie.GetIPAddressValue())
+
+	// The sets must fill the message.
+	packet = append(packet, 0)
+	binary.BigEndian.PutUint16(packet[2:4], uint16(len(packet)))
+	_, err = cp.decodePacket(bytes.NewBuffer(packet), address.String())
+	assert.ErrorContains(t, err, "set length 17 does not match the message length 58")
+}
+
+func TestDecodeHeader(t *testing.T) {
+	packet := make([]byte, len(validDataPacket))
+	copy(packet, validDataPacket)
+	version, length, exportTime, sequence, domain, err := DecodeHeader(packet)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(10), version)
+	assert.Equal(t, uint16(33), length)
+	assert.Equal(t, uint32(1603955730), exportTime)
+	assert.Equal(t, uint32(0), sequence)
+	assert.Equal(t, uint32(1), domain)
+	// The records are not touched.
+	assert.Equal(t, validDataPacket, packet)
+
+	_, _, _, _, _, err = DecodeHeader(validDataPacket[:10])
+	assert.Error(t, err)
+	_, _, _, _, _, err = DecodeHeader(append([]byte{0, 9}, validDataPacket[2:]...))
+	assert.Error(t, err)
+}
+
 func TestCollectingProcess_DecodeDataRecord(t *testing.T) {
 	cp := CollectingProcess{}
 	cp.templatesMap = make(map[uint32]map[uint16][]*entities.InfoElement)
@@ -300,7 +2214,7 @@ func TestCollectingProcess_DecodeDataRecord(t *testing.T) {
 	_, err = cp.decodePacket(bytes.NewBuffer(validDataPacket), address.String())
 	assert.NotNil(t, err, "Error should be logged if corresponding template does not exist.")
 	// Decode with template
-	cp.addTemplate(uint32(1), uint16(256), elementsWithValueIPv4)
+	cp.addTemplate("", uint32(1), uint16(256), elementsWithValueIPv4)
 	message, err := cp.decodePacket(bytes.NewBuffer(validDataPacket), address.String())
 	assert.Nil(t, err, "Error should not be logged if corresponding template exists.")
 	assert.Equal(t, uint16(10), message.GetVersion(), "Flow record version should be 10.")
@@ -318,6 +2232,266 @@ func TestCollectingProcess_DecodeDataRecord(t *testing.T) {
 	assert.NotNil(t, err, "Error should be logged for malformed data record")
 }
 
+func TestCollectingProcess_DecodeReducedSizeBGPAsNumber(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+	}
+	// bgpSourceAsNumber is declared with 2 bytes and bgpDestinationAsNumber with 4 bytes.
+	templatePacket := []byte{0, 10, 0, 32, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 16, 1, 0, 0, 2, 0, 16, 0, 2, 0, 17, 0, 4}
+	_, err = cp.decodePacket(bytes.NewBuffer(templatePacket), address.String())
+	require.NoError(t, err)
+	dataPacket := []byte{0, 10, 0, 26, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 10, 0xfd, 0xe8, 0, 1, 0, 0}
+	message, err := cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+	require.NoError(t, err)
+	record := message.GetSet().GetRecords()[0]
+	ie, _, exist := record.GetInfoElementWithValue("bgpSourceAsNumber")
+	require.True(t, exist)
+	assert.Equal(t, uint16(2), ie.GetInfoElement().Len)
+	assert.Equal(t, uint32(65000), ie.GetUnsigned32Value())
+	ie, _, exist = record.GetInfoElementWithValue("bgpDestinationAsNumber")
+	require.True(t, exist)
+	assert.Equal(t, uint32(65536), ie.GetUnsigned32Value())
+}
+
+func TestCollectingProcess_ExportImportTemplateState(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		protocol:     tcpTransport,
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 1),
+	}
+	_, err = cp.decodePacket(bytes.NewBuffer(validTemplatePacket), address.String())
+	require.NoError(t, err)
+	state, err := cp.ExportTemplateState()
+	require.NoError(t, err)
+
+	// A new collecting process can decode data records after importing the state.
+	newCP := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		protocol:     tcpTransport,
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 1),
+	}
+	_, err = newCP.decodePacket(bytes.NewBuffer(validDataPacket), address.String())
+	require.Error(t, err)
+	require.NoError(t, newCP.ImportTemplateState(state))
+	message, err := newCP.decodePacket(bytes.NewBuffer(validDataPacket), address.String())
+	require.NoError(t, err)
+	ie, _, exist := message.GetSet().GetRecords()[0].GetInfoElementWithValue("sourcePodName")
+	require.True(t, exist)
+	assert.Equal(t, "pod1", ie.GetStringValue())
+
+	assert.Error(t, newCP.ImportTemplateState([]byte("invalid")))
+	assert.Error(t, newCP.ImportTemplateState([]byte(`{"version":2}`)))
+}
+
+func TestCollectingProcess_CreateTemplateMessages(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		protocol:     tcpTransport,
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 1),
+	}
+	_, err = cp.decodePacket(bytes.NewBuffer(validTemplatePacket), address.String())
+	require.NoError(t, err)
+	msgs, err := cp.CreateTemplateMessages(time.Unix(int64(binary.BigEndian.Uint32(validTemplatePacket[4:8])), 0))
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, validTemplatePacket, msgs[0])
+
+	// A downstream collecting process learns the template from the message.
+	newCP := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		protocol:     tcpTransport,
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+	}
+	_, err = newCP.decodePacket(bytes.NewBuffer(msgs[0]), address.String())
+	require.NoError(t, err)
+	template, err := newCP.getTemplate("", 1, 256)
+	require.NoError(t, err)
+	expectedTemplate, _ := cp.getTemplate("", 1, 256)
+	assert.Equal(t, expectedTemplate, template)
+	message, err := newCP.decodePacket(bytes.NewBuffer(validDataPacket), address.String())
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), message.GetSet().GetNumberOfRecords())
+}
+
+func TestCollectingProcess_DecodeOptionsTemplate(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		protocol:     tcpTransport,
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 3),
+	}
+	// Options template 257 with scope exportingProcessId, followed by samplingInterval.
+	optionsTemplatePacket := []byte{0, 10, 0, 34, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 3, 0, 18, 1, 1, 0, 2, 0, 1, 0, 144, 0, 4, 0, 34, 0, 4}
+	optionsDataPacket := []byte{0, 10, 0, 28, 95, 154, 108, 18, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 0, 12, 0, 0, 0, 7, 0, 0, 0, 100}
+	message, err := cp.decodePacket(bytes.NewBuffer(optionsTemplatePacket), address.String())
+	require.NoError(t, err)
+	assert.Equal(t, entities.OptionsTemplate, message.GetSet().GetSetType())
+	scopeFieldCount, isOptionsTemplate := cp.GetScopeFieldCount(1, 257)
+	assert.True(t, isOptionsTemplate)
+	assert.Equal(t, 1, scopeFieldCount)
+
+	message, err = cp.decodePacket(bytes.NewBuffer(optionsDataPacket), address.String())
+	require.NoError(t, err)
+	record := message.GetSet().GetRecords()[0]
+	ie, _, exist := record.GetInfoElementWithValue("exportingProcessId")
+	require.True(t, exist)
+	assert.Equal(t, uint32(7), ie.GetUnsigned32Value())
+	ie, _, exist = record.GetInfoElementWithValue("samplingInterval")
+	require.True(t, exist)
+	assert.Equal(t, uint32(100), ie.GetUnsigned32Value())
+
+	// The options template is re-advertised as an options template.
+	msgs, err := cp.CreateTemplateMessages(time.Unix(int64(binary.BigEndian.Uint32(optionsTemplatePacket[4:8])), 0))
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{optionsTemplatePacket}, msgs)
+
+	// A normal template is not an options template.
+	_, err = cp.decodePacket(bytes.NewBuffer(validTemplatePacket), address.String())
+	require.NoError(t, err)
+	_, isOptionsTemplate = cp.GetScopeFieldCount(1, 256)
+	assert.False(t, isOptionsTemplate)
+	cp.deleteTemplate("", 1, 257)
+	_, isOptionsTemplate = cp.GetScopeFieldCount(1, 257)
+	assert.False(t, isOptionsTemplate)
+}
+
+func TestUDPCollectingProcess_TemplateWithdrawalExpiry(t *testing.T) {
+	address, err := net.ResolveUDPAddr(udpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		protocol:     udpTransport,
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 4),
+		templateTTL:  1,
+	}
+	var numExpired atomic.Int32
+	cp.SetTemplateExpiredHandler(func(obsDomainID uint32, templateID uint16) {
+		numExpired.Add(1)
+	})
+	optionsTemplatePacket := []byte{0, 10, 0, 34, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 3, 0, 18, 1, 1, 0, 2, 0, 1, 0, 144, 0, 4, 0, 34, 0, 4}
+	// Withdrawal of template 256, and of all the options templates.
+	withdrawalPacket := []byte{0, 10, 0, 24, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 8, 1, 0, 0, 0}
+	withdrawAllPacket := []byte{0, 10, 0, 24, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 3, 0, 8, 0, 3, 0, 0}
+	for _, pkt := range [][]byte{validTemplatePacket, optionsTemplatePacket, withdrawalPacket, withdrawAllPacket} {
+		_, err = cp.decodePacket(bytes.NewBuffer(pkt), address.String())
+		require.NoError(t, err)
+	}
+	cp.mutex.RLock()
+	assert.Empty(t, cp.templateGenerations)
+	cp.mutex.RUnlock()
+
+	// The expiry timers of the withdrawn templates are ignored.
+	time.Sleep(1500 * time.Millisecond)
+	assert.Equal(t, int32(0), numExpired.Load())
+	assert.Equal(t, uint64(0), cp.GetNumTemplateExpiries(1, 256))
+	assert.Equal(t, uint64(0), cp.GetNumTemplateExpiries(1, 257))
+}
+
+func TestCollectingProcess_TemplateWithdrawal(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		protocol:     tcpTransport,
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 7),
+	}
+	optionsTemplatePacket := []byte{0, 10, 0, 34, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 3, 0, 18, 1, 1, 0, 2, 0, 1, 0, 144, 0, 4, 0, 34, 0, 4}
+	withdrawalPacket := func(setID, templateID uint16) []byte {
+		pkt := []byte{0, 10, 0, 24, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 8, 0, 0, 0, 0}
+		binary.BigEndian.PutUint16(pkt[16:18], setID)
+		binary.BigEndian.PutUint16(pkt[20:22], templateID)
+		return pkt
+	}
+	decode := func(pkt []byte) *entities.Message {
+		message, err := cp.decodePacket(bytes.NewBuffer(pkt), address.String())
+		require.NoError(t, err)
+		return message
+	}
+	decode(validTemplatePacket)
+	decode(optionsTemplatePacket)
+
+	// Withdrawing template 256 keeps the options template 257.
+	message := decode(withdrawalPacket(entities.TemplateSetID, 256))
+	assert.Equal(t, entities.Template, message.GetSet().GetSetType())
+	require.Len(t, message.GetSet().GetRecords(), 1)
+	assert.Equal(t, uint16(256), message.GetSet().GetRecords()[0].GetTemplateID())
+	assert.Empty(t, message.GetSet().GetRecords()[0].GetOrderedElementList())
+	_, err = cp.getTemplate("", 1, 256)
+	assert.Error(t, err)
+	_, err = cp.getTemplate("", 1, 257)
+	assert.NoError(t, err)
+	_, err = cp.decodePacket(bytes.NewBuffer(validDataPacket), address.String())
+	assert.Error(t, err)
+
+	// Withdrawing all the templates of the domain keeps the options templates.
+	decode(validTemplatePacket)
+	decode(withdrawalPacket(entities.TemplateSetID, entities.TemplateSetID))
+	_, err = cp.getTemplate("", 1, 256)
+	assert.Error(t, err)
+	_, isOptionsTemplate := cp.GetScopeFieldCount(1, 257)
+	assert.True(t, isOptionsTemplate)
+
+	// Withdrawing all the options templates of the domain.
+	message = decode(withdrawalPacket(entities.OptionsTemplateSetID, entities.OptionsTemplateSetID))
+	assert.Equal(t, entities.OptionsTemplate, message.GetSet().GetSetType())
+	assert.Empty(t, message.GetSet().GetRecords())
+	_, err = cp.getTemplate("", 1, 257)
+	assert.Error(t, err)
+	_, isOptionsTemplate = cp.GetScopeFieldCount(1, 257)
+	assert.False(t, isOptionsTemplate)
+}
+
+func TestCollectingProcess_DecodeInterfaceAndWlanElements(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		protocol:     tcpTransport,
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 2),
+	}
+	// Template 257 with interfaceName, interfaceDescription and wlanChannelId.
+	templatePacket := []byte{0, 10, 0, 36, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2, 0, 20, 1, 1, 0, 3, 0, 82, 255, 255, 0, 83, 255, 255, 0, 146, 0, 1}
+	_, err = cp.decodePacket(bytes.NewBuffer(templatePacket), address.String())
+	require.NoError(t, err)
+	// interfaceDescription is longer than 254 bytes and uses the 3-byte length encoding.
+	description := strings.Repeat("a", 300)
+	dataPacket := []byte{0, 10, 1, 73, 95, 154, 107, 127, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 57, 4, 'e', 't', 'h', '0', 255, 1, 44}
+	dataPacket = append(dataPacket, []byte(description)...)
+	dataPacket = append(dataPacket, 6)
+	message, err := cp.decodePacket(bytes.NewBuffer(dataPacket), address.String())
+	require.NoError(t, err)
+	record := message.GetSet().GetRecords()[0]
+	ie, _, exist := record.GetInfoElementWithValue("interfaceName")
+	require.True(t, exist)
+	assert.Equal(t, "eth0", ie.GetStringValue())
+	ie, _, exist = record.GetInfoElementWithValue("interfaceDescription")
+	require.True(t, exist)
+	assert.Equal(t, description, ie.GetStringValue())
+	ie, _, exist = record.GetInfoElementWithValue("wlanChannelId")
+	require.True(t, exist)
+	assert.Equal(t, uint8(6), ie.GetUnsigned8Value())
+	elementMap := record.GetElementMap()
+	assert.Equal(t, "eth0", elementMap["interfaceName"])
+	assert.Equal(t, uint8(6), elementMap["wlanChannelId"])
+}
+
 func TestUDPCollectingProcess_TemplateExpire(t *testing.T) {
 	input := CollectorInput{
 		Address:       hostPortIPv4,
@@ -353,15 +2527,60 @@ func TestUDPCollectingProcess_TemplateExpire(t *testing.T) {
 	}()
 	<-cp.GetMsgChan()
 	cp.Stop()
-	template, err := cp.getTemplate(1, 256)
+	template, err := cp.getTemplate("", 1, 256)
 	assert.NotNil(t, template, "Template should be stored in the template map.")
 	assert.Nil(t, err, "Template should be stored in the template map.")
 	time.Sleep(2 * time.Second)
-	template, err = cp.getTemplate(1, 256)
+	template, err = cp.getTemplate("", 1, 256)
 	assert.Nil(t, template, "Template should be deleted after 5 seconds.")
 	assert.NotNil(t, err, "Template should be deleted after 5 seconds.")
 }
 
+func TestUDPCollectingProcess_TemplateExpiryBeforeRefresh(t *testing.T) {
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		protocol:     udpTransport,
+		templateTTL:  1,
+	}
+	cp.addTemplate("", 1, 256, elementsWithValueIPv4)
+	cp.addTemplate("", 1, 257, elementsWithValueIPv4)
+	// Template 257 is refreshed before its expiry.
+	time.Sleep(600 * time.Millisecond)
+	cp.addTemplate("", 1, 257, elementsWithValueIPv4)
+	time.Sleep(700 * time.Millisecond)
+	_, err := cp.getTemplate("", 1, 256)
+	assert.Error(t, err, "Template 256 should be expired")
+	assert.Equal(t, uint64(1), cp.GetNumTemplateExpiries(1, 256))
+	_, err = cp.getTemplate("", 1, 257)
+	assert.NoError(t, err, "Template 257 should not be expired after being refreshed")
+	assert.Equal(t, uint64(0), cp.GetNumTemplateExpiries(1, 257))
+}
+
+func TestUDPCollectingProcess_TemplateTTLByObsDomain(t *testing.T) {
+	input := getCollectorInput(udpTransport, false, false)
+	input.TemplateTTL = 10
+	input.TemplateTTLByObsDomain = map[uint32]uint32{2: 1}
+	cp, err := InitCollectingProcess(input)
+	require.NoError(t, err)
+	expiredCh := make(chan templateKey, 2)
+	cp.SetTemplateExpiredHandler(func(obsDomainID uint32, templateID uint16) {
+		expiredCh <- templateKey{obsDomainID: obsDomainID, templateID: templateID}
+	})
+	cp.addTemplate("", 1, 256, elementsWithValueIPv4)
+	cp.addTemplate("", 2, 256, elementsWithValueIPv4)
+	select {
+	case key := <-expiredCh:
+		assert.Equal(t, templateKey{obsDomainID: 2, templateID: 256}, key)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Template 256 of obsDomainID 2 should be expired")
+	}
+	_, err = cp.getTemplate("", 2, 256)
+	assert.Error(t, err)
+	_, err = cp.getTemplate("", 1, 256)
+	assert.NoError(t, err, "Template 256 of obsDomainID 1 should use the default TTL")
+	assert.Empty(t, expiredCh)
+}
+
 func TestTLSCollectingProcess(t *testing.T) {
 	input := getCollectorInput(tcpTransport, true, false)
 	cp, err := InitCollectingProcess(input)
@@ -422,6 +2641,8 @@ func TestDTLSCollectingProcess(t *testing.T) {
 	// wait until collector is ready
 	waitForCollectorReady(t, cp)
 	collectorAddr, _ := net.ResolveUDPAddr("udp", cp.GetAddress().String())
+	// Keep the session open, as the templates are deleted when it is closed.
+	closeCh := make(chan struct{})
 	go func() {
 		roots := x509.NewCertPool()
 		ok := roots.AppendCertsFromPEM([]byte(testcerts.FakeCert2))
@@ -438,12 +2659,62 @@ func TestDTLSCollectingProcess(t *testing.T) {
 		defer conn.Close()
 		_, err = conn.Write(validTemplatePacket)
 		assert.NoError(t, err)
+		<-closeCh
 	}()
 	<-cp.GetMsgChan()
 	cp.Stop()
+	close(closeCh)
 	assert.NotNil(t, cp.templatesMap[1], "DTLS Collecting Process should receive and store the received template.")
 }
 
+func TestDTLSCollectingProcess_SessionTemplates(t *testing.T) {
+	caCert, caKey, caData, err := testcerts.GenerateCACert()
+	require.NoError(t, err)
+	serverCertData, serverKeyData, err := testcerts.GenerateServerCert(caCert, caKey, testcerts.AddIPAddress(net.ParseIP("127.0.0.1")))
+	require.NoError(t, err)
+	serverCert, err := tls.X509KeyPair(serverCertData, serverKeyData)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(caData))
+
+	input := getCollectorInput(udpTransport, false, false)
+	input.DTLSConfig = &dtls.Config{
+		Certificates:         []tls.Certificate{serverCert},
+		ExtendedMasterSecret: dtls.RequireExtendedMasterSecret,
+	}
+	cp, err := InitCollectingProcess(input)
+	require.NoError(t, err)
+	go cp.Start()
+	defer cp.Stop()
+	err = wait.Poll(10*time.Millisecond, time.Second, func() (bool, error) {
+		_, ok := cp.GetAddress().(*net.UDPAddr)
+		return ok, nil
+	})
+	require.NoError(t, err)
+	collectorAddr := cp.GetAddress().(*net.UDPAddr)
+
+	conn, err := dtls.Dial("udp", collectorAddr, &dtls.Config{
+		RootCAs:              roots,
+		ExtendedMasterSecret: dtls.RequireExtendedMasterSecret,
+	})
+	require.NoError(t, err)
+	_, err = conn.Write(validTemplatePacket)
+	require.NoError(t, err)
+	<-cp.GetMsgChan()
+	template, _ := cp.getTemplate("", 1, 256)
+	assert.NotNil(t, template)
+	assert.Equal(t, int64(1), cp.GetNumConnToCollector())
+
+	// Closing the session deletes its templates.
+	require.NoError(t, conn.Close())
+	err = wait.Poll(10*time.Millisecond, time.Second, func() (bool, error) {
+		_, err := cp.getTemplate("", 1, 256)
+		return err != nil, nil
+	})
+	assert.NoError(t, err, "Templates should be deleted when the session is closed")
+	assert.Equal(t, int64(0), cp.GetNumConnToCollector())
+}
+
 func TestTCPCollectingProcessIPv6(t *testing.T) {
 	input := getCollectorInput(tcpTransport, false, true)
 	cp, err := InitCollectingProcess(input)
@@ -466,7 +2737,7 @@ func TestTCPCollectingProcessIPv6(t *testing.T) {
 	<-cp.GetMsgChan()
 	message := <-cp.GetMsgChan()
 	cp.Stop()
-	template, _ := cp.getTemplate(1, 256)
+	template, _ := cp.getTemplate("", 1, 256)
 	assert.NotNil(t, template)
 	ie, _, exist := message.GetSet().GetRecords()[0].GetInfoElementWithValue("sourceIPv6Address")
 	assert.True(t, exist)
@@ -495,13 +2766,127 @@ func TestUDPCollectingProcessIPv6(t *testing.T) {
 	<-cp.GetMsgChan()
 	message := <-cp.GetMsgChan()
 	cp.Stop()
-	template, _ := cp.getTemplate(1, 256)
+	template, _ := cp.getTemplate("", 1, 256)
 	assert.NotNil(t, template)
 	ie, _, exist := message.GetSet().GetRecords()[0].GetInfoElementWithValue("sourceIPv6Address")
 	assert.True(t, exist)
 	assert.Equal(t, net.ParseIP("2001:0:3238:DFE1:63::FEFB"), ie.GetIPAddressValue())
 }
 
+func TestTCPCollectingProcess_ReceiveCompressedMessages(t *testing.T) {
+	input := getCollectorInput(tcpTransport, false, false)
+	input.DecompressMessages = true
+	cp, err := InitCollectingProcess(input)
+	if err != nil {
+		t.Fatalf("TCP Collecting Process does not start correctly: %v", err)
+	}
+	go cp.Start()
+	// wait until collector is ready
+	waitForCollectorReady(t, cp)
+	collectorAddr := cp.GetAddress()
+	compressedTemplatePacket, err := exporter.CreateCompressedIPFIXMsg(validTemplatePacket)
+	require.NoError(t, err)
+	compressedDataPacket, err := exporter.CreateCompressedIPFIXMsg(validDataPacket)
+	require.NoError(t, err)
+	go func() {
+		conn, err := net.Dial(collectorAddr.Network(), collectorAddr.String())
+		if err != nil {
+			t.Errorf("Cannot establish connection to %s", collectorAddr.String())
+			return
+		}
+		defer conn.Close()
+		conn.Write(compressedTemplatePacket)
+		conn.Write(compressedDataPacket)
+	}()
+	<-cp.GetMsgChan()
+	message := <-cp.GetMsgChan()
+	cp.Stop()
+	template, _ := cp.getTemplate("", 1, 256)
+	assert.NotNil(t, template)
+	ie, _, exist := message.GetSet().GetRecords()[0].GetInfoElementWithValue("sourceIPv4Address")
+	assert.True(t, exist)
+	assert.Equal(t, net.IP([]byte{1, 2, 3, 4}), ie.GetIPAddressValue())
+}
+
+func TestDecompressMessage(t *testing.T) {
+	compressed, err := exporter.CreateCompressedIPFIXMsg(validDataPacket)
+	require.NoError(t, err)
+	decompressed, err := decompressMessage(compressed)
+	require.NoError(t, err)
+	assert.Equal(t, validDataPacket, decompressed)
+	// Payload length in the wrapper header does not match.
+	_, err = decompressMessage(compressed[:len(compressed)-1])
+	assert.Error(t, err)
+}
+
+func TestGetCompressedMessageLength(t *testing.T) {
+	compressed, err := exporter.CreateCompressedIPFIXMsg(validDataPacket)
+	require.NoError(t, err)
+	length, err := getCompressedMessageLength(bufio.NewReader(bytes.NewReader(compressed)))
+	require.NoError(t, err)
+	assert.Equal(t, len(compressed), length)
+	// The payload length is rejected before the message is read.
+	header := []byte{0, 1, 0xff, 0xff, 0xff, 0xff}
+	_, err = getCompressedMessageLength(bufio.NewReader(bytes.NewReader(header)))
+	assert.ErrorContains(t, err, "exceeds the maximum length")
+}
+
+func TestCollectingProcess_DeliveryModeBlock(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 1),
+		deliveryMode: DeliveryModeBlock,
+	}
+	cp.addTemplate("", uint32(1), uint16(256), elementsWithValueIPv4)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 3; i++ {
+			_, err := cp.decodePacket(bytes.NewBuffer(validDataPacket), address.String())
+			assert.NoError(t, err)
+		}
+	}()
+	// Without a consumer, decoding blocks once the channel buffer is full.
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("Decoding should be blocked until the consumer reads the messages")
+	default:
+	}
+	assert.Equal(t, int64(1), cp.GetNumRecordsReceived())
+	// Slow consumer reads all the messages; none of them is dropped.
+	for i := 0; i < 3; i++ {
+		time.Sleep(20 * time.Millisecond)
+		<-cp.GetMsgChan()
+	}
+	<-done
+	assert.Equal(t, int64(3), cp.GetNumRecordsReceived())
+	assert.Equal(t, int64(0), cp.GetNumMessagesDropped())
+}
+
+func TestCollectingProcess_DeliveryModeDrop(t *testing.T) {
+	address, err := net.ResolveTCPAddr(tcpTransport, hostPortIPv4)
+	require.NoError(t, err)
+	cp := CollectingProcess{
+		templatesMap: make(map[uint32]map[uint16][]*entities.InfoElement),
+		netAddress:   address,
+		messageChan:  make(chan *entities.Message, 1),
+		deliveryMode: DeliveryModeDrop,
+	}
+	cp.addTemplate("", uint32(1), uint16(256), elementsWithValueIPv4)
+	// Without a consumer, messages which do not fit in the channel buffer are dropped.
+	for i := 0; i < 3; i++ {
+		_, err := cp.decodePacket(bytes.NewBuffer(validDataPacket), address.String())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int64(1), cp.GetNumRecordsReceived())
+	assert.Equal(t, int64(2), cp.GetNumMessagesDropped())
+	assert.Len(t, cp.GetMsgChan(), 1)
+}
+
 func getCollectorInput(network string, isEncrypted bool, isIPv6 bool) CollectorInput {
 	if network == tcpTransport {
 		var address string
@@ -0,0 +1,148 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// proxyHeaderTimeout is the maximum time to receive the PROXY protocol header
+// once the connection is accepted.
+const proxyHeaderTimeout = 10 * time.Second
+
+// proxyProtocolSignature starts every PROXY protocol v2 header.
+var proxyProtocolSignature = []byte{0x0d, 0x0a, 0x0d, 0x0a, 0x00, 0x0d, 0x0a, 0x51, 0x55, 0x49, 0x54, 0x0a}
+
+// proxyProtocolListener accepts connections which start with a PROXY protocol
+// v2 header, as sent by TCP load balancers to convey the address of the
+// client they forward the connection for.
+type proxyProtocolListener struct {
+	net.Listener
+}
+
+func (l *proxyProtocolListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &proxyProtocolConn{Conn: conn}, nil
+}
+
+// proxyProtocolConn is a connection whose PROXY protocol header is read on
+// the first call to Read or RemoteAddr, so that a slow client does not block
+// accepting other connections. RemoteAddr returns the source address from the
+// header, or the address of the load balancer for a LOCAL header. If the
+// header is invalid, Read returns the error.
+type proxyProtocolConn struct {
+	net.Conn
+	once       sync.Once
+	remoteAddr net.Addr
+	err        error
+}
+
+func (c *proxyProtocolConn) readHeader() {
+	c.once.Do(func() {
+		c.remoteAddr = c.Conn.RemoteAddr()
+		if err := c.Conn.SetReadDeadline(time.Now().Add(proxyHeaderTimeout)); err != nil {
+			c.err = err
+			return
+		}
+		addr, err := readProxyProtocolHeader(c.Conn)
+		if err != nil {
+			c.err = fmt.Errorf("error when reading PROXY protocol header from %s: %w", c.remoteAddr, err)
+			return
+		}
+		if addr != nil {
+			c.remoteAddr = addr
+		}
+		c.err = c.Conn.SetReadDeadline(time.Time{})
+	})
+}
+
+func (c *proxyProtocolConn) Read(b []byte) (int, error) {
+	c.readHeader()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.Conn.Read(b)
+}
+
+func (c *proxyProtocolConn) RemoteAddr() net.Addr {
+	c.readHeader()
+	return c.remoteAddr
+}
+
+// readProxyProtocolHeader reads a PROXY protocol v2 header and returns the
+// source address it contains. The address is nil for a LOCAL command, e.g. a
+// health check of the load balancer, and for address families other than
+// TCP over IPv4 or IPv6, in which case the address of the connection should
+// be used.
+func readProxyProtocolHeader(reader io.Reader) (net.Addr, error) {
+	/*
+		Encoding format for PROXY protocol v2 header:
+		+---------------------------------------------------------------+
+		| Signature (12 bytes)                                          |
+		+---------------+---------------+-------------------------------+
+		| Version|Cmd   | Family|Proto  | Length of the addresses       |
+		+---------------+---------------+-------------------------------+
+		| Source address, destination address, source port,            |
+		| destination port and TLVs (Length bytes)                      |
+		+---------------------------------------------------------------+
+		(Reference: https://www.haproxy.org/download/2.8/doc/proxy-protocol.txt)
+	*/
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:12], proxyProtocolSignature) {
+		return nil, fmt.Errorf("invalid PROXY protocol v2 signature")
+	}
+	if version := header[12] >> 4; version != 2 {
+		return nil, fmt.Errorf("unsupported PROXY protocol version %d", version)
+	}
+	command := header[12] & 0x0f
+	if command > 1 {
+		return nil, fmt.Errorf("unsupported PROXY protocol command %d", command)
+	}
+	addresses := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(reader, addresses); err != nil {
+		return nil, err
+	}
+	if command == 0 { // LOCAL
+		return nil, nil
+	}
+	var ipLen int
+	switch header[13] {
+	case 0x11: // TCP over IPv4
+		ipLen = net.IPv4len
+	case 0x21: // TCP over IPv6
+		ipLen = net.IPv6len
+	default:
+		return nil, nil
+	}
+	if len(addresses) < 2*ipLen+4 {
+		return nil, fmt.Errorf("PROXY protocol address length %d is too short", len(addresses))
+	}
+	return &net.TCPAddr{
+		IP:   net.IP(append([]byte(nil), addresses[:ipLen]...)),
+		Port: int(binary.BigEndian.Uint16(addresses[2*ipLen : 2*ipLen+2])),
+	}, nil
+}
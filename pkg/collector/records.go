@@ -0,0 +1,93 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"github.com/vmware/go-ipfix/pkg/entities"
+)
+
+// DecodedRecord is a decoded data record, along with the information of the
+// message in which it was received.
+type DecodedRecord struct {
+	ExportAddress string
+	ObsDomainID   uint32
+	ExportTime    uint32
+	TemplateID    uint16
+	// Elements are the elements of the record keyed by element name. If
+	// elements of different enterprises share the same name, the last one in
+	// the record is kept.
+	Elements map[string]entities.InfoElementWithValue
+}
+
+// Records returns a channel which receives the decoded data records, flattened
+// from the messages of the message channel. The messages are consumed from the
+// channel returned by GetMsgChan, so both channels should not be used at the
+// same time, and template messages are discarded. The channel is closed when
+// the collecting process is stopped. With StopAndWait, it is closed once the
+// records of the drained messages are delivered.
+func (cp *CollectingProcess) Records() <-chan *DecodedRecord {
+	cp.recordsOnce.Do(func() {
+		cp.recordsChan = make(chan *DecodedRecord, cp.messageChanSize)
+		go cp.flattenMessages(cp.stopChan)
+	})
+	return cp.recordsChan
+}
+
+func (cp *CollectingProcess) flattenMessages(stopCh chan struct{}) {
+	defer close(cp.recordsChan)
+	for {
+		select {
+		case <-stopCh:
+			// StopAndWait closes the message channel once the buffered
+			// messages are delivered, and they are flattened until then.
+			if !cp.isDraining() {
+				return
+			}
+			stopCh = nil
+		case message, ok := <-cp.messageChan:
+			if !ok {
+				return
+			}
+			set := message.GetSet()
+			if set == nil || set.GetSetType() != entities.Data {
+				continue
+			}
+			for _, record := range set.GetRecords() {
+				orderedElements := record.GetOrderedElementList()
+				decodedRecord := &DecodedRecord{
+					ExportAddress: message.GetExportAddress(),
+					ObsDomainID:   message.GetObsDomainID(),
+					ExportTime:    message.GetExportTime(),
+					TemplateID:    record.GetTemplateID(),
+					Elements:      make(map[string]entities.InfoElementWithValue, len(orderedElements)),
+				}
+				for _, element := range orderedElements {
+					decodedRecord.Elements[element.GetName()] = element
+				}
+				for sent := false; !sent; {
+					select {
+					case <-stopCh:
+						if !cp.isDraining() {
+							return
+						}
+						stopCh = nil
+					case cp.recordsChan <- decodedRecord:
+						sent = true
+					}
+				}
+			}
+		}
+	}
+}
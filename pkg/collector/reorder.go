@@ -0,0 +1,145 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/vmware/go-ipfix/pkg/entities"
+)
+
+// DefaultReorderTimeout is the maximum time a UDP message is held in the
+// reorder buffer when CollectorInput.ReorderTimeout is not set.
+const DefaultReorderTimeout = 100 * time.Millisecond
+
+// reorderBuffer holds the out-of-order messages received from one exporter for
+// one observation domain. The sequence number of an IPFIX message is the total
+// number of data records sent by the exporter before this message, so the
+// sequence number expected for the next message is the sequence number of the
+// last delivered message plus the number of data records it contains.
+type reorderBuffer struct {
+	mutex sync.Mutex
+	// nextSeq is the sequence number of the next message to deliver
+	nextSeq     uint32
+	initialized bool
+	// messages are the held messages sorted by sequence number
+	messages []*entities.Message
+	timer    *time.Timer
+}
+
+// seqBefore returns whether sequence number a comes before b, taking
+// wraparound into account.
+func seqBefore(a, b uint32) bool {
+	return int32(a-b) < 0
+}
+
+func nextSequenceNum(message *entities.Message) uint32 {
+	seq := message.GetSequenceNum()
+	if message.GetSet().GetSetType() == entities.Data {
+		seq += uint32(message.GetSet().GetNumberOfRecords())
+	}
+	return seq
+}
+
+// reorderMessage adds the message to the reorder buffer of its exporter and
+// delivers the buffered messages which are in sequence. If the buffer holds
+// more than reorderWindowSize messages, the earliest ones are delivered even if
+// there is a gap in the sequence numbers. Held messages are flushed after
+// reorderTimeout.
+func (cp *CollectingProcess) reorderMessage(clientAddress string, message *entities.Message) {
+	key := fmt.Sprintf("%s/%d", clientAddress, message.GetObsDomainID())
+	cp.mutex.Lock()
+	if cp.reorderBuffers == nil {
+		cp.reorderBuffers = make(map[string]*reorderBuffer)
+	}
+	buffer, exist := cp.reorderBuffers[key]
+	if !exist {
+		buffer = &reorderBuffer{}
+		cp.reorderBuffers[key] = buffer
+	}
+	cp.mutex.Unlock()
+
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	if !buffer.initialized {
+		buffer.nextSeq = message.GetSequenceNum()
+		buffer.initialized = true
+	}
+	if seqBefore(message.GetSequenceNum(), buffer.nextSeq) {
+		// late message whose successors have already been delivered
+		klog.V(2).InfoS("Delivering late message out of order", "exporter", clientAddress, "sequenceNumber", message.GetSequenceNum())
+		cp.deliverMessage(message)
+		return
+	}
+	index := sort.Search(len(buffer.messages), func(i int) bool {
+		return seqBefore(message.GetSequenceNum(), buffer.messages[i].GetSequenceNum())
+	})
+	buffer.messages = append(buffer.messages, nil)
+	copy(buffer.messages[index+1:], buffer.messages[index:])
+	buffer.messages[index] = message
+
+	for len(buffer.messages) > 0 && (buffer.messages[0].GetSequenceNum() == buffer.nextSeq || len(buffer.messages) > cp.reorderWindowSize) {
+		cp.deliverFromReorderBuffer(buffer)
+	}
+	if buffer.timer != nil {
+		buffer.timer.Stop()
+		buffer.timer = nil
+	}
+	if len(buffer.messages) > 0 {
+		buffer.timer = time.AfterFunc(cp.reorderTimeout, func() {
+			buffer.mutex.Lock()
+			defer buffer.mutex.Unlock()
+			for len(buffer.messages) > 0 {
+				cp.deliverFromReorderBuffer(buffer)
+			}
+		})
+	}
+}
+
+// flushReorderBuffers delivers all the held messages, without waiting for the
+// missing ones.
+func (cp *CollectingProcess) flushReorderBuffers() {
+	cp.mutex.RLock()
+	buffers := make([]*reorderBuffer, 0, len(cp.reorderBuffers))
+	for _, buffer := range cp.reorderBuffers {
+		buffers = append(buffers, buffer)
+	}
+	cp.mutex.RUnlock()
+	for _, buffer := range buffers {
+		buffer.mutex.Lock()
+		if buffer.timer != nil {
+			buffer.timer.Stop()
+			buffer.timer = nil
+		}
+		for len(buffer.messages) > 0 {
+			cp.deliverFromReorderBuffer(buffer)
+		}
+		buffer.mutex.Unlock()
+	}
+}
+
+// deliverFromReorderBuffer delivers the first held message. The buffer mutex
+// must be held by the caller.
+func (cp *CollectingProcess) deliverFromReorderBuffer(buffer *reorderBuffer) {
+	message := buffer.messages[0]
+	buffer.messages = buffer.messages[1:]
+	buffer.nextSeq = nextSequenceNum(message)
+	cp.deliverMessage(message)
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"github.com/ishidawataru/sctp"
+	"k8s.io/klog/v2"
+)
+
+// startSCTPServer listens for SCTP associations, which are handled like TCP
+// connections. SCTP preserves message boundaries and every IPFIX message is
+// sent as a single SCTP message, so the messages received on the different
+// streams of an association are never interleaved and the message length
+// framing used for TCP applies to all the streams.
+func (cp *CollectingProcess) startSCTPServer() {
+	address, err := sctp.ResolveSCTPAddr("sctp", cp.address)
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+	listener, err := sctp.ListenSCTP("sctp", address)
+	if err != nil {
+		klog.Errorf("Cannot start collecting process on %s: %v", cp.address, err)
+		return
+	}
+	cp.updateAddress(listener.Addr())
+	klog.Infof("Start SCTP collecting process on %s", cp.netAddress)
+
+	cp.wg.Add(1)
+	go func(stopCh chan struct{}) {
+		defer cp.wg.Done()
+		for {
+			conn, err := listener.AcceptSCTP()
+			if err != nil {
+				select {
+				case <-stopCh:
+					return
+				default:
+					klog.Errorf("Cannot start the association on the collecting process at %s: %v", cp.address, err)
+					return
+				}
+			}
+			cp.wg.Add(1)
+			// Every association is a client of the collecting process, and is
+			// closed when the collecting process is stopped.
+			go cp.handleTCPClient(conn)
+		}
+	}(cp.stopChan)
+	<-cp.stopChan
+	listener.Close()
+}
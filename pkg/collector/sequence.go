@@ -0,0 +1,65 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"k8s.io/klog/v2"
+
+	"github.com/vmware/go-ipfix/pkg/entities"
+)
+
+// sequenceKey identifies the messages of one observation domain received
+// from one exporter, whose sequence numbers are consecutive.
+type sequenceKey struct {
+	exportAddress string
+	obsDomainID   uint32
+}
+
+// checkSequenceNum compares the sequence number of the message with the
+// sequence number expected after the previous messages of the same exporter
+// and observation domain, and counts the data records missing in between.
+// Sequence numbers are compared modulo 2^32 (RFC 7011 section 3.1), so that a
+// wraparound is not reported as a gap. Messages received late, after their
+// successors, neither count as missing records nor change the expected
+// sequence number.
+func (cp *CollectingProcess) checkSequenceNum(exportAddress string, message *entities.Message) {
+	key := sequenceKey{exportAddress, message.GetObsDomainID()}
+	seq := message.GetSequenceNum()
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	if cp.nextSequenceNums == nil {
+		cp.nextSequenceNums = make(map[sequenceKey]uint32)
+	}
+	expected, exist := cp.nextSequenceNums[key]
+	if exist && seqBefore(expected, seq) {
+		missing := seq - expected
+		klog.V(2).InfoS("Gap in sequence numbers", "exporter", exportAddress, "obsDomainID", key.obsDomainID, "expected", expected, "received", seq, "missingRecords", missing)
+		cp.numMissingRecords += uint64(missing)
+	}
+	if next := nextSequenceNum(message); !exist || seqBefore(expected, next) {
+		cp.nextSequenceNums[key] = next
+	}
+}
+
+// GetNumMissingRecords returns the number of data records which were not
+// received, as indicated by the gaps in the sequence numbers of the messages
+// of every exporter and observation domain. The records of a message received
+// after its successors are counted as missing, since the gap is detected when
+// the successors are received.
+func (cp *CollectingProcess) GetNumMissingRecords() int64 {
+	cp.mutex.RLock()
+	defer cp.mutex.RUnlock()
+	return int64(cp.numMissingRecords)
+}
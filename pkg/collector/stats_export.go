@@ -0,0 +1,153 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/vmware/go-ipfix/pkg/entities"
+	"github.com/vmware/go-ipfix/pkg/registry"
+)
+
+// DefaultStatsExportInterval is the interval at which the collector statistics
+// are exported when StatsExportConfig.Interval is not set.
+const DefaultStatsExportInterval = time.Minute
+
+// StatsExporter sends the collector statistics to an upstream collector. It is
+// implemented by exporter.ExportingProcess.
+type StatsExporter interface {
+	SendSet(set entities.Set) (int, error)
+	NewTemplateID() uint16
+}
+
+// StatsExportConfig configures the periodic export of the collector statistics
+// as an IPFIX options record.
+type StatsExportConfig struct {
+	Exporter StatsExporter
+	// Interval is the export interval. Default is DefaultStatsExportInterval.
+	Interval time.Duration
+}
+
+// startStatsExport exports the collector statistics every interval until the
+// collecting process is stopped.
+func (cp *CollectingProcess) startStatsExport() {
+	interval := cp.statsExportConfig.Interval
+	if interval == 0 {
+		interval = DefaultStatsExportInterval
+	}
+	cp.wg.Add(1)
+	go func() {
+		defer cp.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-cp.stopChan:
+				return
+			case <-ticker.C:
+				if err := cp.ExportStats(); err != nil {
+					klog.ErrorS(err, "Failed to export collector statistics")
+				}
+			}
+		}
+	}()
+}
+
+// ExportStats sends the collector statistics with the exporter configured in
+// CollectorInput.StatsExportConfig. The statistics are sent as an options
+// record with collectorIPv4Address (or collectorIPv6Address) and
+// collectorTransportPort as scope, and ignoredDataRecordTotalCount as the
+// number of data records dropped because the message channel was full. The
+// options template is sent along with every record, so that collectors using
+// UDP always have it.
+func (cp *CollectingProcess) ExportStats() error {
+	if cp.statsExportConfig == nil || cp.statsExportConfig.Exporter == nil {
+		return fmt.Errorf("statistics export is not configured")
+	}
+	addr := cp.GetAddress()
+	if addr == nil {
+		return fmt.Errorf("collecting process is not started")
+	}
+	var ip net.IP
+	var port int
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		ip, port = a.IP, a.Port
+	case *net.UDPAddr:
+		ip, port = a.IP, a.Port
+	default:
+		return fmt.Errorf("unsupported collector address %s", addr)
+	}
+	addressElementName := "collectorIPv4Address"
+	if ip.To4() == nil {
+		addressElementName = "collectorIPv6Address"
+	} else {
+		ip = ip.To4()
+	}
+	elements := make([]*entities.InfoElement, 3)
+	for i, name := range []string{addressElementName, "collectorTransportPort", "ignoredDataRecordTotalCount"} {
+		element, err := registry.GetInfoElement(name, registry.IANAEnterpriseID)
+		if err != nil {
+			return err
+		}
+		elements[i] = element
+	}
+
+	exporter := cp.statsExportConfig.Exporter
+	cp.mutex.Lock()
+	if cp.statsTemplateID == 0 {
+		cp.statsTemplateID = exporter.NewTemplateID()
+	}
+	templateID := cp.statsTemplateID
+	numRecordsDropped := cp.numOfRecordsDropped
+	cp.mutex.Unlock()
+
+	templateElements := make([]entities.InfoElementWithValue, len(elements))
+	for i, element := range elements {
+		var err error
+		if templateElements[i], err = entities.DecodeAndCreateInfoElementWithValue(element, nil); err != nil {
+			return err
+		}
+	}
+	templateSet := entities.NewSet(false)
+	if err := templateSet.PrepareSet(entities.OptionsTemplate, templateID); err != nil {
+		return err
+	}
+	if err := templateSet.AddOptionsTemplateRecord(templateElements, 2, templateID); err != nil {
+		return err
+	}
+	if _, err := exporter.SendSet(templateSet); err != nil {
+		return err
+	}
+
+	dataSet := entities.NewSet(false)
+	if err := dataSet.PrepareSet(entities.Data, templateID); err != nil {
+		return err
+	}
+	dataElements := []entities.InfoElementWithValue{
+		entities.NewIPAddressInfoElement(elements[0], ip),
+		entities.NewUnsigned16InfoElement(elements[1], uint16(port)),
+		entities.NewUnsigned64InfoElement(elements[2], numRecordsDropped),
+	}
+	if err := dataSet.AddRecord(dataElements, templateID); err != nil {
+		return err
+	}
+	_, err := exporter.SendSet(dataSet)
+	return err
+}
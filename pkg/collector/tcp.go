@@ -9,32 +9,46 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"time"
 
 	"k8s.io/klog/v2"
 )
 
+const tlsHandshakeTimeout = 10 * time.Second
+
 func (cp *CollectingProcess) startTCPServer() {
 	var listener net.Listener
-	if cp.isEncrypted { // use TLS
-		config, err := cp.createServerConfig()
-		if err != nil {
-			klog.Error(err)
-			return
+	if cp.isEncrypted || cp.tlsConfig != nil { // use TLS
+		var err error
+		config := cp.tlsConfig
+		if config == nil {
+			config, err = cp.createServerConfig()
+			if err != nil {
+				klog.Error(err)
+				return
+			}
 		}
-		listener, err = tls.Listen("tcp", cp.address, config)
+		listener, err = cp.listenTCP()
 		if err != nil {
 			klog.Errorf("Cannot start tls collecting process on %s: %v", cp.address, err)
 			return
 		}
+		if cp.proxyProtocol {
+			listener = &proxyProtocolListener{listener}
+		}
+		listener = tls.NewListener(listener, config)
 		cp.updateAddress(listener.Addr())
 		klog.Infof("Started TLS collecting process on %s", cp.netAddress)
 	} else {
 		var err error
-		listener, err = net.Listen("tcp", cp.address)
+		listener, err = cp.listenTCP()
 		if err != nil {
 			klog.Errorf("Cannot start collecting process on %s: %v", cp.address, err)
 			return
 		}
+		if cp.proxyProtocol {
+			listener = &proxyProtocolListener{listener}
+		}
 		cp.updateAddress(listener.Addr())
 		klog.Infof("Start TCP collecting process on %s", cp.netAddress)
 	}
@@ -62,15 +76,32 @@ func (cp *CollectingProcess) startTCPServer() {
 }
 
 func (cp *CollectingProcess) handleTCPClient(conn net.Conn) {
+	defer cp.wg.Done()
+	defer conn.Close()
 	address := conn.RemoteAddr().String()
+	// Complete the TLS handshake before reading any message, so that a failed
+	// handshake only closes the connection.
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		if err := handshakeTLS(tlsConn); err != nil {
+			cp.incrementNumTLSHandshakeErrors()
+			klog.ErrorS(err, "TLS handshake failed", "address", address)
+			return
+		}
+	}
 	client := cp.createClient()
 	cp.addClient(address, client)
-	defer cp.wg.Done()
-	defer conn.Close()
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
 		reader := bufio.NewReader(conn)
 		for {
-			length, err := getMessageLength(reader)
+			var length int
+			var err error
+			if cp.decompressMessages {
+				length, err = getCompressedMessageLength(reader)
+			} else {
+				length, err = getMessageLength(reader)
+			}
 			if errors.Is(err, io.EOF) {
 				klog.V(2).InfoS("Connection was closed by client")
 				return
@@ -87,7 +118,15 @@ func (cp *CollectingProcess) handleTCPClient(conn net.Conn) {
 				cp.deleteClient(address)
 				return
 			}
-			message, err := cp.decodePacket(bytes.NewBuffer(buff), address)
+			receiveTime := time.Now()
+			if cp.decompressMessages {
+				buff, err = decompressMessage(buff)
+				if err != nil {
+					klog.ErrorS(err, "Error when decompressing the message")
+					continue
+				}
+			}
+			message, err := cp.decodePacketWithReceiveTime(bytes.NewBuffer(buff), address, receiveTime)
 			if err != nil {
 				klog.ErrorS(err, "Error when decoding packet")
 				continue
@@ -97,6 +136,23 @@ func (cp *CollectingProcess) handleTCPClient(conn net.Conn) {
 		}
 	}()
 	<-cp.stopChan
+	if cp.isDraining() {
+		// Closing the connection stops reading from the socket, and the
+		// messages already buffered by the reader are decoded until it
+		// fails to read the next one.
+		conn.Close()
+		<-done
+	}
+}
+
+func handshakeTLS(conn *tls.Conn) error {
+	if err := conn.SetDeadline(time.Now().Add(tlsHandshakeTimeout)); err != nil {
+		return err
+	}
+	if err := conn.Handshake(); err != nil {
+		return err
+	}
+	return conn.SetDeadline(time.Time{})
 }
 
 func (cp *CollectingProcess) createServerConfig() (*tls.Config, error) {
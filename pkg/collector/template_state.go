@@ -0,0 +1,160 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vmware/go-ipfix/pkg/entities"
+	"github.com/vmware/go-ipfix/pkg/exporter"
+)
+
+const templateStateVersion = 1
+
+type templateState struct {
+	Version   int                `json:"version"`
+	Templates []templateSnapshot `json:"templates"`
+}
+
+type templateSnapshot struct {
+	// Exporter is the IP address of the exporter of the template if templates
+	// are isolated by exporter.
+	Exporter    string                  `json:"exporter,omitempty"`
+	ObsDomainID uint32                  `json:"obsDomainID"`
+	TemplateID  uint16                  `json:"templateID"`
+	Elements    []*entities.InfoElement `json:"elements"`
+	// ScopeFieldCount is the number of scope fields of an options template.
+	ScopeFieldCount int `json:"scopeFieldCount,omitempty"`
+}
+
+// ExportTemplateState returns a snapshot of all the templates currently known
+// by the collecting process. It can be persisted and given to
+// ImportTemplateState after a restart, so that data records can be decoded
+// without waiting for exporters to resend their templates.
+func (cp *CollectingProcess) ExportTemplateState() ([]byte, error) {
+	state := templateState{
+		Version:   templateStateVersion,
+		Templates: cp.getTemplateSnapshots(),
+	}
+	return json.Marshal(state)
+}
+
+// getTemplateSnapshots returns all the templates currently known, ordered by
+// exporter, obsDomainID and template ID.
+func (cp *CollectingProcess) getTemplateSnapshots() []templateSnapshot {
+	cp.mutex.RLock()
+	templates := make([]templateSnapshot, 0)
+	for exporter, store := range cp.getTemplateStores() {
+		for _, obsDomainID := range store.ObsDomainIDs() {
+			for _, templateID := range store.TemplateIDs(obsDomainID) {
+				elements, exists := store.Get(obsDomainID, templateID)
+				if !exists {
+					continue
+				}
+				scopeFieldCount, _ := cp.getScopeFieldCountLocked(exporter, obsDomainID, templateID)
+				templates = append(templates, templateSnapshot{
+					Exporter:        exporter,
+					ObsDomainID:     obsDomainID,
+					TemplateID:      templateID,
+					Elements:        elements,
+					ScopeFieldCount: scopeFieldCount,
+				})
+			}
+		}
+	}
+	cp.mutex.RUnlock()
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].Exporter != templates[j].Exporter {
+			return templates[i].Exporter < templates[j].Exporter
+		}
+		if templates[i].ObsDomainID != templates[j].ObsDomainID {
+			return templates[i].ObsDomainID < templates[j].ObsDomainID
+		}
+		return templates[i].TemplateID < templates[j].TemplateID
+	})
+	return templates
+}
+
+// CreateTemplateMessages serializes all the templates currently known by the
+// collecting process into IPFIX messages, so that they can be forwarded to a
+// downstream collector, e.g. when relaying the data records. Every message
+// contains a template set, or an options template set, with a single template
+// record, and has the obsDomainID of the template.
+func (cp *CollectingProcess) CreateTemplateMessages(exportTime time.Time) ([][]byte, error) {
+	templates := cp.getTemplateSnapshots()
+	msgs := make([][]byte, 0, len(templates))
+	for _, template := range templates {
+		elements := make([]entities.InfoElementWithValue, len(template.Elements))
+		for i, element := range template.Elements {
+			var err error
+			if elements[i], err = entities.DecodeAndCreateInfoElementWithValue(element, nil); err != nil {
+				return nil, err
+			}
+		}
+		templateSet := entities.NewSet(false)
+		var err error
+		if template.ScopeFieldCount > 0 {
+			if err = templateSet.PrepareSet(entities.OptionsTemplate, template.TemplateID); err == nil {
+				err = templateSet.AddOptionsTemplateRecord(elements, template.ScopeFieldCount, template.TemplateID)
+			}
+		} else {
+			if err = templateSet.PrepareSet(entities.Template, template.TemplateID); err == nil {
+				err = templateSet.AddRecord(elements, template.TemplateID)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error when creating template record %d with obsDomainID %d: %v", template.TemplateID, template.ObsDomainID, err)
+		}
+		templateSet.UpdateLenInHeader()
+		// Template sets do not increase the sequence number.
+		msg, err := exporter.CreateIPFIXMsg(templateSet, template.ObsDomainID, 0, exportTime)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+// ImportTemplateState loads the templates from a snapshot created by
+// ExportTemplateState. Existing templates with the same obsDomainID and
+// template ID are replaced. For UDP, imported templates expire after the
+// template TTL like received ones.
+func (cp *CollectingProcess) ImportTemplateState(data []byte) error {
+	var state templateState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("error when decoding template state: %v", err)
+	}
+	if state.Version != templateStateVersion {
+		return fmt.Errorf("template state version %d is not supported", state.Version)
+	}
+	for _, template := range state.Templates {
+		for _, element := range template.Elements {
+			if element == nil {
+				return fmt.Errorf("template %d with obsDomainID %d contains an invalid element", template.TemplateID, template.ObsDomainID)
+			}
+		}
+		if template.ScopeFieldCount < 0 || template.ScopeFieldCount > len(template.Elements) {
+			return fmt.Errorf("template %d with obsDomainID %d has invalid scope field count %d", template.TemplateID, template.ObsDomainID, template.ScopeFieldCount)
+		}
+	}
+	for _, template := range state.Templates {
+		cp.addTemplateElements(cp.getTemplateExporter(template.Exporter), template.ObsDomainID, template.TemplateID, template.Elements, uint16(template.ScopeFieldCount))
+	}
+	return nil
+}
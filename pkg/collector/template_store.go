@@ -0,0 +1,115 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"github.com/vmware/go-ipfix/pkg/entities"
+)
+
+// TemplateStore stores the templates received by the collecting process. A
+// store backed by a shared backend lets several collecting processes decode
+// the data records of any exporter. The methods are called with the mutex of
+// the collecting process held, so a store used by a single collecting process
+// does not need its own synchronization.
+type TemplateStore interface {
+	// Get returns the elements of the template, or false if it is not stored.
+	Get(obsDomainID uint32, templateID uint16) ([]*entities.InfoElement, bool)
+	// Put adds or replaces the template.
+	Put(obsDomainID uint32, templateID uint16, elements []*entities.InfoElement)
+	// Delete deletes the template.
+	Delete(obsDomainID uint32, templateID uint16)
+	// Expire is called when the lifetime of a UDP template ends without the
+	// exporter refreshing it to this collecting process. A shared store may
+	// keep the template if it was refreshed through another collecting process.
+	Expire(obsDomainID uint32, templateID uint16)
+	// ObsDomainIDs returns the observation domains for which templates are stored.
+	ObsDomainIDs() []uint32
+	// TemplateIDs returns the IDs of the templates stored for the observation domain.
+	TemplateIDs(obsDomainID uint32) []uint16
+}
+
+// memoryTemplateStore is the default TemplateStore, which stores the templates
+// in memory. For each obsDomainID, there is a map of templates.
+type memoryTemplateStore map[uint32]map[uint16][]*entities.InfoElement
+
+// NewMemoryTemplateStore returns an empty in-memory TemplateStore, which is
+// not safe for concurrent use.
+func NewMemoryTemplateStore() TemplateStore {
+	return make(memoryTemplateStore)
+}
+
+func (s memoryTemplateStore) Get(obsDomainID uint32, templateID uint16) ([]*entities.InfoElement, bool) {
+	elements, exists := s[obsDomainID][templateID]
+	return elements, exists
+}
+
+func (s memoryTemplateStore) Put(obsDomainID uint32, templateID uint16, elements []*entities.InfoElement) {
+	if _, exists := s[obsDomainID]; !exists {
+		s[obsDomainID] = make(map[uint16][]*entities.InfoElement)
+	}
+	s[obsDomainID][templateID] = elements
+}
+
+func (s memoryTemplateStore) Delete(obsDomainID uint32, templateID uint16) {
+	delete(s[obsDomainID], templateID)
+	if len(s[obsDomainID]) == 0 {
+		delete(s, obsDomainID)
+	}
+}
+
+func (s memoryTemplateStore) Expire(obsDomainID uint32, templateID uint16) {
+	s.Delete(obsDomainID, templateID)
+}
+
+func (s memoryTemplateStore) ObsDomainIDs() []uint32 {
+	obsDomainIDs := make([]uint32, 0, len(s))
+	for obsDomainID := range s {
+		obsDomainIDs = append(obsDomainIDs, obsDomainID)
+	}
+	return obsDomainIDs
+}
+
+func (s memoryTemplateStore) TemplateIDs(obsDomainID uint32) []uint16 {
+	templateIDs := make([]uint16, 0, len(s[obsDomainID]))
+	for templateID := range s[obsDomainID] {
+		templateIDs = append(templateIDs, templateID)
+	}
+	return templateIDs
+}
+
+// getTemplateStore returns the store of the templates received from the given
+// exporter if templates are isolated by exporter. Otherwise, the exporter is
+// empty and it returns the TemplateStore given in CollectorInput, or the
+// in-memory templatesMap by default.
+func (cp *CollectingProcess) getTemplateStore(exporter string) TemplateStore {
+	if exporter != "" {
+		// The store of an unknown exporter is a nil map, which is empty.
+		return cp.exporterTemplates[exporter]
+	}
+	if cp.templateStore != nil {
+		return cp.templateStore
+	}
+	return cp.templatesMap
+}
+
+// getTemplateStores returns the template stores by exporter, including the
+// default store for the empty exporter.
+func (cp *CollectingProcess) getTemplateStores() map[string]TemplateStore {
+	stores := map[string]TemplateStore{"": cp.getTemplateStore("")}
+	for exporter, store := range cp.exporterTemplates {
+		stores[exporter] = store
+	}
+	return stores
+}
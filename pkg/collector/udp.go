@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"crypto/tls"
 	"crypto/x509"
+	"fmt"
 	"net"
 	"time"
 
@@ -28,28 +29,28 @@ import (
 )
 
 func (cp *CollectingProcess) startUDPServer() {
-	var listener net.Listener
-	var err error
-	var conn net.Conn
 	address, err := net.ResolveUDPAddr(cp.protocol, cp.address)
 	if err != nil {
 		klog.Error(err)
 		return
 	}
-	if cp.isEncrypted { // use DTLS
-		cert, err := tls.X509KeyPair(cp.serverCert, cp.serverKey)
-		if err != nil {
-			klog.Error(err)
-			return
-		}
-		certPool := x509.NewCertPool()
-		certPool.AppendCertsFromPEM(cp.serverCert)
-		config := &dtls.Config{
-			Certificates:         []tls.Certificate{cert},
-			ExtendedMasterSecret: dtls.RequireExtendedMasterSecret,
-			ClientCAs:            certPool,
+	if cp.isEncrypted || cp.dtlsConfig != nil { // use DTLS
+		config := cp.dtlsConfig
+		if config == nil {
+			cert, err := tls.X509KeyPair(cp.serverCert, cp.serverKey)
+			if err != nil {
+				klog.Error(err)
+				return
+			}
+			certPool := x509.NewCertPool()
+			certPool.AppendCertsFromPEM(cp.serverCert)
+			config = &dtls.Config{
+				Certificates:         []tls.Certificate{cert},
+				ExtendedMasterSecret: dtls.RequireExtendedMasterSecret,
+				ClientCAs:            certPool,
+			}
 		}
-		listener, err = dtls.Listen("udp", address, config)
+		listener, err := dtls.Listen("udp", address, config)
 		if err != nil {
 			klog.Error(err)
 			return
@@ -57,42 +58,36 @@ func (cp *CollectingProcess) startUDPServer() {
 		defer listener.Close()
 		cp.updateAddress(listener.Addr())
 		klog.Infof("Start dtls collecting process on %s", cp.netAddress)
-		conn, err = listener.Accept()
-		if err != nil {
-			klog.Error(err)
-			return
-		}
-		defer conn.Close()
-		go func() {
-			buff := make([]byte, cp.maxBufferSize)
+		go func(stopCh chan struct{}) {
 			for {
-				size, err := conn.Read(buff)
+				conn, err := listener.Accept()
 				if err != nil {
-					if size == 0 { // received stop collector message
+					select {
+					case <-stopCh:
 						return
+					default:
+						// The handshake of a new session failed.
+						klog.Errorf("Cannot accept dtls session on the collecting process at %s: %v", cp.address, err)
+						continue
 					}
-					klog.Errorf("Error in collecting process: %v", err)
-					return
-				}
-				address, err = net.ResolveUDPAddr(conn.LocalAddr().Network(), conn.LocalAddr().String())
-				if err != nil {
-					klog.Errorf("Error in dtls collecting process: %v", err)
-					return
 				}
-				klog.V(2).Infof("Receiving %d bytes from %s", size, address.String())
-				cp.handleUDPClient(address)
-				buffBytes := make([]byte, size)
-				copy(buffBytes, buff[0:size])
-				cp.clients[address.String()].packetChan <- bytes.NewBuffer(buffBytes)
+				cp.wg.Add(1)
+				go cp.handleDTLSSession(conn)
 			}
-		}()
+		}(cp.stopChan)
 	} else { // use udp
-		conn, err := net.ListenUDP("udp", address)
+		conn, err := cp.listenUDP(address)
 		if err != nil {
 			klog.Error(err)
 			return
 		}
-		cp.updateAddress(conn.LocalAddr())
+		if address.IP.IsMulticast() {
+			// The socket is bound to the wildcard address, report the group
+			// address instead.
+			cp.updateAddress(&net.UDPAddr{IP: address.IP, Port: conn.LocalAddr().(*net.UDPAddr).Port})
+		} else {
+			cp.updateAddress(conn.LocalAddr())
+		}
 		klog.Infof("Start UDP collecting process on %s", cp.netAddress)
 		defer conn.Close()
 		go func() {
@@ -106,15 +101,90 @@ func (cp *CollectingProcess) startUDPServer() {
 					klog.Errorf("Error in udp collecting process: %v", err)
 					return
 				}
+				receiveTime := time.Now()
 				klog.V(2).Infof("Receiving %d bytes from %s", size, address.String())
 				cp.handleUDPClient(address)
-				cp.clients[address.String()].packetChan <- bytes.NewBuffer(buff[0:size])
+				cp.clients[address.String()].packetChan <- &receivedPacket{bytes.NewBuffer(buff[0:size]), receiveTime}
 			}
 		}()
 	}
 	<-cp.stopChan
 }
 
+// listenUDP listens on the UDP address. If the address is a multicast group
+// address, the group is joined on the configured multicast interface.
+func (cp *CollectingProcess) listenUDP(address *net.UDPAddr) (*net.UDPConn, error) {
+	if !address.IP.IsMulticast() {
+		return net.ListenUDP("udp", address)
+	}
+	var iface *net.Interface
+	if cp.multicastInterface != "" {
+		var err error
+		iface, err = net.InterfaceByName(cp.multicastInterface)
+		if err != nil {
+			return nil, fmt.Errorf("cannot find multicast interface %s: %v", cp.multicastInterface, err)
+		}
+	}
+	conn, err := net.ListenMulticastUDP("udp", iface, address)
+	if err != nil {
+		return nil, fmt.Errorf("cannot join multicast group %s: %v", address.IP, err)
+	}
+	klog.InfoS("Joined multicast group", "group", address.IP, "interface", cp.multicastInterface)
+	return conn, nil
+}
+
+// handleDTLSSession decodes the messages received in the DTLS session. The
+// session is a client of the collecting process, keyed by its remote address,
+// and the templates received in the session are deleted when it is closed.
+func (cp *CollectingProcess) handleDTLSSession(conn net.Conn) {
+	defer cp.wg.Done()
+	address := conn.RemoteAddr().String()
+	cp.addClient(address, cp.createClient())
+	cp.addSession(address)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buff := make([]byte, cp.maxBufferSize)
+		for {
+			size, err := conn.Read(buff)
+			if err != nil {
+				if size == 0 { // the session was closed
+					klog.V(2).InfoS("DTLS session was closed", "address", address)
+					return
+				}
+				klog.ErrorS(err, "Error when reading from dtls session", "address", address)
+				return
+			}
+			receiveTime := time.Now()
+			klog.V(2).Infof("Receiving %d bytes from %s", size, address)
+			buffBytes := make([]byte, size)
+			copy(buffBytes, buff[0:size])
+			if cp.decompressMessages {
+				buffBytes, err = decompressMessage(buffBytes)
+				if err != nil {
+					klog.Error(err)
+					continue
+				}
+			}
+			if _, err := cp.decodePacketWithReceiveTime(bytes.NewBuffer(buffBytes), address, receiveTime); err != nil {
+				klog.Error(err)
+			}
+		}
+	}()
+	select {
+	case <-cp.stopChan:
+		conn.Close()
+		if cp.isDraining() {
+			<-done
+		}
+		cp.deleteClient(address)
+	case <-done:
+		conn.Close()
+		cp.deleteClient(address)
+		cp.deleteSession(address)
+	}
+}
+
 func (cp *CollectingProcess) handleUDPClient(address net.Addr) {
 	if _, exist := cp.clients[address.String()]; !exist {
 		client := cp.createClient()
@@ -123,10 +193,41 @@ func (cp *CollectingProcess) handleUDPClient(address net.Addr) {
 		go func() {
 			defer cp.wg.Done()
 			ticker := time.NewTicker(time.Duration(entities.TemplateRefreshTimeOut) * time.Second)
+			handlePacket := func(packet *receivedPacket) error {
+				if cp.decompressMessages {
+					buff, err := decompressMessage(packet.buffer.Bytes())
+					if err != nil {
+						klog.Error(err)
+						return nil
+					}
+					packet.buffer = bytes.NewBuffer(buff)
+				}
+				// get the message here
+				message, err := cp.decodePacketWithReceiveTime(packet.buffer, address.String(), packet.receiveTime)
+				if err != nil {
+					return err
+				}
+				klog.V(4).Infof("Processed message from exporter %v, number of records: %v, observation domain ID: %v",
+					message.GetExportAddress(), message.GetSet().GetNumberOfRecords(), message.GetObsDomainID())
+				return nil
+			}
 			for {
 				select {
 				case <-cp.stopChan:
 					klog.Infof("Collecting process from %s has stopped.", address.String())
+					if cp.isDraining() {
+						// decode the packets which were already read
+						for drained := false; !drained; {
+							select {
+							case packet := <-client.packetChan:
+								if err := handlePacket(packet); err != nil {
+									klog.Error(err)
+								}
+							default:
+								drained = true
+							}
+						}
+					}
 					cp.deleteClient(address.String())
 					return
 				case <-ticker.C: // set timeout for udp connection
@@ -134,14 +235,10 @@ func (cp *CollectingProcess) handleUDPClient(address net.Addr) {
 					cp.deleteClient(address.String())
 					return
 				case packet := <-client.packetChan:
-					// get the message here
-					message, err := cp.decodePacket(packet, address.String())
-					if err != nil {
+					if err := handlePacket(packet); err != nil {
 						klog.Error(err)
 						return
 					}
-					klog.V(4).Infof("Processed message from exporter %v, number of records: %v, observation domain ID: %v",
-						message.GetExportAddress(), message.GetSet().GetNumberOfRecords(), message.GetObsDomainID())
 					ticker.Stop()
 					ticker = time.NewTicker(time.Duration(entities.TemplateRefreshTimeOut) * time.Second)
 				}
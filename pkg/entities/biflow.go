@@ -0,0 +1,68 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"fmt"
+	"strings"
+)
+
+// reverseEnterpriseID is the enterprise ID of the reverse information elements
+// defined in RFC 5103. A reverse element has the same element ID as its IANA
+// forward counterpart and its name is prefixed with "reverse".
+const reverseEnterpriseID uint32 = 29305
+
+// BiflowRecord presents the elements of a biflow record per direction.
+type BiflowRecord struct {
+	// Forward contains the forward direction elements of the record, as well
+	// as the elements without a direction such as the flow keys, keyed by
+	// element name.
+	Forward map[string]InfoElementWithValue
+	// Reverse contains the reverse direction elements of the record, keyed by
+	// the name of their forward counterpart, e.g. octetDeltaCount for
+	// reverseOctetDeltaCount.
+	Reverse map[string]InfoElementWithValue
+}
+
+// MergeBiflow splits the elements of a biflow record into its forward and
+// reverse directions, using the RFC 5103 reverse element mapping, so that the
+// counters of both directions can be accessed with the same element name.
+func MergeBiflow(record Record) (*BiflowRecord, error) {
+	biflow := &BiflowRecord{
+		Forward: make(map[string]InfoElementWithValue),
+		Reverse: make(map[string]InfoElementWithValue),
+	}
+	for _, ie := range record.GetOrderedElementList() {
+		element := ie.GetInfoElement()
+		if element.EnterpriseId != reverseEnterpriseID {
+			biflow.Forward[element.Name] = ie
+			continue
+		}
+		name, err := getForwardElementName(element.Name)
+		if err != nil {
+			return nil, err
+		}
+		biflow.Reverse[name] = ie
+	}
+	return biflow, nil
+}
+
+func getForwardElementName(reverseName string) (string, error) {
+	name := strings.TrimPrefix(reverseName, "reverse")
+	if name == reverseName || name == "" {
+		return "", fmt.Errorf("element with name %s is not a reverse element", reverseName)
+	}
+	return strings.ToLower(name[:1]) + name[1:], nil
+}
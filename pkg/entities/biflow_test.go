@@ -0,0 +1,49 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeBiflow(t *testing.T) {
+	record := newDecodedRecord(t, []*InfoElement{
+		NewInfoElement("sourceIPv4Address", 8, 18, 0, 4),
+		NewInfoElement("octetDeltaCount", 1, 4, 0, 8),
+		NewInfoElement("reverseOctetDeltaCount", 1, 4, reverseEnterpriseID, 8),
+	}, [][]byte{
+		{10, 0, 0, 1},
+		{0, 0, 0, 0, 0, 0, 0x03, 0xe8},
+		{0, 0, 0, 0, 0, 0, 0x01, 0xf4},
+	})
+	biflow, err := MergeBiflow(record)
+	require.NoError(t, err)
+	require.Contains(t, biflow.Forward, "octetDeltaCount")
+	require.Contains(t, biflow.Reverse, "octetDeltaCount")
+	assert.Equal(t, uint64(1000), biflow.Forward["octetDeltaCount"].GetUnsigned64Value())
+	assert.Equal(t, uint64(500), biflow.Reverse["octetDeltaCount"].GetUnsigned64Value())
+	assert.Contains(t, biflow.Forward, "sourceIPv4Address")
+	assert.Len(t, biflow.Forward, 2)
+	assert.Len(t, biflow.Reverse, 1)
+
+	record = newDecodedRecord(t, []*InfoElement{
+		NewInfoElement("octetDeltaCount", 1, 4, reverseEnterpriseID, 8),
+	}, [][]byte{{0, 0, 0, 0, 0, 0, 0, 1}})
+	_, err = MergeBiflow(record)
+	assert.Error(t, err)
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultCEFFieldMapping maps the names of common information elements to CEF
+// extension keys. The elements are matched by name regardless of their
+// enterprise, so that the keys also apply to elements which are not part of the
+// built-in registries, such as tlsServerName, dnsName and dnsQueryName, once
+// they are added with a custom registry.
+var DefaultCEFFieldMapping = map[string]string{
+	"sourceIPv4Address":        "src",
+	"destinationIPv4Address":   "dst",
+	"sourceIPv6Address":        "c6a2",
+	"destinationIPv6Address":   "c6a3",
+	"sourceTransportPort":      "spt",
+	"destinationTransportPort": "dpt",
+	"protocolIdentifier":       "proto",
+	"octetDeltaCount":          "bytes",
+	"tlsServerName":            "dhost",
+	"dnsName":                  "sourceDnsDomain",
+	"dnsQueryName":             "destinationDnsDomain",
+}
+
+// CEFOptions configures the CEF line created by Record.ToCEF.
+type CEFOptions struct {
+	DeviceVendor  string
+	DeviceProduct string
+	DeviceVersion string
+	SignatureID   string
+	Name          string
+	Severity      int
+	// FieldMapping maps information element names to CEF extension keys.
+	// Elements which are not in the mapping are not rendered. If it is nil,
+	// DefaultCEFFieldMapping is used.
+	FieldMapping map[string]string
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`)
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+)
+
+// ToCEF renders the record as a line in Common Event Format (CEF) version 0.
+// Extension fields follow the order of the elements in the record.
+func (b *baseRecord) ToCEF(options CEFOptions) string {
+	fieldMapping := options.FieldMapping
+	if fieldMapping == nil {
+		fieldMapping = DefaultCEFFieldMapping
+	}
+	var sb strings.Builder
+	sb.WriteString("CEF:0")
+	for _, field := range []string{options.DeviceVendor, options.DeviceProduct, options.DeviceVersion, options.SignatureID, options.Name} {
+		sb.WriteString("|")
+		sb.WriteString(cefHeaderEscaper.Replace(field))
+	}
+	sb.WriteString(fmt.Sprintf("|%d|", options.Severity))
+	first := true
+	for _, element := range b.orderedElementList {
+		if element == nil {
+			continue
+		}
+		key, exist := fieldMapping[element.GetName()]
+		if !exist {
+			continue
+		}
+		if !first {
+			sb.WriteString(" ")
+		}
+		first = false
+		sb.WriteString(key)
+		sb.WriteString("=")
+		sb.WriteString(cefExtensionEscaper.Replace(fmt.Sprint(getElementMapValue(element))))
+	}
+	return sb.String()
+}
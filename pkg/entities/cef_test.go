@@ -0,0 +1,56 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecord_ToCEF(t *testing.T) {
+	record := newDecodedRecord(t, []*InfoElement{
+		NewInfoElement("sourceIPv4Address", 8, Ipv4Address, 0, 4),
+		NewInfoElement("destinationIPv4Address", 12, Ipv4Address, 0, 4),
+		NewInfoElement("sourceTransportPort", 7, Unsigned16, 0, 2),
+		NewInfoElement("destinationTransportPort", 11, Unsigned16, 0, 2),
+		NewInfoElement("protocolIdentifier", 4, Unsigned8, 0, 1),
+		NewInfoElement("octetDeltaCount", 1, Unsigned64, 0, 8),
+		NewInfoElement("interfaceName", 82, String, 0, VariableLength),
+	}, [][]byte{
+		{10, 0, 0, 1},
+		{10, 0, 0, 2},
+		{0x1f, 0x90},
+		{0, 0x50},
+		{6},
+		{0, 0, 0, 0, 0, 0, 0x4, 0xd2},
+		[]byte("eth0=a"),
+	})
+	options := CEFOptions{
+		DeviceVendor:  "VMware",
+		DeviceProduct: "go-ipfix",
+		DeviceVersion: "1.0",
+		SignatureID:   "flow",
+		Name:          "IPFIX|flow",
+		Severity:      3,
+	}
+	assert.Equal(t, `CEF:0|VMware|go-ipfix|1.0|flow|IPFIX\|flow|3|src=10.0.0.1 dst=10.0.0.2 spt=8080 dpt=80 proto=6 bytes=1234`, record.ToCEF(options))
+
+	options.FieldMapping = map[string]string{
+		"sourceIPv4Address": "src",
+		"interfaceName":     "deviceInboundInterface",
+	}
+	assert.Equal(t, `CEF:0|VMware|go-ipfix|1.0|flow|IPFIX\|flow|3|src=10.0.0.1 deviceInboundInterface=eth0\=a`, record.ToCEF(options))
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"fmt"
+	"time"
+)
+
+// ntpEpochOffset is the number of seconds between the NTP epoch (1900) and the
+// Unix epoch (1970).
+const ntpEpochOffset = 2208988800
+
+// TimeToIEDataTypeValue converts t to the value of the given dateTime data
+// type: a uint32 for DateTimeSeconds, and a uint64 for the other types.
+// DateTimeMicroseconds and DateTimeNanoseconds use the NTP timestamp format of
+// RFC7011 section 6.1.9 and 6.1.10, and the 11 least significant bits of the
+// fraction are zero for DateTimeMicroseconds. It returns nil for other data
+// types.
+func TimeToIEDataTypeValue(dataType IEDataType, t time.Time) interface{} {
+	switch dataType {
+	case DateTimeSeconds:
+		return uint32(t.Unix())
+	case DateTimeMilliseconds:
+		return uint64(t.UnixMilli())
+	case DateTimeMicroseconds, DateTimeNanoseconds:
+		seconds := uint64(t.Unix() + ntpEpochOffset)
+		fraction := (uint64(t.Nanosecond()) << 32) / uint64(time.Second)
+		if dataType == DateTimeMicroseconds {
+			fraction &^= 0x7ff
+		}
+		return seconds<<32 | fraction
+	default:
+		return nil
+	}
+}
+
+// IEDataTypeValueToTime converts the value of the given dateTime data type, as
+// returned by GetUnsigned32Value or GetUnsigned64Value, to a time.Time.
+func IEDataTypeValueToTime(dataType IEDataType, value uint64) (time.Time, error) {
+	switch dataType {
+	case DateTimeSeconds:
+		return time.Unix(int64(value), 0), nil
+	case DateTimeMilliseconds:
+		return time.UnixMilli(int64(value)), nil
+	case DateTimeMicroseconds, DateTimeNanoseconds:
+		seconds := int64(value>>32) - ntpEpochOffset
+		nanoseconds := ((value & 0xffffffff) * uint64(time.Second)) >> 32
+		return time.Unix(seconds, int64(nanoseconds)), nil
+	default:
+		return time.Time{}, fmt.Errorf("data type %d is not a dateTime type", dataType)
+	}
+}
+
+// NewDateTimeInfoElement creates the info element with value t converted to
+// the dateTime data type of the element.
+func NewDateTimeInfoElement(element *InfoElement, t time.Time) (InfoElementWithValue, error) {
+	switch element.DataType {
+	case DateTimeSeconds:
+		return NewDateTimeSecondsInfoElement(element, TimeToIEDataTypeValue(element.DataType, t).(uint32)), nil
+	case DateTimeMilliseconds:
+		return NewDateTimeMillisecondsInfoElement(element, TimeToIEDataTypeValue(element.DataType, t).(uint64)), nil
+	case DateTimeMicroseconds:
+		return NewDateTimeMicrosecondsInfoElement(element, TimeToIEDataTypeValue(element.DataType, t).(uint64)), nil
+	case DateTimeNanoseconds:
+		return NewDateTimeNanosecondsInfoElement(element, TimeToIEDataTypeValue(element.DataType, t).(uint64)), nil
+	default:
+		return nil, fmt.Errorf("element %s is not of dateTime type", element.Name)
+	}
+}
+
+// GetTimeValue returns the value of an element of dateTime type as a
+// time.Time, with the precision of its data type.
+func GetTimeValue(element InfoElementWithValue) (time.Time, error) {
+	switch element.GetDataType() {
+	case DateTimeSeconds:
+		return IEDataTypeValueToTime(DateTimeSeconds, uint64(element.GetUnsigned32Value()))
+	case DateTimeMilliseconds, DateTimeMicroseconds, DateTimeNanoseconds:
+		return IEDataTypeValueToTime(element.GetDataType(), element.GetUnsigned64Value())
+	default:
+		return time.Time{}, fmt.Errorf("element %s is not of dateTime type", element.GetName())
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeTimeToDateTimeElements(t *testing.T) {
+	ts := time.Date(2026, 10, 15, 8, 30, 15, 123456789, time.UTC)
+	for _, tc := range []struct {
+		element      *InfoElement
+		expectedTime time.Time
+	}{
+		{NewInfoElement("flowStartSeconds", 150, DateTimeSeconds, 0, 4), ts.Truncate(time.Second)},
+		{NewInfoElement("flowStartMilliseconds", 152, DateTimeMilliseconds, 0, 8), ts.Truncate(time.Millisecond)},
+		{NewInfoElement("flowStartMicroseconds", 154, DateTimeMicroseconds, 0, 8), ts.Truncate(time.Microsecond)},
+		{NewInfoElement("flowStartNanoseconds", 156, DateTimeNanoseconds, 0, 8), ts},
+	} {
+		t.Run(tc.element.Name, func(t *testing.T) {
+			encoded, err := EncodeToIEDataType(tc.element.DataType, ts)
+			require.NoError(t, err)
+			require.Len(t, encoded, int(tc.element.Len))
+			decoded, err := DecodeAndCreateInfoElementWithValue(tc.element, encoded)
+			require.NoError(t, err)
+			var value uint64
+			if tc.element.DataType == DateTimeSeconds {
+				value = uint64(decoded.GetUnsigned32Value())
+			} else {
+				value = decoded.GetUnsigned64Value()
+			}
+			decodedTime, err := IEDataTypeValueToTime(tc.element.DataType, value)
+			require.NoError(t, err)
+			// The NTP fraction has a resolution of about 0.23ns, and the 11 least
+			// significant bits are cleared for microseconds.
+			assert.WithinDuration(t, tc.expectedTime, decodedTime, time.Microsecond)
+			assert.False(t, decodedTime.After(ts))
+
+			element, err := NewDateTimeInfoElement(tc.element, ts)
+			require.NoError(t, err)
+			buffer := make([]byte, element.GetLength())
+			require.NoError(t, encodeInfoElementValueToBuff(element, buffer, 0))
+			assert.Equal(t, encoded, buffer)
+		})
+	}
+	_, err := NewDateTimeInfoElement(NewInfoElement("octetDeltaCount", 1, Unsigned64, 0, 8), ts)
+	assert.Error(t, err)
+}
+
+func TestEncodeTimeToFlowStartMilliseconds(t *testing.T) {
+	element := NewInfoElement("flowStartMilliseconds", 152, DateTimeMilliseconds, 0, 8)
+	ts := time.UnixMilli(1603955730123)
+	encoded, err := EncodeToIEDataType(element.DataType, ts)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x0, 0x0, 0x1, 0x75, 0x73, 0x36, 0x26, 0xcb}, encoded)
+	decoded, err := DecodeAndCreateInfoElementWithValue(element, encoded)
+	require.NoError(t, err)
+	decodedTime, err := IEDataTypeValueToTime(element.DataType, decoded.GetUnsigned64Value())
+	require.NoError(t, err)
+	assert.True(t, ts.Equal(decodedTime))
+}
+
+func TestGetTimeValue(t *testing.T) {
+	ts := time.Date(2026, 10, 15, 8, 30, 15, 0, time.UTC)
+	element, err := NewDateTimeInfoElement(NewInfoElement("observationTimeSeconds", 322, DateTimeSeconds, 0, 4), ts)
+	require.NoError(t, err)
+	value, err := GetTimeValue(element)
+	require.NoError(t, err)
+	assert.True(t, ts.Equal(value))
+	_, err = GetTimeValue(NewUnsigned32InfoElement(NewInfoElement("samplingInterval", 34, Unsigned32, 0, 4), 1))
+	assert.Error(t, err)
+}
+
+func TestDecodeDateTimeAndBooleanElements(t *testing.T) {
+	// 2026-10-15T08:30:15.5Z, encoded as per RFC7011 section 6.1.7 to 6.1.10:
+	// seconds and milliseconds since the Unix epoch, and NTP timestamps with
+	// seconds since the NTP epoch (1900) and a 32-bit fraction.
+	ts := time.Date(2026, 10, 15, 8, 30, 15, 500000000, time.UTC)
+	for _, tc := range []struct {
+		dataType IEDataType
+		value    []byte
+		expected time.Time
+	}{
+		{DateTimeSeconds, []byte{106, 208, 143, 23}, ts.Truncate(time.Second)},
+		{DateTimeMilliseconds, []byte{0, 0, 1, 161, 62, 174, 243, 204}, ts},
+		{DateTimeMicroseconds, []byte{238, 123, 13, 151, 0x80, 0, 0, 0}, ts},
+		{DateTimeNanoseconds, []byte{238, 123, 13, 151, 0x80, 0, 0, 0}, ts},
+	} {
+		element, err := DecodeAndCreateInfoElementWithValue(NewInfoElement("dateTimeElement", 1, tc.dataType, 9999, uint16(len(tc.value))), tc.value)
+		require.NoError(t, err)
+		value, err := GetTimeValue(element)
+		require.NoError(t, err)
+		assert.True(t, tc.expected.Equal(value), "data type %d: expected %v, got %v", tc.dataType, tc.expected, value)
+	}
+
+	// The boolean values are 1 for true and 2 for false.
+	booleanElement := NewInfoElement("isMulticast", 206, Boolean, 0, 1)
+	element, err := DecodeAndCreateInfoElementWithValue(booleanElement, []byte{1})
+	require.NoError(t, err)
+	assert.True(t, element.GetBooleanValue())
+	element, err = DecodeAndCreateInfoElementWithValue(booleanElement, []byte{2})
+	require.NoError(t, err)
+	assert.False(t, element.GetBooleanValue())
+}
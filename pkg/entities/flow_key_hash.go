@@ -0,0 +1,95 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+)
+
+// FlowKeyHash returns a 64-bit FNV-1a hash of the values of the given key
+// elements, in the given order. Values are hashed in a canonical form which
+// does not depend on the encoded length of the elements: all unsigned and
+// signed integers are hashed as 64-bit values, float32 values as float64, and
+// IPv4 addresses in their 4-byte form. Missing elements are hashed as absent,
+// so that records with and without an element do not collide. The hash is
+// stable across processes and can be used as a cache or dedup key.
+func (b *baseRecord) FlowKeyHash(keyElements []string) uint64 {
+	h := fnv.New64a()
+	for _, name := range keyElements {
+		element, _, exist := b.GetInfoElementWithValue(name)
+		if !exist {
+			h.Write([]byte{0})
+			continue
+		}
+		h.Write([]byte{1})
+		writeCanonicalValue(h, element)
+	}
+	return h.Sum64()
+}
+
+func writeCanonicalValue(h hash.Hash64, element InfoElementWithValue) {
+	buf := make([]byte, 8)
+	writeUint64 := func(v uint64) {
+		binary.BigEndian.PutUint64(buf, v)
+		h.Write(buf)
+	}
+	writeBytes := func(v []byte) {
+		writeUint64(uint64(len(v)))
+		h.Write(v)
+	}
+	switch element.GetDataType() {
+	case Unsigned8:
+		writeUint64(uint64(element.GetUnsigned8Value()))
+	case Unsigned16:
+		writeUint64(uint64(element.GetUnsigned16Value()))
+	case Unsigned32, DateTimeSeconds:
+		writeUint64(uint64(element.GetUnsigned32Value()))
+	case Unsigned64, DateTimeMilliseconds, DateTimeMicroseconds, DateTimeNanoseconds:
+		writeUint64(element.GetUnsigned64Value())
+	case Signed8:
+		writeUint64(uint64(element.GetSigned8Value()))
+	case Signed16:
+		writeUint64(uint64(element.GetSigned16Value()))
+	case Signed32:
+		writeUint64(uint64(element.GetSigned32Value()))
+	case Signed64:
+		writeUint64(uint64(element.GetSigned64Value()))
+	case Float32:
+		writeUint64(math.Float64bits(float64(element.GetFloat32Value())))
+	case Float64:
+		writeUint64(math.Float64bits(element.GetFloat64Value()))
+	case Boolean:
+		if element.GetBooleanValue() {
+			writeUint64(1)
+		} else {
+			writeUint64(0)
+		}
+	case MacAddress:
+		writeBytes(element.GetMacAddressValue())
+	case Ipv4Address, Ipv6Address:
+		ip := element.GetIPAddressValue()
+		if ipv4 := ip.To4(); ipv4 != nil {
+			ip = ipv4
+		}
+		writeBytes(ip)
+	case String:
+		writeBytes([]byte(element.GetStringValue()))
+	case OctetArray:
+		writeBytes(element.GetOctetArrayValue())
+	}
+}
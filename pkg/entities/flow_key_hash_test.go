@@ -0,0 +1,60 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlowKeyHash(t *testing.T) {
+	srcIPElement := NewInfoElement("sourceIPv4Address", 8, Ipv4Address, 0, 4)
+	srcPortElement := NewInfoElement("sourceTransportPort", 7, Unsigned16, 0, 2)
+	protoElement := NewInfoElement("protocolIdentifier", 4, Unsigned8, 0, 1)
+	packetsElement := NewInfoElement("packetDeltaCount", 2, Unsigned64, 0, 8)
+	keyElements := []string{"sourceIPv4Address", "sourceTransportPort", "protocolIdentifier"}
+
+	decodedRecord := newDecodedRecord(t, []*InfoElement{srcIPElement, srcPortElement, protoElement, packetsElement}, [][]byte{
+		{10, 0, 0, 1},
+		{0, 80},
+		{6},
+		{0, 0, 0, 0, 0, 0, 0, 5},
+	})
+	// Same key values in a different order with a 16-byte IPv4 address and a
+	// different non-key value.
+	record := NewDataRecord(uniqueTemplateID, 4, 0, false)
+	for _, ie := range []InfoElementWithValue{
+		NewUnsigned64InfoElement(packetsElement, 10),
+		NewUnsigned8InfoElement(protoElement, 6),
+		NewUnsigned16InfoElement(srcPortElement, 80),
+		NewIPAddressInfoElement(srcIPElement, net.ParseIP("10.0.0.1")),
+	} {
+		require.NoError(t, record.AddInfoElement(ie))
+	}
+	assert.Equal(t, decodedRecord.FlowKeyHash(keyElements), record.FlowKeyHash(keyElements))
+	assert.NotEqual(t, decodedRecord.FlowKeyHash(append(keyElements, "packetDeltaCount")), record.FlowKeyHash(append(keyElements, "packetDeltaCount")))
+
+	otherRecord := newDecodedRecord(t, []*InfoElement{srcIPElement, srcPortElement, protoElement}, [][]byte{
+		{10, 0, 0, 1},
+		{0, 81},
+		{6},
+	})
+	assert.NotEqual(t, decodedRecord.FlowKeyHash(keyElements), otherRecord.FlowKeyHash(keyElements))
+	// Missing elements are not equivalent to zero values.
+	assert.NotEqual(t, otherRecord.FlowKeyHash([]string{"destinationTransportPort"}), otherRecord.FlowKeyHash(nil))
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"time"
 )
 
 type IEDataType uint8
@@ -91,6 +92,12 @@ type InfoElement struct {
 	EnterpriseId uint32
 	// Length of IE
 	Len uint16
+	// Units of the IE value as listed in the IANA registry, e.g. "octets";
+	// empty if the IE has no units or they are unknown
+	Units string
+	// Data type semantics follows the specification in RFC7012(section 3.2),
+	// e.g. "deltaCounter"; empty if unknown
+	Semantics string
 }
 
 func NewInfoElement(name string, ieID uint16, ieType IEDataType, entID uint32, len uint16) *InfoElement {
@@ -191,22 +198,76 @@ func decodeToIEDataType(dataType IEDataType, val interface{}) (interface{}, erro
 	case DateTimeSeconds:
 		v := binary.BigEndian.Uint32(value)
 		return v, nil
-	case DateTimeMilliseconds:
+	case DateTimeMilliseconds, DateTimeMicroseconds, DateTimeNanoseconds:
 		v := binary.BigEndian.Uint64(value)
 		return v, nil
-	case DateTimeMicroseconds, DateTimeNanoseconds:
-		return nil, fmt.Errorf("API does not support micro and nano seconds types yet")
 	case MacAddress:
 		return net.HardwareAddr(value), nil
 	case Ipv4Address, Ipv6Address:
 		return net.IP(value), nil
 	case String:
 		return string(value), nil
+	case OctetArray:
+		return value, nil
 	default:
 		return nil, fmt.Errorf("API supports only valid information elements with datatypes given in RFC7011")
 	}
 }
 
+// IsReducedSizeEncodingSupported returns whether the data type can be encoded
+// with fewer octets than its abstract length (RFC 7011 section 6.2). When an
+// information element of such a type is declared in a template with a reduced
+// length, DecodeAndCreateInfoElementWithValue left-pads the value accordingly,
+// or decodes it as a float32 for a float64 reduced to 4 octets.
+func IsReducedSizeEncodingSupported(dataType IEDataType) bool {
+	switch dataType {
+	case Unsigned16, Unsigned32, Unsigned64, Signed16, Signed32, Signed64, Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// ValidateAddressLength returns an error if the information element has an
+// address data type (ipv4Address, ipv6Address or macAddress) and the given
+// length is not exactly 4, 16 or 6 bytes respectively. Address types do not
+// support reduced-size encoding, so any other length is malformed.
+func ValidateAddressLength(element *InfoElement, length int) error {
+	switch element.DataType {
+	case Ipv4Address, Ipv6Address, MacAddress:
+		if expected := int(InfoElementLength[element.DataType]); length != expected {
+			return fmt.Errorf("invalid length %d for address element %s: expected %d bytes", length, element.Name, expected)
+		}
+	}
+	return nil
+}
+
+// padReducedSizeValue left-pads the value with zeros up to the given length
+// when the value was encoded with reduced size.
+func padReducedSizeValue(value []byte, length int) []byte {
+	if len(value) >= length {
+		return value
+	}
+	padded := make([]byte, length)
+	copy(padded[length-len(value):], value)
+	return padded
+}
+
+// signExtendReducedSizeValue left-pads the value with its sign bit up to the
+// given length when the value was encoded with reduced size, so that negative
+// values are preserved.
+func signExtendReducedSizeValue(value []byte, length int) []byte {
+	if len(value) >= length || len(value) == 0 || value[0]&0x80 == 0 {
+		return padReducedSizeValue(value, length)
+	}
+	padded := make([]byte, length)
+	for i := 0; i < length-len(value); i++ {
+		padded[i] = 0xff
+	}
+	copy(padded[length-len(value):], value)
+	return padded
+}
+
 // DecodeAndCreateInfoElementWithValue takes in the info element and its value in bytes, and
 // returns appropriate InfoElementWithValue.
 func DecodeAndCreateInfoElementWithValue(element *InfoElement, value []byte) (InfoElementWithValue, error) {
@@ -224,7 +285,7 @@ func DecodeAndCreateInfoElementWithValue(element *InfoElement, value []byte) (In
 		if value == nil {
 			val = 0
 		} else {
-			val = binary.BigEndian.Uint16(value)
+			val = binary.BigEndian.Uint16(padReducedSizeValue(value, 2))
 		}
 		return NewUnsigned16InfoElement(element, val), nil
 	case Unsigned32:
@@ -232,7 +293,7 @@ func DecodeAndCreateInfoElementWithValue(element *InfoElement, value []byte) (In
 		if value == nil {
 			val = 0
 		} else {
-			val = binary.BigEndian.Uint32(value)
+			val = binary.BigEndian.Uint32(padReducedSizeValue(value, 4))
 		}
 		return NewUnsigned32InfoElement(element, val), nil
 	case Unsigned64:
@@ -240,7 +301,7 @@ func DecodeAndCreateInfoElementWithValue(element *InfoElement, value []byte) (In
 		if value == nil {
 			val = 0
 		} else {
-			val = binary.BigEndian.Uint64(value)
+			val = binary.BigEndian.Uint64(padReducedSizeValue(value, 8))
 		}
 		return NewUnsigned64InfoElement(element, val), nil
 	case Signed8:
@@ -256,7 +317,7 @@ func DecodeAndCreateInfoElementWithValue(element *InfoElement, value []byte) (In
 		if value == nil {
 			val = 0
 		} else {
-			val = int16(binary.BigEndian.Uint16(value))
+			val = int16(binary.BigEndian.Uint16(signExtendReducedSizeValue(value, 2)))
 		}
 		return NewSigned16InfoElement(element, val), nil
 	case Signed32:
@@ -264,30 +325,39 @@ func DecodeAndCreateInfoElementWithValue(element *InfoElement, value []byte) (In
 		if value == nil {
 			val = 0
 		} else {
-			val = int32(binary.BigEndian.Uint32(value))
+			val = int32(binary.BigEndian.Uint32(signExtendReducedSizeValue(value, 4)))
 		}
 		return NewSigned32InfoElement(element, val), nil
 	case Signed64:
 		var val int64
 		if value == nil {
 			val = 0
+		} else {
+			val = int64(binary.BigEndian.Uint64(signExtendReducedSizeValue(value, 8)))
 		}
-		val = int64(binary.BigEndian.Uint64(value))
 		return NewSigned64InfoElement(element, val), nil
 	case Float32:
 		var val float32
 		if value == nil {
 			val = 0
-		} else {
+		} else if len(value) == 4 {
 			val = math.Float32frombits(binary.BigEndian.Uint32(value))
+		} else {
+			return nil, fmt.Errorf("invalid length %d for float32 element %s: expected 4 bytes", len(value), element.Name)
 		}
 		return NewFloat32InfoElement(element, val), nil
 	case Float64:
 		var val float64
-		if value == nil {
+		switch len(value) {
+		case 0:
 			val = 0
-		} else {
+		case 8:
 			val = math.Float64frombits(binary.BigEndian.Uint64(value))
+		case 4:
+			// float64 reduced to float32 (RFC 7011 section 6.2)
+			val = float64(math.Float32frombits(binary.BigEndian.Uint32(value)))
+		default:
+			return nil, fmt.Errorf("invalid length %d for float64 element %s: expected 8 or 4 bytes", len(value), element.Name)
 		}
 		return NewFloat64InfoElement(element, val), nil
 	case Boolean:
@@ -316,10 +386,25 @@ func DecodeAndCreateInfoElementWithValue(element *InfoElement, value []byte) (In
 		}
 		return NewDateTimeMillisecondsInfoElement(element, val), nil
 	case DateTimeMicroseconds, DateTimeNanoseconds:
-		return nil, fmt.Errorf("API does not support micro and nano seconds types yet")
-	case MacAddress:
-		return NewMacAddressInfoElement(element, value), nil
-	case Ipv4Address, Ipv6Address:
+		var val uint64
+		if value != nil {
+			val = binary.BigEndian.Uint64(value)
+		}
+		if element.DataType == DateTimeMicroseconds {
+			return NewDateTimeMicrosecondsInfoElement(element, val), nil
+		}
+		return NewDateTimeNanosecondsInfoElement(element, val), nil
+	case MacAddress, Ipv4Address, Ipv6Address:
+		// IPv4 addresses in their 16-byte form, as returned by net.ParseIP, are accepted.
+		isIPv4In16Bytes := element.DataType == Ipv4Address && len(value) == net.IPv6len && net.IP(value).To4() != nil
+		if value != nil && !isIPv4In16Bytes {
+			if err := ValidateAddressLength(element, len(value)); err != nil {
+				return nil, err
+			}
+		}
+		if element.DataType == MacAddress {
+			return NewMacAddressInfoElement(element, value), nil
+		}
 		return NewIPAddressInfoElement(element, value), nil
 	case String:
 		var val string
@@ -329,6 +414,21 @@ func DecodeAndCreateInfoElementWithValue(element *InfoElement, value []byte) (In
 			val = string(value)
 		}
 		return NewStringInfoElement(element, val), nil
+	case OctetArray:
+		return NewOctetArrayInfoElement(element, value), nil
+	case BasicList, SubTemplateList, SubTemplateMultiList:
+		// Structured data requires resolving the elements and templates used in
+		// the lists, see StructuredDataDecoder.
+		if value != nil {
+			return nil, fmt.Errorf("structured data element %s should be decoded with StructuredDataDecoder", element.Name)
+		}
+		if element.DataType == BasicList {
+			return NewBasicListInfoElement(element, nil), nil
+		}
+		if element.DataType == SubTemplateList {
+			return NewSubTemplateListInfoElement(element, nil), nil
+		}
+		return NewSubTemplateMultiListInfoElement(element, nil), nil
 	default:
 		return nil, fmt.Errorf("API supports only valid information elements with datatypes given in RFC7011")
 	}
@@ -428,6 +528,9 @@ func EncodeToIEDataType(dataType IEDataType, val interface{}) ([]byte, error) {
 		}
 		return b, nil
 	case DateTimeSeconds:
+		if t, ok := val.(time.Time); ok {
+			val = TimeToIEDataTypeValue(dataType, t)
+		}
 		v, ok := val.(uint32)
 		if !ok {
 			return nil, fmt.Errorf("val argument %v is not of type uint32", val)
@@ -435,7 +538,11 @@ func EncodeToIEDataType(dataType IEDataType, val interface{}) ([]byte, error) {
 		b := make([]byte, 4)
 		binary.BigEndian.PutUint32(b, v)
 		return b, nil
-	case DateTimeMilliseconds:
+	case DateTimeMilliseconds, DateTimeMicroseconds, DateTimeNanoseconds:
+		// Microseconds and nanoseconds are encoded in the NTP timestamp format.
+		if t, ok := val.(time.Time); ok {
+			val = TimeToIEDataTypeValue(dataType, t)
+		}
 		v, ok := val.(uint64)
 		if !ok {
 			return nil, fmt.Errorf("val argument %v is not of type uint64", val)
@@ -443,10 +550,6 @@ func EncodeToIEDataType(dataType IEDataType, val interface{}) ([]byte, error) {
 		b := make([]byte, 8)
 		binary.BigEndian.PutUint64(b, v)
 		return b, nil
-		// Currently only supporting seconds and milliseconds
-	case DateTimeMicroseconds, DateTimeNanoseconds:
-		// TODO: RFC 7011 has extra spec for these data types. Need to follow that
-		return nil, fmt.Errorf("API does not support micro and nano seconds types yet")
 	case MacAddress:
 		// Expects net.Hardware type
 		v, ok := val.(net.HardwareAddr)
@@ -495,6 +598,25 @@ func EncodeToIEDataType(dataType IEDataType, val interface{}) ([]byte, error) {
 			return nil, fmt.Errorf("provided String value is too long and cannot be encoded: len=%d, maxlen=%d", len(v), math.MaxUint16)
 		}
 		return encodedBytes, nil
+	case OctetArray:
+		v, ok := val.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("val argument %v is not of type []byte for this element", val)
+		}
+		var encodedBytes []byte
+		if len(v) < 255 {
+			encodedBytes = make([]byte, len(v)+1)
+			encodedBytes[0] = uint8(len(v))
+			copy(encodedBytes[1:], v)
+		} else if len(v) <= math.MaxUint16 {
+			encodedBytes = make([]byte, len(v)+3)
+			encodedBytes[0] = byte(255)
+			binary.BigEndian.PutUint16(encodedBytes[1:3], uint16(len(v)))
+			copy(encodedBytes[3:], v)
+		} else {
+			return nil, fmt.Errorf("provided OctetArray value is too long and cannot be encoded: len=%d, maxlen=%d", len(v), math.MaxUint16)
+		}
+		return encodedBytes, nil
 	}
 	return nil, fmt.Errorf("API supports only valid information elements with datatypes given in RFC7011")
 }
@@ -524,7 +646,12 @@ func encodeInfoElementValueToBuff(element InfoElementWithValue, buffer []byte, i
 	case Float32:
 		binary.BigEndian.PutUint32(buffer[index:], math.Float32bits(element.GetFloat32Value()))
 	case Float64:
-		binary.BigEndian.PutUint64(buffer[index:], math.Float64bits(element.GetFloat64Value()))
+		if element.GetLength() == 4 {
+			// float64 reduced to float32 (RFC 7011 section 6.2)
+			binary.BigEndian.PutUint32(buffer[index:], math.Float32bits(float32(element.GetFloat64Value())))
+		} else {
+			binary.BigEndian.PutUint64(buffer[index:], math.Float64bits(element.GetFloat64Value()))
+		}
 	case Boolean:
 		// Following boolean spec from RFC7011
 		indicator := byte(int8(1))
@@ -534,12 +661,8 @@ func encodeInfoElementValueToBuff(element InfoElementWithValue, buffer []byte, i
 		copy(buffer[index:index+1], []byte{indicator})
 	case DateTimeSeconds:
 		binary.BigEndian.PutUint32(buffer[index:], element.GetUnsigned32Value())
-	case DateTimeMilliseconds:
+	case DateTimeMilliseconds, DateTimeMicroseconds, DateTimeNanoseconds:
 		binary.BigEndian.PutUint64(buffer[index:], element.GetUnsigned64Value())
-		// Currently only supporting seconds and milliseconds
-	case DateTimeMicroseconds, DateTimeNanoseconds:
-		// TODO: RFC 7011 has extra spec for these data types. Need to follow that
-		return fmt.Errorf("API does not support micro and nano seconds types yet")
 	case MacAddress:
 		copy(buffer[index:], element.GetMacAddressValue())
 	case Ipv4Address:
@@ -568,6 +691,50 @@ func encodeInfoElementValueToBuff(element InfoElementWithValue, buffer []byte, i
 		} else {
 			return fmt.Errorf("provided String value is too long and cannot be encoded: len=%d, maxlen=%d", len(v), math.MaxUint16)
 		}
+	case OctetArray:
+		v := element.GetOctetArrayValue()
+		if element.GetInfoElement().Len != VariableLength {
+			if len(v) != int(element.GetInfoElement().Len) {
+				return fmt.Errorf("provided OctetArray value length %d does not match the element length %d", len(v), element.GetInfoElement().Len)
+			}
+			copy(buffer[index:], v)
+		} else if len(v) < 255 {
+			buffer[index] = uint8(len(v))
+			copy(buffer[index+1:], v)
+		} else if len(v) <= math.MaxUint16 {
+			buffer[index] = byte(255) // marker byte for long octet arrays
+			binary.BigEndian.PutUint16(buffer[index+1:index+3], uint16(len(v)))
+			copy(buffer[index+3:], v)
+		} else {
+			return fmt.Errorf("provided OctetArray value is too long and cannot be encoded: len=%d, maxlen=%d", len(v), math.MaxUint16)
+		}
+	case SubTemplateList:
+		v := element.GetSubTemplateListValue()
+		if v == nil {
+			return fmt.Errorf("provided subTemplateList element %s has no value", element.GetName())
+		}
+		length := v.getEncodedLength()
+		if length < 255 {
+			buffer[index] = uint8(length)
+			index++
+		} else if length <= math.MaxUint16 {
+			buffer[index] = byte(255) // marker byte for long lists
+			binary.BigEndian.PutUint16(buffer[index+1:index+3], uint16(length))
+			index += 3
+		} else {
+			return fmt.Errorf("provided subTemplateList value is too long and cannot be encoded: len=%d, maxlen=%d", length, math.MaxUint16)
+		}
+		buffer[index] = v.Semantic
+		binary.BigEndian.PutUint16(buffer[index+1:index+3], v.TemplateID)
+		index += 3
+		for _, record := range v.Records {
+			for _, field := range record {
+				if err := encodeInfoElementValueToBuff(field, buffer, index); err != nil {
+					return err
+				}
+				index += field.GetLength()
+			}
+		}
 	default:
 		return fmt.Errorf("API supports only valid information elements with datatypes given in RFC7011")
 	}
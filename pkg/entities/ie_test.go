@@ -66,15 +66,115 @@ func TestEncodeToIEDataType(t *testing.T) {
 	buff, err := EncodeToIEDataType(String, s)
 	assert.Nil(t, err)
 	assert.Equal(t, []byte{0x4, 0x54, 0x65, 0x73, 0x74}, buff)
+	buff, err = EncodeToIEDataType(OctetArray, []byte{0xab, 0xcd})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x2, 0xab, 0xcd}, buff)
 }
 
 func TestNewInfoElementWithValue(t *testing.T) {
 	ip := net.ParseIP("10.0.0.1")
-	element := NewIPAddressInfoElement(&InfoElement{"sourceIPv4Address", 8, 18, 0, 4}, ip)
+	element := NewIPAddressInfoElement(NewInfoElement("sourceIPv4Address", 8, 18, 0, 4), ip)
 	assert.Equal(t, element.GetInfoElement().Name, "sourceIPv4Address")
 	assert.Equal(t, element.GetIPAddressValue(), ip)
 }
 
+func TestDecodeAndCreateInfoElementWithValue_ReducedSize(t *testing.T) {
+	assert.True(t, IsReducedSizeEncodingSupported(Unsigned32))
+	assert.False(t, IsReducedSizeEncodingSupported(Ipv4Address))
+	element := NewInfoElement("bgpSourceAsNumber", 16, Unsigned32, 0, 2)
+	ie, err := DecodeAndCreateInfoElementWithValue(element, []byte{0xfd, 0xe8})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(65000), ie.GetUnsigned32Value())
+	element = NewInfoElement("octetDeltaCount", 1, Unsigned64, 0, 4)
+	ie, err = DecodeAndCreateInfoElementWithValue(element, []byte{0, 0, 0x4, 0xd2})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1234), ie.GetUnsigned64Value())
+	element = NewInfoElement("octetDeltaCount", 1, Unsigned64, 0, 1)
+	ie, err = DecodeAndCreateInfoElementWithValue(element, []byte{0xff})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(255), ie.GetUnsigned64Value())
+	element = NewInfoElement("transportOctetDeltaCount", 401, Unsigned16, 0, 1)
+	ie, err = DecodeAndCreateInfoElementWithValue(element, []byte{0x80})
+	require.NoError(t, err)
+	assert.Equal(t, uint16(128), ie.GetUnsigned16Value())
+
+	// Signed values are sign-extended.
+	assert.True(t, IsReducedSizeEncodingSupported(Signed64))
+	assert.False(t, IsReducedSizeEncodingSupported(Signed8))
+	for _, tc := range []struct {
+		dataType IEDataType
+		value    []byte
+		expected int64
+	}{
+		{Signed16, []byte{0x7f}, 127},
+		{Signed16, []byte{0xfe}, -2},
+		{Signed32, []byte{0x01, 0x00}, 256},
+		{Signed32, []byte{0xff, 0x00}, -256},
+		{Signed64, []byte{0x00, 0x00, 0x04, 0xd2}, 1234},
+		{Signed64, []byte{0xff, 0xff, 0xfb, 0x2e}, -1234},
+	} {
+		element = NewInfoElement("signedElement", 1, tc.dataType, 9999, uint16(len(tc.value)))
+		ie, err = DecodeAndCreateInfoElementWithValue(element, tc.value)
+		require.NoError(t, err)
+		var value int64
+		switch tc.dataType {
+		case Signed16:
+			value = int64(ie.GetSigned16Value())
+		case Signed32:
+			value = int64(ie.GetSigned32Value())
+		case Signed64:
+			value = ie.GetSigned64Value()
+		}
+		assert.Equal(t, tc.expected, value, "data type %d with value %v", tc.dataType, tc.value)
+	}
+	ie, err = DecodeAndCreateInfoElementWithValue(NewInfoElement("signedElement", 1, Signed64, 9999, 8), nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), ie.GetSigned64Value())
+
+	// A float64 may be reduced to a float32.
+	assert.True(t, IsReducedSizeEncodingSupported(Float64))
+	assert.False(t, IsReducedSizeEncodingSupported(Float32))
+	element = NewInfoElement("samplingProbability", 311, Float64, 0, 8)
+	ie, err = DecodeAndCreateInfoElementWithValue(element, []byte{0x3f, 0xd5, 0x55, 0x55, 0x55, 0x55, 0x55, 0x55})
+	require.NoError(t, err)
+	assert.Equal(t, 1.0/3, ie.GetFloat64Value())
+	element = NewInfoElement("samplingProbability", 311, Float64, 0, 4)
+	ie, err = DecodeAndCreateInfoElementWithValue(element, []byte{0x3e, 0x80, 0, 0})
+	require.NoError(t, err)
+	assert.Equal(t, 0.25, ie.GetFloat64Value())
+	buffer := make([]byte, 4)
+	require.NoError(t, encodeInfoElementValueToBuff(ie, buffer, 0))
+	assert.Equal(t, []byte{0x3e, 0x80, 0, 0}, buffer)
+	_, err = DecodeAndCreateInfoElementWithValue(NewInfoElement("samplingProbability", 311, Float64, 0, 2), []byte{0x3e, 0x80})
+	assert.ErrorContains(t, err, "invalid length 2 for float64 element samplingProbability")
+	ie, err = DecodeAndCreateInfoElementWithValue(NewInfoElement("float32Element", 1, Float32, 9999, 4), []byte{0xbf, 0xc0, 0, 0})
+	require.NoError(t, err)
+	assert.Equal(t, float32(-1.5), ie.GetFloat32Value())
+}
+
+func TestDecodeAndCreateInfoElementWithValue_AddressLength(t *testing.T) {
+	for _, tc := range []struct {
+		dataType IEDataType
+		valid    []byte
+		invalid  []byte
+	}{
+		{Ipv4Address, []byte{1, 2, 3, 4}, []byte{1, 2, 3}},
+		{Ipv6Address, net.ParseIP("2001:db8::1"), []byte{1, 2, 3, 4}},
+		{MacAddress, []byte{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}, []byte{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0}},
+	} {
+		element := NewInfoElement("addressElement", 1, tc.dataType, 9999, InfoElementLength[tc.dataType])
+		_, err := DecodeAndCreateInfoElementWithValue(element, tc.valid)
+		assert.NoError(t, err, "data type %d", tc.dataType)
+		_, err = DecodeAndCreateInfoElementWithValue(element, tc.invalid)
+		assert.ErrorContains(t, err, "invalid length", "data type %d", tc.dataType)
+		_, err = DecodeAndCreateInfoElementWithValue(element, nil)
+		assert.NoError(t, err, "data type %d", tc.dataType)
+	}
+	ie, err := DecodeAndCreateInfoElementWithValue(NewInfoElement("sourceIPv4Address", 8, Ipv4Address, 0, 4), net.ParseIP("1.2.3.4"))
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", ie.GetIPAddressValue().String())
+}
+
 func BenchmarkEncodeInfoElementValueToBuffShortString(b *testing.B) {
 	// a short string has a max length of 254
 	str := strings.Repeat("x", 128)
@@ -108,3 +208,15 @@ func BenchmarkEncodeInfoElementValueToBuffLongString(b *testing.B) {
 		}
 	}
 }
+
+func TestNormalizeNumericType(t *testing.T) {
+	ie := NormalizeNumericType(NewSigned32InfoElement(NewInfoElement("mibObjectValueInteger", 434, Signed32, 0, 4), -2))
+	assert.Equal(t, Signed64, ie.GetDataType())
+	assert.Equal(t, int64(-2), ie.GetSigned64Value())
+	ie = NormalizeNumericType(NewFloat32InfoElement(NewInfoElement("samplingProbability", 311, Float32, 0, 4), 0.5))
+	assert.Equal(t, Float64, ie.GetDataType())
+	assert.Equal(t, 0.5, ie.GetFloat64Value())
+	assert.Equal(t, "samplingProbability", ie.GetName())
+	str := NewStringInfoElement(NewInfoElement("interfaceName", 82, String, 0, VariableLength), "eth0")
+	assert.Equal(t, str, NormalizeNumericType(str))
+}
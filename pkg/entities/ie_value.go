@@ -25,6 +25,10 @@ type InfoElementWithValue interface {
 	GetMacAddressValue() net.HardwareAddr
 	GetStringValue() string
 	GetIPAddressValue() net.IP
+	GetOctetArrayValue() []byte
+	GetBasicListValue() *BasicListValue
+	GetSubTemplateListValue() *SubTemplateListValue
+	GetSubTemplateMultiListValue() *SubTemplateMultiListValue
 	SetUnsigned8Value(val uint8)
 	SetUnsigned16Value(val uint16)
 	SetUnsigned32Value(val uint32)
@@ -39,13 +43,30 @@ type InfoElementWithValue interface {
 	SetMacAddressValue(val net.HardwareAddr)
 	SetStringValue(val string)
 	SetIPAddressValue(val net.IP)
+	SetOctetArrayValue(val []byte)
+	SetBasicListValue(val *BasicListValue)
+	SetSubTemplateListValue(val *SubTemplateListValue)
+	SetSubTemplateMultiListValue(val *SubTemplateMultiListValue)
 	IsValueEmpty() bool
 	GetLength() int
 	ResetValue()
+	// GetByteOffsets returns the start (inclusive) and end (exclusive) offsets
+	// of the encoded element within its data record. They are only set when
+	// the collecting process records element offsets.
+	GetByteOffsets() (start int, end int, exist bool)
+	SetByteOffsets(start int, end int)
+	// GetUnits returns the units of the info element value, e.g. "octets".
+	GetUnits() string
+	// GetSemantics returns the data type semantics of the info element, e.g.
+	// "deltaCounter".
+	GetSemantics() string
 }
 
 type baseInfoElement struct {
 	element *InfoElement
+	// byteOffsets are the start and end offsets of the element in the decoded
+	// data record, used for debugging.
+	byteOffsets *[2]int
 }
 
 func (b *baseInfoElement) GetName() string {
@@ -64,6 +85,25 @@ func (b *baseInfoElement) AddInfoElement(infoElement *InfoElement) {
 	b.element = infoElement
 }
 
+func (b *baseInfoElement) GetUnits() string {
+	return b.element.Units
+}
+
+func (b *baseInfoElement) GetSemantics() string {
+	return b.element.Semantics
+}
+
+func (b *baseInfoElement) GetByteOffsets() (int, int, bool) {
+	if b.byteOffsets == nil {
+		return 0, 0, false
+	}
+	return b.byteOffsets[0], b.byteOffsets[1], true
+}
+
+func (b *baseInfoElement) SetByteOffsets(start int, end int) {
+	b.byteOffsets = &[2]int{start, end}
+}
+
 func (b *baseInfoElement) GetUnsigned8Value() uint8 {
 	panic("accessing value of wrong data type")
 }
@@ -120,6 +160,22 @@ func (b *baseInfoElement) GetIPAddressValue() net.IP {
 	panic("accessing value of wrong data type")
 }
 
+func (b *baseInfoElement) GetOctetArrayValue() []byte {
+	panic("accessing value of wrong data type")
+}
+
+func (b *baseInfoElement) GetBasicListValue() *BasicListValue {
+	panic("accessing value of wrong data type")
+}
+
+func (b *baseInfoElement) GetSubTemplateListValue() *SubTemplateListValue {
+	panic("accessing value of wrong data type")
+}
+
+func (b *baseInfoElement) GetSubTemplateMultiListValue() *SubTemplateMultiListValue {
+	panic("accessing value of wrong data type")
+}
+
 func (b *baseInfoElement) SetUnsigned8Value(val uint8) {
 	panic("setting value with wrong data type")
 }
@@ -176,6 +232,22 @@ func (b *baseInfoElement) SetIPAddressValue(val net.IP) {
 	panic("setting value with wrong data type")
 }
 
+func (b *baseInfoElement) SetOctetArrayValue(val []byte) {
+	panic("setting value with wrong data type")
+}
+
+func (b *baseInfoElement) SetBasicListValue(val *BasicListValue) {
+	panic("setting value with wrong data type")
+}
+
+func (b *baseInfoElement) SetSubTemplateListValue(val *SubTemplateListValue) {
+	panic("setting value with wrong data type")
+}
+
+func (b *baseInfoElement) SetSubTemplateMultiListValue(val *SubTemplateMultiListValue) {
+	panic("setting value with wrong data type")
+}
+
 func (b *baseInfoElement) GetLength() int {
 	return int(b.element.Len)
 }
@@ -565,6 +637,46 @@ func (s *StringInfoElement) ResetValue() {
 	s.value = ""
 }
 
+type OctetArrayInfoElement struct {
+	baseInfoElement
+	value []byte
+}
+
+func NewOctetArrayInfoElement(element *InfoElement, val []byte) *OctetArrayInfoElement {
+	infoElem := &OctetArrayInfoElement{
+		value: val,
+	}
+	infoElem.element = element
+	return infoElem
+}
+
+func (oa *OctetArrayInfoElement) GetOctetArrayValue() []byte {
+	return oa.value
+}
+
+func (oa *OctetArrayInfoElement) GetLength() int {
+	if oa.element.Len != VariableLength {
+		return int(oa.element.Len)
+	}
+	if len(oa.value) < 255 {
+		return len(oa.value) + 1
+	} else {
+		return len(oa.value) + 3
+	}
+}
+
+func (oa *OctetArrayInfoElement) SetOctetArrayValue(val []byte) {
+	oa.value = val
+}
+
+func (oa *OctetArrayInfoElement) IsValueEmpty() bool {
+	return len(oa.value) == 0
+}
+
+func (oa *OctetArrayInfoElement) ResetValue() {
+	oa.value = nil
+}
+
 type DateTimeSecondsInfoElement struct {
 	value uint32
 	baseInfoElement
@@ -623,6 +735,70 @@ func (dmsec *DateTimeMillisecondsInfoElement) ResetValue() {
 	dmsec.value = 0
 }
 
+// DateTimeMicrosecondsInfoElement holds the value in the NTP timestamp format
+// of RFC7011 section 6.1.9. Use NewDateTimeInfoElement to create it from a
+// time.Time.
+type DateTimeMicrosecondsInfoElement struct {
+	value uint64
+	baseInfoElement
+}
+
+func NewDateTimeMicrosecondsInfoElement(element *InfoElement, val uint64) *DateTimeMicrosecondsInfoElement {
+	infoElem := &DateTimeMicrosecondsInfoElement{
+		value: val,
+	}
+	infoElem.element = element
+	return infoElem
+}
+
+func (dusec *DateTimeMicrosecondsInfoElement) GetUnsigned64Value() uint64 {
+	return dusec.value
+}
+
+func (dusec *DateTimeMicrosecondsInfoElement) SetUnsigned64Value(val uint64) {
+	dusec.value = val
+}
+
+func (dusec *DateTimeMicrosecondsInfoElement) IsValueEmpty() bool {
+	return dusec.value == 0
+}
+
+func (dusec *DateTimeMicrosecondsInfoElement) ResetValue() {
+	dusec.value = 0
+}
+
+// DateTimeNanosecondsInfoElement holds the value in the NTP timestamp format
+// of RFC7011 section 6.1.10. Use NewDateTimeInfoElement to create it from a
+// time.Time.
+type DateTimeNanosecondsInfoElement struct {
+	value uint64
+	baseInfoElement
+}
+
+func NewDateTimeNanosecondsInfoElement(element *InfoElement, val uint64) *DateTimeNanosecondsInfoElement {
+	infoElem := &DateTimeNanosecondsInfoElement{
+		value: val,
+	}
+	infoElem.element = element
+	return infoElem
+}
+
+func (dnsec *DateTimeNanosecondsInfoElement) GetUnsigned64Value() uint64 {
+	return dnsec.value
+}
+
+func (dnsec *DateTimeNanosecondsInfoElement) SetUnsigned64Value(val uint64) {
+	dnsec.value = val
+}
+
+func (dnsec *DateTimeNanosecondsInfoElement) IsValueEmpty() bool {
+	return dnsec.value == 0
+}
+
+func (dnsec *DateTimeNanosecondsInfoElement) ResetValue() {
+	dnsec.value = 0
+}
+
 type IPAddressInfoElement struct {
 	baseInfoElement
 	value net.IP
@@ -651,3 +827,131 @@ func (ip *IPAddressInfoElement) IsValueEmpty() bool {
 func (ip *IPAddressInfoElement) ResetValue() {
 	ip.value = nil
 }
+
+type BasicListInfoElement struct {
+	baseInfoElement
+	value *BasicListValue
+}
+
+func NewBasicListInfoElement(element *InfoElement, val *BasicListValue) *BasicListInfoElement {
+	infoElem := &BasicListInfoElement{
+		value: val,
+	}
+	infoElem.element = element
+	return infoElem
+}
+
+func (bl *BasicListInfoElement) GetBasicListValue() *BasicListValue {
+	return bl.value
+}
+
+func (bl *BasicListInfoElement) SetBasicListValue(val *BasicListValue) {
+	bl.value = val
+}
+
+func (bl *BasicListInfoElement) IsValueEmpty() bool {
+	return bl.value == nil
+}
+
+func (bl *BasicListInfoElement) ResetValue() {
+	bl.value = nil
+}
+
+type SubTemplateListInfoElement struct {
+	baseInfoElement
+	value *SubTemplateListValue
+}
+
+func NewSubTemplateListInfoElement(element *InfoElement, val *SubTemplateListValue) *SubTemplateListInfoElement {
+	infoElem := &SubTemplateListInfoElement{
+		value: val,
+	}
+	infoElem.element = element
+	return infoElem
+}
+
+func (stl *SubTemplateListInfoElement) GetSubTemplateListValue() *SubTemplateListValue {
+	return stl.value
+}
+
+// GetLength returns the length of the encoded list, including the
+// variable-length prefix.
+func (stl *SubTemplateListInfoElement) GetLength() int {
+	length := stl.value.getEncodedLength()
+	if length < 255 {
+		return length + 1
+	}
+	return length + 3
+}
+
+func (stl *SubTemplateListInfoElement) SetSubTemplateListValue(val *SubTemplateListValue) {
+	stl.value = val
+}
+
+func (stl *SubTemplateListInfoElement) IsValueEmpty() bool {
+	return stl.value == nil
+}
+
+func (stl *SubTemplateListInfoElement) ResetValue() {
+	stl.value = nil
+}
+
+type SubTemplateMultiListInfoElement struct {
+	baseInfoElement
+	value *SubTemplateMultiListValue
+}
+
+func NewSubTemplateMultiListInfoElement(element *InfoElement, val *SubTemplateMultiListValue) *SubTemplateMultiListInfoElement {
+	infoElem := &SubTemplateMultiListInfoElement{
+		value: val,
+	}
+	infoElem.element = element
+	return infoElem
+}
+
+func (stml *SubTemplateMultiListInfoElement) GetSubTemplateMultiListValue() *SubTemplateMultiListValue {
+	return stml.value
+}
+
+func (stml *SubTemplateMultiListInfoElement) SetSubTemplateMultiListValue(val *SubTemplateMultiListValue) {
+	stml.value = val
+}
+
+func (stml *SubTemplateMultiListInfoElement) IsValueEmpty() bool {
+	return stml.value == nil
+}
+
+func (stml *SubTemplateMultiListInfoElement) ResetValue() {
+	stml.value = nil
+}
+
+// NormalizeNumericType returns the element with its value widened to uint64 for
+// unsigned integers, int64 for signed integers and float64 for floats, along
+// with an element of the widened data type. Other elements are returned as is.
+func NormalizeNumericType(ie InfoElementWithValue) InfoElementWithValue {
+	element := ie.GetInfoElement()
+	widen := func(dataType IEDataType) *InfoElement {
+		widenedElement := *element
+		widenedElement.DataType = dataType
+		widenedElement.Len = InfoElementLength[dataType]
+		return &widenedElement
+	}
+	switch ie.GetDataType() {
+	case Unsigned8:
+		return NewUnsigned64InfoElement(widen(Unsigned64), uint64(ie.GetUnsigned8Value()))
+	case Unsigned16:
+		return NewUnsigned64InfoElement(widen(Unsigned64), uint64(ie.GetUnsigned16Value()))
+	case Unsigned32:
+		return NewUnsigned64InfoElement(widen(Unsigned64), uint64(ie.GetUnsigned32Value()))
+	case Signed8:
+		return NewSigned64InfoElement(widen(Signed64), int64(ie.GetSigned8Value()))
+	case Signed16:
+		return NewSigned64InfoElement(widen(Signed64), int64(ie.GetSigned16Value()))
+	case Signed32:
+		return NewSigned64InfoElement(widen(Signed64), int64(ie.GetSigned32Value()))
+	case Float32:
+		return NewFloat64InfoElement(widen(Float64), float64(ie.GetFloat32Value()))
+	default:
+		return ie
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// messageJSON is the JSON representation of a Message.
+type messageJSON struct {
+	Version        uint16   `json:"version"`
+	SequenceNumber uint32   `json:"sequenceNumber"`
+	ObsDomainID    uint32   `json:"obsDomainID"`
+	ExportTime     string   `json:"exportTime"`
+	ExportAddress  string   `json:"exportAddress,omitempty"`
+	SetType        string   `json:"setType,omitempty"`
+	Records        []Record `json:"records"`
+}
+
+// MarshalJSON encodes the message header fields and the records of its set.
+// The export time is formatted as RFC3339.
+func (m *Message) MarshalJSON() ([]byte, error) {
+	msg := messageJSON{
+		Version:        m.version,
+		SequenceNumber: m.seqNumber,
+		ObsDomainID:    m.obsDomainID,
+		ExportTime:     time.Unix(int64(m.exportTime), 0).UTC().Format(time.RFC3339),
+		ExportAddress:  m.exportAddress,
+		Records:        []Record{},
+	}
+	if m.set != nil {
+		switch m.set.GetSetType() {
+		case Template:
+			msg.SetType = "template"
+		case OptionsTemplate:
+			msg.SetType = "optionsTemplate"
+		case Data:
+			msg.SetType = "data"
+		}
+		msg.Records = m.set.GetRecords()
+	}
+	return json.Marshal(msg)
+}
+
+// MarshalJSON encodes the data record as an object with an entry per element,
+// see InfoElementWithValue MarshalJSON for the keys and values.
+func (d *dataRecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(getRecordJSONValue(d.orderedElementList))
+}
+
+// MarshalJSON encodes the template record as its template ID and the
+// enterprise-qualified names of its elements.
+func (t *templateRecord) MarshalJSON() ([]byte, error) {
+	elements := make([]string, 0, len(t.orderedElementList))
+	for _, element := range t.orderedElementList {
+		if element != nil {
+			elements = append(elements, GetQualifiedName(element.GetInfoElement()))
+		}
+	}
+	return json.Marshal(struct {
+		TemplateID      uint16   `json:"templateID"`
+		ScopeFieldCount uint16   `json:"scopeFieldCount,omitempty"`
+		Elements        []string `json:"elements"`
+	}{t.templateID, t.scopeFieldCount, elements})
+}
+
+// getRecordJSONValue returns the elements keyed by their enterprise-qualified
+// name, so that elements with the same name from different enterprises do not
+// collide.
+func getRecordJSONValue(elements []InfoElementWithValue) map[string]interface{} {
+	values := make(map[string]interface{}, len(elements))
+	for _, element := range elements {
+		if element != nil {
+			values[GetQualifiedName(element.GetInfoElement())] = getElementJSONValue(element)
+		}
+	}
+	return values
+}
+
+// getElementJSONValue returns the value of the element in a form suitable for
+// JSON: addresses as strings, timestamps as RFC3339 strings in UTC, octet
+// arrays as hex strings and numbers as numbers. Non-finite floats, which cannot
+// be represented in JSON, are returned as nil.
+func getElementJSONValue(element InfoElementWithValue) interface{} {
+	switch element.GetDataType() {
+	case Float32, Float64:
+		value := element.GetFloat64Value()
+		if element.GetDataType() == Float32 {
+			value = float64(element.GetFloat32Value())
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil
+		}
+		return value
+	case DateTimeSeconds, DateTimeMilliseconds, DateTimeMicroseconds, DateTimeNanoseconds:
+		t, err := GetTimeValue(element)
+		if err != nil {
+			return nil
+		}
+		return t.UTC().Format(time.RFC3339Nano)
+	case MacAddress:
+		if mac := element.GetMacAddressValue(); mac != nil {
+			return mac.String()
+		}
+		return nil
+	case Ipv4Address, Ipv6Address:
+		if ip := element.GetIPAddressValue(); ip != nil {
+			return ip.String()
+		}
+		return nil
+	case OctetArray:
+		return hex.EncodeToString(element.GetOctetArrayValue())
+	case BasicList:
+		basicList := element.GetBasicListValue()
+		if basicList == nil {
+			return nil
+		}
+		values := make([]interface{}, len(basicList.Values))
+		for i, value := range basicList.Values {
+			values[i] = getElementJSONValue(value)
+		}
+		return values
+	case SubTemplateList:
+		subTemplateList := element.GetSubTemplateListValue()
+		if subTemplateList == nil {
+			return nil
+		}
+		return getRecordsJSONValue(subTemplateList.Records)
+	case SubTemplateMultiList:
+		subTemplateMultiList := element.GetSubTemplateMultiListValue()
+		if subTemplateMultiList == nil {
+			return nil
+		}
+		recordsByTemplateID := subTemplateMultiList.GetRecordsByTemplateID()
+		values := make(map[uint16][]map[string]interface{}, len(recordsByTemplateID))
+		for templateID, records := range recordsByTemplateID {
+			values[templateID] = getRecordsJSONValue(records)
+		}
+		return values
+	default:
+		// The remaining data types are numbers, booleans and strings, which are
+		// rendered as is.
+		return getElementMapValue(element)
+	}
+}
+
+// FormatElementValue returns the value of the element as a string, rendered
+// like in the JSON encoding of the element: addresses as strings, timestamps as
+// RFC3339 strings in UTC and octet arrays as hex strings. Structured data is
+// returned as JSON, and values which are null in JSON as an empty string.
+func FormatElementValue(element InfoElementWithValue) string {
+	switch value := getElementJSONValue(element).(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []interface{}, []map[string]interface{}, map[uint16][]map[string]interface{}:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func getRecordsJSONValue(records [][]InfoElementWithValue) []map[string]interface{} {
+	values := make([]map[string]interface{}, len(records))
+	for i, record := range records {
+		values[i] = getRecordJSONValue(record)
+	}
+	return values
+}
+
+// marshalElementJSON encodes the element as an object with a single entry,
+// keyed by the enterprise-qualified name of the element.
+func marshalElementJSON(element InfoElementWithValue) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		GetQualifiedName(element.GetInfoElement()): getElementJSONValue(element),
+	})
+}
+
+// The MarshalJSON methods of the elements encode the element as an object with
+// its name as the key and its value rendered according to its data type.
+// Enterprise-specific elements are keyed by their name prefixed with the
+// enterprise ID, e.g. "56506:sourcePodName", to avoid collisions with IANA
+// element names.
+
+func (e *Unsigned8InfoElement) MarshalJSON() ([]byte, error)            { return marshalElementJSON(e) }
+func (e *Unsigned16InfoElement) MarshalJSON() ([]byte, error)           { return marshalElementJSON(e) }
+func (e *Unsigned32InfoElement) MarshalJSON() ([]byte, error)           { return marshalElementJSON(e) }
+func (e *Unsigned64InfoElement) MarshalJSON() ([]byte, error)           { return marshalElementJSON(e) }
+func (e *Signed8InfoElement) MarshalJSON() ([]byte, error)              { return marshalElementJSON(e) }
+func (e *Signed16InfoElement) MarshalJSON() ([]byte, error)             { return marshalElementJSON(e) }
+func (e *Signed32InfoElement) MarshalJSON() ([]byte, error)             { return marshalElementJSON(e) }
+func (e *Signed64InfoElement) MarshalJSON() ([]byte, error)             { return marshalElementJSON(e) }
+func (e *Float32InfoElement) MarshalJSON() ([]byte, error)              { return marshalElementJSON(e) }
+func (e *Float64InfoElement) MarshalJSON() ([]byte, error)              { return marshalElementJSON(e) }
+func (e *BooleanInfoElement) MarshalJSON() ([]byte, error)              { return marshalElementJSON(e) }
+func (e *MacAddressInfoElement) MarshalJSON() ([]byte, error)           { return marshalElementJSON(e) }
+func (e *StringInfoElement) MarshalJSON() ([]byte, error)               { return marshalElementJSON(e) }
+func (e *OctetArrayInfoElement) MarshalJSON() ([]byte, error)           { return marshalElementJSON(e) }
+func (e *DateTimeSecondsInfoElement) MarshalJSON() ([]byte, error)      { return marshalElementJSON(e) }
+func (e *DateTimeMillisecondsInfoElement) MarshalJSON() ([]byte, error) { return marshalElementJSON(e) }
+func (e *DateTimeMicrosecondsInfoElement) MarshalJSON() ([]byte, error) { return marshalElementJSON(e) }
+func (e *DateTimeNanosecondsInfoElement) MarshalJSON() ([]byte, error)  { return marshalElementJSON(e) }
+func (e *IPAddressInfoElement) MarshalJSON() ([]byte, error)            { return marshalElementJSON(e) }
+func (e *BasicListInfoElement) MarshalJSON() ([]byte, error)            { return marshalElementJSON(e) }
+func (e *SubTemplateListInfoElement) MarshalJSON() ([]byte, error)      { return marshalElementJSON(e) }
+func (e *SubTemplateMultiListInfoElement) MarshalJSON() ([]byte, error) { return marshalElementJSON(e) }
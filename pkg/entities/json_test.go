@@ -0,0 +1,146 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"encoding/json"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord_MarshalJSON(t *testing.T) {
+	record := newDecodedRecord(t, []*InfoElement{
+		NewInfoElement("sourceIPv4Address", 8, Ipv4Address, 0, 4),
+		NewInfoElement("destinationIPv6Address", 28, Ipv6Address, 0, 16),
+		NewInfoElement("sourceMacAddress", 56, MacAddress, 0, 6),
+		NewInfoElement("octetDeltaCount", 1, Unsigned64, 0, 8),
+		NewInfoElement("flowStartSeconds", 150, DateTimeSeconds, 0, 4),
+		NewInfoElement("flowEndMilliseconds", 153, DateTimeMilliseconds, 0, 8),
+		NewInfoElement("interfaceName", 82, String, 0, VariableLength),
+		NewInfoElement("sourcePodName", 101, String, 56506, VariableLength),
+		NewInfoElement("sourcePodName", 101, String, 1234, VariableLength),
+		NewInfoElement("isMulticast", 206, Boolean, 0, 1),
+		NewInfoElement("mplsLabelStackSection", 70, OctetArray, 0, VariableLength),
+	}, [][]byte{
+		{10, 0, 0, 1},
+		net.ParseIP("2001:db8::1"),
+		{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
+		{0, 0, 0, 0, 0, 0, 0x4, 0xd2},
+		{0x65, 0x53, 0xf1, 0x00},
+		{0, 0, 0x01, 0x8b, 0xcf, 0xe5, 0x69, 0xf4},
+		[]byte("eth0"),
+		[]byte("pod-a"),
+		[]byte("pod-b"),
+		{1},
+		{0xca, 0xfe},
+	})
+	data, err := json.Marshal(record)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"sourceIPv4Address": "10.0.0.1",
+		"destinationIPv6Address": "2001:db8::1",
+		"sourceMacAddress": "aa:bb:cc:dd:ee:ff",
+		"octetDeltaCount": 1234,
+		"flowStartSeconds": "2023-11-14T22:13:20Z",
+		"flowEndMilliseconds": "2023-11-14T22:13:20.5Z",
+		"interfaceName": "eth0",
+		"56506:sourcePodName": "pod-a",
+		"1234:sourcePodName": "pod-b",
+		"isMulticast": true,
+		"mplsLabelStackSection": "cafe"
+	}`, string(data))
+
+	element, _, exist := record.GetInfoElementWithValue("sourceIPv4Address")
+	require.True(t, exist)
+	data, err = json.Marshal(element)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"sourceIPv4Address": "10.0.0.1"}`, string(data))
+
+	data, err = json.Marshal(NewFloat64InfoElement(NewInfoElement("samplingProbability", 311, Float64, 0, 8), math.NaN()))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"samplingProbability": null}`, string(data))
+}
+
+func TestMessage_MarshalJSON(t *testing.T) {
+	elements := []*InfoElement{
+		NewInfoElement("sourceIPv4Address", 8, Ipv4Address, 0, 4),
+		NewInfoElement("packetDeltaCount", 2, Unsigned64, 0, 8),
+	}
+	set := NewSet(true)
+	require.NoError(t, set.PrepareSet(Data, 256))
+	require.NoError(t, set.AddRecord([]InfoElementWithValue{
+		NewIPAddressInfoElement(elements[0], net.IP{1, 2, 3, 4}),
+		NewUnsigned64InfoElement(elements[1], 10),
+	}, 256))
+	message := NewMessage(true)
+	message.SetVersion(10)
+	message.SetSequenceNum(7)
+	message.SetObsDomainID(1)
+	message.SetExportTime(uint32(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).Unix()))
+	message.SetExportAddress("127.0.0.1")
+	message.AddSet(set)
+	data, err := json.Marshal(message)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"version": 10,
+		"sequenceNumber": 7,
+		"obsDomainID": 1,
+		"exportTime": "2026-01-02T03:04:05Z",
+		"exportAddress": "127.0.0.1",
+		"setType": "data",
+		"records": [{"sourceIPv4Address": "1.2.3.4", "packetDeltaCount": 10}]
+	}`, string(data))
+
+	templateSet := NewSet(true)
+	require.NoError(t, templateSet.PrepareSet(Template, 256))
+	require.NoError(t, templateSet.AddRecord([]InfoElementWithValue{
+		NewIPAddressInfoElement(elements[0], nil),
+		NewUnsigned64InfoElement(elements[1], 0),
+	}, 256))
+	message = NewMessage(true)
+	message.SetVersion(10)
+	message.AddSet(templateSet)
+	data, err = json.Marshal(message)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"version": 10,
+		"sequenceNumber": 0,
+		"obsDomainID": 0,
+		"exportTime": "1970-01-01T00:00:00Z",
+		"setType": "template",
+		"records": [{"templateID": 256, "elements": ["sourceIPv4Address", "packetDeltaCount"]}]
+	}`, string(data))
+}
+
+func TestFormatElementValue(t *testing.T) {
+	assert.Equal(t, "10.0.0.1", FormatElementValue(NewIPAddressInfoElement(NewInfoElement("sourceIPv4Address", 8, Ipv4Address, 0, 4), net.IP{10, 0, 0, 1})))
+	assert.Equal(t, "1234", FormatElementValue(NewUnsigned64InfoElement(NewInfoElement("octetDeltaCount", 1, Unsigned64, 0, 8), 1234)))
+	assert.Equal(t, "0.5", FormatElementValue(NewFloat64InfoElement(NewInfoElement("samplingProbability", 311, Float64, 0, 8), 0.5)))
+	assert.Equal(t, "", FormatElementValue(NewFloat64InfoElement(NewInfoElement("samplingProbability", 311, Float64, 0, 8), math.Inf(1))))
+	assert.Equal(t, "2023-11-14T22:13:20Z", FormatElementValue(NewDateTimeSecondsInfoElement(NewInfoElement("flowStartSeconds", 150, DateTimeSeconds, 0, 4), 1700000000)))
+	basicList := &BasicListValue{
+		Element: NewInfoElement("sourceTransportPort", 7, Unsigned16, 0, 2),
+		Values: []InfoElementWithValue{
+			NewUnsigned16InfoElement(NewInfoElement("sourceTransportPort", 7, Unsigned16, 0, 2), 80),
+			NewUnsigned16InfoElement(NewInfoElement("sourceTransportPort", 7, Unsigned16, 0, 2), 443),
+		},
+	}
+	assert.Equal(t, "[80,443]", FormatElementValue(NewBasicListInfoElement(NewInfoElement("basicList", 291, BasicList, 0, VariableLength), basicList)))
+}
@@ -16,11 +16,22 @@ package entities
 
 import (
 	"encoding/binary"
+	"time"
 )
 
 const (
 	MaxSocketMsgSize int = 65535
 	MsgHeaderLength  int = 16
+	// CompressedMsgHeaderLength is the length of the wrapper header which is
+	// prepended to compressed IPFIX messages. It consists of a 2-byte flags field
+	// and a 4-byte length of the payload that follows the header.
+	CompressedMsgHeaderLength int = 6
+)
+
+const (
+	// CompressedMsgFlagGzip indicates that the payload following the wrapper
+	// header is a gzip-compressed IPFIX message.
+	CompressedMsgFlagGzip uint16 = 0x1
 )
 
 // Message represents IPFIX message.
@@ -36,6 +47,9 @@ type Message struct {
 	exportAddress string
 	isDecoding    bool
 	set           Set
+	// receiveTime is the wall-clock time when the message was read by the
+	// collecting process.
+	receiveTime time.Time
 }
 
 func NewMessage(isDecoding bool) *Message {
@@ -108,6 +122,16 @@ func (m *Message) SetExportAddress(ipAddr string) {
 	m.exportAddress = ipAddr
 }
 
+// GetReceiveTime returns the time when the message was read by the collecting
+// process, as opposed to the export time set by the exporter.
+func (m *Message) GetReceiveTime() time.Time {
+	return m.receiveTime
+}
+
+func (m *Message) SetReceiveTime(receiveTime time.Time) {
+	m.receiveTime = receiveTime
+}
+
 func (m *Message) GetSet() Set {
 	return m.set
 }
@@ -0,0 +1,113 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/vmware/go-ipfix/pkg/entities/protobuf"
+)
+
+// ToProto converts the message to a generic FlowMessage protobuf, e.g. to
+// stream decoded messages over gRPC. Every element is identified by its
+// element ID and enterprise ID, and the fields of a record are sorted by
+// enterprise ID and element ID so that the mapping does not depend on the
+// order of the elements in the template. Elements of template records have no
+// value. Structured data elements are not supported.
+func (m *Message) ToProto() (*protobuf.FlowMessage, error) {
+	flowMessage := &protobuf.FlowMessage{
+		ExportTime:     m.exportTime,
+		SequenceNumber: m.seqNumber,
+		ObsDomainID:    m.obsDomainID,
+		ExportAddress:  m.exportAddress,
+	}
+	if m.set == nil {
+		return flowMessage, nil
+	}
+	isTemplate := m.set.GetSetType() == Template || m.set.GetSetType() == OptionsTemplate
+	for _, record := range m.set.GetRecords() {
+		flowRecord := &protobuf.FlowRecord{
+			TemplateID: uint32(record.GetTemplateID()),
+		}
+		for _, element := range record.GetOrderedElementList() {
+			if element == nil {
+				continue
+			}
+			field, err := elementToProtoField(element, !isTemplate)
+			if err != nil {
+				return nil, err
+			}
+			flowRecord.Fields = append(flowRecord.Fields, field)
+		}
+		sort.SliceStable(flowRecord.Fields, func(i, j int) bool {
+			if flowRecord.Fields[i].EnterpriseID != flowRecord.Fields[j].EnterpriseID {
+				return flowRecord.Fields[i].EnterpriseID < flowRecord.Fields[j].EnterpriseID
+			}
+			return flowRecord.Fields[i].ElementID < flowRecord.Fields[j].ElementID
+		})
+		flowMessage.Records = append(flowMessage.Records, flowRecord)
+	}
+	return flowMessage, nil
+}
+
+func elementToProtoField(element InfoElementWithValue, withValue bool) (*protobuf.Field, error) {
+	ie := element.GetInfoElement()
+	field := &protobuf.Field{
+		ElementID:    uint32(ie.ElementId),
+		EnterpriseID: ie.EnterpriseId,
+		Name:         ie.Name,
+	}
+	if !withValue {
+		return field, nil
+	}
+	switch ie.DataType {
+	case Unsigned8:
+		field.Value = &protobuf.Field_UnsignedValue{UnsignedValue: uint64(element.GetUnsigned8Value())}
+	case Unsigned16:
+		field.Value = &protobuf.Field_UnsignedValue{UnsignedValue: uint64(element.GetUnsigned16Value())}
+	case Unsigned32, DateTimeSeconds:
+		field.Value = &protobuf.Field_UnsignedValue{UnsignedValue: uint64(element.GetUnsigned32Value())}
+	case Unsigned64, DateTimeMilliseconds, DateTimeMicroseconds, DateTimeNanoseconds:
+		field.Value = &protobuf.Field_UnsignedValue{UnsignedValue: element.GetUnsigned64Value()}
+	case Signed8:
+		field.Value = &protobuf.Field_SignedValue{SignedValue: int64(element.GetSigned8Value())}
+	case Signed16:
+		field.Value = &protobuf.Field_SignedValue{SignedValue: int64(element.GetSigned16Value())}
+	case Signed32:
+		field.Value = &protobuf.Field_SignedValue{SignedValue: int64(element.GetSigned32Value())}
+	case Signed64:
+		field.Value = &protobuf.Field_SignedValue{SignedValue: element.GetSigned64Value()}
+	case Float32:
+		field.Value = &protobuf.Field_FloatValue{FloatValue: float64(element.GetFloat32Value())}
+	case Float64:
+		field.Value = &protobuf.Field_FloatValue{FloatValue: element.GetFloat64Value()}
+	case Boolean:
+		field.Value = &protobuf.Field_BoolValue{BoolValue: element.GetBooleanValue()}
+	case String:
+		field.Value = &protobuf.Field_StringValue{StringValue: element.GetStringValue()}
+	case MacAddress:
+		field.Value = &protobuf.Field_BytesValue{BytesValue: element.GetMacAddressValue()}
+	case Ipv4Address:
+		field.Value = &protobuf.Field_BytesValue{BytesValue: element.GetIPAddressValue().To4()}
+	case Ipv6Address:
+		field.Value = &protobuf.Field_BytesValue{BytesValue: element.GetIPAddressValue().To16()}
+	case OctetArray:
+		field.Value = &protobuf.Field_BytesValue{BytesValue: element.GetOctetArrayValue()}
+	default:
+		return nil, fmt.Errorf("element %s of data type %d cannot be converted to protobuf", ie.Name, ie.DataType)
+	}
+	return field, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/vmware/go-ipfix/pkg/entities/protobuf"
+)
+
+func TestMessage_ToProto(t *testing.T) {
+	elements := []*InfoElement{
+		NewInfoElement("sourcePodName", 101, String, 56506, VariableLength),
+		NewInfoElement("sourceIPv4Address", 8, Ipv4Address, 0, 4),
+		NewInfoElement("octetDeltaCount", 1, Unsigned64, 0, 8),
+		NewInfoElement("flowEndSeconds", 151, DateTimeSeconds, 0, 4),
+	}
+	values := [][]byte{
+		[]byte("pod1"),
+		{10, 0, 0, 1},
+		{0, 0, 0, 0, 0, 0, 0x4, 0xd2},
+		{0x5f, 0x9a, 0x6c, 0x12},
+	}
+	elementsWithValue := make([]InfoElementWithValue, len(elements))
+	for i, element := range elements {
+		var err error
+		elementsWithValue[i], err = DecodeAndCreateInfoElementWithValue(element, values[i])
+		require.NoError(t, err)
+	}
+	set := NewSet(true)
+	require.NoError(t, set.PrepareSet(Data, 256))
+	require.NoError(t, set.AddRecord(elementsWithValue, 256))
+	message := NewMessage(true)
+	message.SetExportTime(1604021266)
+	message.SetSequenceNum(10)
+	message.SetObsDomainID(1)
+	message.SetExportAddress("127.0.0.1")
+	message.AddSet(set)
+
+	flowMessage, err := message.ToProto()
+	require.NoError(t, err)
+	expected := &protobuf.FlowMessage{
+		ExportTime:     1604021266,
+		SequenceNumber: 10,
+		ObsDomainID:    1,
+		ExportAddress:  "127.0.0.1",
+		Records: []*protobuf.FlowRecord{{
+			TemplateID: 256,
+			Fields: []*protobuf.Field{
+				{ElementID: 1, Name: "octetDeltaCount", Value: &protobuf.Field_UnsignedValue{UnsignedValue: 1234}},
+				{ElementID: 8, Name: "sourceIPv4Address", Value: &protobuf.Field_BytesValue{BytesValue: net.IP{10, 0, 0, 1}}},
+				{ElementID: 151, Name: "flowEndSeconds", Value: &protobuf.Field_UnsignedValue{UnsignedValue: 1603955730}},
+				{ElementID: 101, EnterpriseID: 56506, Name: "sourcePodName", Value: &protobuf.Field_StringValue{StringValue: "pod1"}},
+			},
+		}},
+	}
+	assert.True(t, proto.Equal(expected, flowMessage), "Unexpected FlowMessage: %v", flowMessage)
+
+	data, err := proto.Marshal(flowMessage)
+	require.NoError(t, err)
+	decoded := &protobuf.FlowMessage{}
+	require.NoError(t, proto.Unmarshal(data, decoded))
+	assert.True(t, proto.Equal(flowMessage, decoded))
+}
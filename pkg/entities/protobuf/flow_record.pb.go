@@ -0,0 +1,490 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1
+// 	protoc        v3.15.0
+// source: pkg/entities/protobuf/flow_record.proto
+
+package protobuf
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// FlowMessage is a generic representation of a decoded IPFIX message.
+type FlowMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Header of IPFIX Message.
+	ExportTime     uint32        `protobuf:"varint,1,opt,name=ExportTime,proto3" json:"ExportTime,omitempty"`
+	SequenceNumber uint32        `protobuf:"varint,2,opt,name=SequenceNumber,proto3" json:"SequenceNumber,omitempty"`
+	ObsDomainID    uint32        `protobuf:"varint,3,opt,name=ObsDomainID,proto3" json:"ObsDomainID,omitempty"`
+	ExportAddress  string        `protobuf:"bytes,4,opt,name=ExportAddress,proto3" json:"ExportAddress,omitempty"`
+	Records        []*FlowRecord `protobuf:"bytes,5,rep,name=Records,proto3" json:"Records,omitempty"`
+}
+
+func (x *FlowMessage) Reset() {
+	*x = FlowMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_entities_protobuf_flow_record_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FlowMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlowMessage) ProtoMessage() {}
+
+func (x *FlowMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_entities_protobuf_flow_record_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlowMessage.ProtoReflect.Descriptor instead.
+func (*FlowMessage) Descriptor() ([]byte, []int) {
+	return file_pkg_entities_protobuf_flow_record_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *FlowMessage) GetExportTime() uint32 {
+	if x != nil {
+		return x.ExportTime
+	}
+	return 0
+}
+
+func (x *FlowMessage) GetSequenceNumber() uint32 {
+	if x != nil {
+		return x.SequenceNumber
+	}
+	return 0
+}
+
+func (x *FlowMessage) GetObsDomainID() uint32 {
+	if x != nil {
+		return x.ObsDomainID
+	}
+	return 0
+}
+
+func (x *FlowMessage) GetExportAddress() string {
+	if x != nil {
+		return x.ExportAddress
+	}
+	return ""
+}
+
+func (x *FlowMessage) GetRecords() []*FlowRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+// FlowRecord is a data record, or a template record whose fields have no value.
+type FlowRecord struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TemplateID uint32 `protobuf:"varint,1,opt,name=TemplateID,proto3" json:"TemplateID,omitempty"`
+	// Fields are sorted by enterprise ID and element ID.
+	Fields []*Field `protobuf:"bytes,2,rep,name=Fields,proto3" json:"Fields,omitempty"`
+}
+
+func (x *FlowRecord) Reset() {
+	*x = FlowRecord{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_entities_protobuf_flow_record_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FlowRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlowRecord) ProtoMessage() {}
+
+func (x *FlowRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_entities_protobuf_flow_record_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlowRecord.ProtoReflect.Descriptor instead.
+func (*FlowRecord) Descriptor() ([]byte, []int) {
+	return file_pkg_entities_protobuf_flow_record_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *FlowRecord) GetTemplateID() uint32 {
+	if x != nil {
+		return x.TemplateID
+	}
+	return 0
+}
+
+func (x *FlowRecord) GetFields() []*Field {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+// Field is an information element with its value.
+type Field struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ElementID    uint32 `protobuf:"varint,1,opt,name=ElementID,proto3" json:"ElementID,omitempty"`
+	EnterpriseID uint32 `protobuf:"varint,2,opt,name=EnterpriseID,proto3" json:"EnterpriseID,omitempty"`
+	Name         string `protobuf:"bytes,3,opt,name=Name,proto3" json:"Name,omitempty"`
+	// Types that are assignable to Value:
+	//	*Field_UnsignedValue
+	//	*Field_SignedValue
+	//	*Field_FloatValue
+	//	*Field_BoolValue
+	//	*Field_StringValue
+	//	*Field_BytesValue
+	Value isField_Value `protobuf_oneof:"Value"`
+}
+
+func (x *Field) Reset() {
+	*x = Field{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_entities_protobuf_flow_record_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Field) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Field) ProtoMessage() {}
+
+func (x *Field) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_entities_protobuf_flow_record_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Field.ProtoReflect.Descriptor instead.
+func (*Field) Descriptor() ([]byte, []int) {
+	return file_pkg_entities_protobuf_flow_record_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Field) GetElementID() uint32 {
+	if x != nil {
+		return x.ElementID
+	}
+	return 0
+}
+
+func (x *Field) GetEnterpriseID() uint32 {
+	if x != nil {
+		return x.EnterpriseID
+	}
+	return 0
+}
+
+func (x *Field) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (m *Field) GetValue() isField_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (x *Field) GetUnsignedValue() uint64 {
+	if x, ok := x.GetValue().(*Field_UnsignedValue); ok {
+		return x.UnsignedValue
+	}
+	return 0
+}
+
+func (x *Field) GetSignedValue() int64 {
+	if x, ok := x.GetValue().(*Field_SignedValue); ok {
+		return x.SignedValue
+	}
+	return 0
+}
+
+func (x *Field) GetFloatValue() float64 {
+	if x, ok := x.GetValue().(*Field_FloatValue); ok {
+		return x.FloatValue
+	}
+	return 0
+}
+
+func (x *Field) GetBoolValue() bool {
+	if x, ok := x.GetValue().(*Field_BoolValue); ok {
+		return x.BoolValue
+	}
+	return false
+}
+
+func (x *Field) GetStringValue() string {
+	if x, ok := x.GetValue().(*Field_StringValue); ok {
+		return x.StringValue
+	}
+	return ""
+}
+
+func (x *Field) GetBytesValue() []byte {
+	if x, ok := x.GetValue().(*Field_BytesValue); ok {
+		return x.BytesValue
+	}
+	return nil
+}
+
+type isField_Value interface {
+	isField_Value()
+}
+
+type Field_UnsignedValue struct {
+	UnsignedValue uint64 `protobuf:"varint,4,opt,name=UnsignedValue,proto3,oneof"`
+}
+
+type Field_SignedValue struct {
+	SignedValue int64 `protobuf:"varint,5,opt,name=SignedValue,proto3,oneof"`
+}
+
+type Field_FloatValue struct {
+	FloatValue float64 `protobuf:"fixed64,6,opt,name=FloatValue,proto3,oneof"`
+}
+
+type Field_BoolValue struct {
+	BoolValue bool `protobuf:"varint,7,opt,name=BoolValue,proto3,oneof"`
+}
+
+type Field_StringValue struct {
+	StringValue string `protobuf:"bytes,8,opt,name=StringValue,proto3,oneof"`
+}
+
+type Field_BytesValue struct {
+	// IP and MAC addresses, and octet arrays.
+	BytesValue []byte `protobuf:"bytes,9,opt,name=BytesValue,proto3,oneof"`
+}
+
+func (*Field_UnsignedValue) isField_Value() {}
+
+func (*Field_SignedValue) isField_Value() {}
+
+func (*Field_FloatValue) isField_Value() {}
+
+func (*Field_BoolValue) isField_Value() {}
+
+func (*Field_StringValue) isField_Value() {}
+
+func (*Field_BytesValue) isField_Value() {}
+
+var File_pkg_entities_protobuf_flow_record_proto protoreflect.FileDescriptor
+
+var file_pkg_entities_protobuf_flow_record_proto_rawDesc = []byte{
+	0x0a, 0x27, 0x70, 0x6b, 0x67, 0x2f, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x69, 0x65, 0x73, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x66, 0x6c, 0x6f, 0x77, 0x5f, 0x72, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x76, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x2e, 0x67, 0x6f, 0x69,
+	0x70, 0x66, 0x69, 0x78, 0x2e, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x69, 0x65, 0x73, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x22, 0xf0, 0x01, 0x0a, 0x0b, 0x46, 0x6c, 0x6f, 0x77, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74,
+	0x54, 0x69, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x45, 0x78, 0x70, 0x6f,
+	0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x26, 0x0a, 0x0e, 0x53, 0x65, 0x71, 0x75, 0x65, 0x6e,
+	0x63, 0x65, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e,
+	0x53, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x20,
+	0x0a, 0x0b, 0x4f, 0x62, 0x73, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x49, 0x44, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0b, 0x4f, 0x62, 0x73, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x49, 0x44,
+	0x12, 0x24, 0x0a, 0x0d, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x41,
+	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x51, 0x0a, 0x07, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x37, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x76, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x2e, 0x67, 0x6f, 0x69, 0x70,
+	0x66, 0x69, 0x78, 0x2e, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x69, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x6c, 0x6f, 0x77, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x52, 0x07, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x22, 0x78, 0x0a, 0x0a, 0x46, 0x6c, 0x6f,
+	0x77, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x54, 0x65, 0x6d, 0x70, 0x6c,
+	0x61, 0x74, 0x65, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x54, 0x65, 0x6d,
+	0x70, 0x6c, 0x61, 0x74, 0x65, 0x49, 0x44, 0x12, 0x4a, 0x0a, 0x06, 0x46, 0x69, 0x65, 0x6c, 0x64,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2e, 0x76, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x2e, 0x67, 0x6f, 0x69, 0x70,
+	0x66, 0x69, 0x78, 0x2e, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x69, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x52, 0x06, 0x46, 0x69, 0x65,
+	0x6c, 0x64, 0x73, 0x22, 0xba, 0x02, 0x0a, 0x05, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x1c, 0x0a,
+	0x09, 0x45, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x09, 0x45, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x44, 0x12, 0x22, 0x0a, 0x0c, 0x45,
+	0x6e, 0x74, 0x65, 0x72, 0x70, 0x72, 0x69, 0x73, 0x65, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x0c, 0x45, 0x6e, 0x74, 0x65, 0x72, 0x70, 0x72, 0x69, 0x73, 0x65, 0x49, 0x44, 0x12,
+	0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x4e,
+	0x61, 0x6d, 0x65, 0x12, 0x26, 0x0a, 0x0d, 0x55, 0x6e, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x56,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x48, 0x00, 0x52, 0x0d, 0x55, 0x6e,
+	0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x22, 0x0a, 0x0b, 0x53,
+	0x69, 0x67, 0x6e, 0x65, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03,
+	0x48, 0x00, 0x52, 0x0b, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12,
+	0x20, 0x0a, 0x0a, 0x46, 0x6c, 0x6f, 0x61, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x0a, 0x46, 0x6c, 0x6f, 0x61, 0x74, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x12, 0x1e, 0x0a, 0x09, 0x42, 0x6f, 0x6f, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x09, 0x42, 0x6f, 0x6f, 0x6c, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x12, 0x22, 0x0a, 0x0b, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0b, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67,
+	0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x20, 0x0a, 0x0a, 0x42, 0x79, 0x74, 0x65, 0x73, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x0a, 0x42, 0x79, 0x74,
+	0x65, 0x73, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x42, 0x17, 0x5a, 0x15, 0x70, 0x6b, 0x67, 0x2f, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x69, 0x65, 0x73,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
+}
+
+var (
+	file_pkg_entities_protobuf_flow_record_proto_rawDescOnce sync.Once
+	file_pkg_entities_protobuf_flow_record_proto_rawDescData = file_pkg_entities_protobuf_flow_record_proto_rawDesc
+)
+
+func file_pkg_entities_protobuf_flow_record_proto_rawDescGZIP() []byte {
+	file_pkg_entities_protobuf_flow_record_proto_rawDescOnce.Do(func() {
+		file_pkg_entities_protobuf_flow_record_proto_rawDescData = protoimpl.X.CompressGZIP(file_pkg_entities_protobuf_flow_record_proto_rawDescData)
+	})
+	return file_pkg_entities_protobuf_flow_record_proto_rawDescData
+}
+
+var file_pkg_entities_protobuf_flow_record_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_pkg_entities_protobuf_flow_record_proto_goTypes = []interface{}{
+	(*FlowMessage)(nil), // 0: github.com.vmware.goipfix.entities.protobuf.FlowMessage
+	(*FlowRecord)(nil),  // 1: github.com.vmware.goipfix.entities.protobuf.FlowRecord
+	(*Field)(nil),       // 2: github.com.vmware.goipfix.entities.protobuf.Field
+}
+var file_pkg_entities_protobuf_flow_record_proto_depIdxs = []int32{
+	1, // 0: github.com.vmware.goipfix.entities.protobuf.FlowMessage.Records:type_name -> github.com.vmware.goipfix.entities.protobuf.FlowRecord
+	2, // 1: github.com.vmware.goipfix.entities.protobuf.FlowRecord.Fields:type_name -> github.com.vmware.goipfix.entities.protobuf.Field
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_pkg_entities_protobuf_flow_record_proto_init() }
+func file_pkg_entities_protobuf_flow_record_proto_init() {
+	if File_pkg_entities_protobuf_flow_record_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pkg_entities_protobuf_flow_record_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FlowMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_entities_protobuf_flow_record_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FlowRecord); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_entities_protobuf_flow_record_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Field); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_pkg_entities_protobuf_flow_record_proto_msgTypes[2].OneofWrappers = []interface{}{
+		(*Field_UnsignedValue)(nil),
+		(*Field_SignedValue)(nil),
+		(*Field_FloatValue)(nil),
+		(*Field_BoolValue)(nil),
+		(*Field_StringValue)(nil),
+		(*Field_BytesValue)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_pkg_entities_protobuf_flow_record_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_pkg_entities_protobuf_flow_record_proto_goTypes,
+		DependencyIndexes: file_pkg_entities_protobuf_flow_record_proto_depIdxs,
+		MessageInfos:      file_pkg_entities_protobuf_flow_record_proto_msgTypes,
+	}.Build()
+	File_pkg_entities_protobuf_flow_record_proto = out.File
+	file_pkg_entities_protobuf_flow_record_proto_rawDesc = nil
+	file_pkg_entities_protobuf_flow_record_proto_goTypes = nil
+	file_pkg_entities_protobuf_flow_record_proto_depIdxs = nil
+}
@@ -41,6 +41,9 @@ type Record interface {
 	GetRecordLength() int
 	GetMinDataRecordLen() uint16
 	GetElementMap() map[string]interface{}
+	GetElementMapWithOptions(options ElementMapOptions) map[string]interface{}
+	ToCEF(options CEFOptions) string
+	FlowKeyHash(keyElements []string) uint64
 }
 
 type baseRecord struct {
@@ -75,6 +78,9 @@ type templateRecord struct {
 	minDataRecLength uint16
 	// index is used when adding elements to orderedElementList
 	index int
+	// scopeFieldCount is the number of scope fields of an options template
+	// record, and 0 for a template record.
+	scopeFieldCount uint16
 }
 
 func NewTemplateRecord(id uint16, numElements int, isDecoding bool) *templateRecord {
@@ -88,9 +94,19 @@ func NewTemplateRecord(id uint16, numElements int, isDecoding bool) *templateRec
 		},
 		0,
 		0,
+		0,
 	}
 }
 
+// NewOptionsTemplateRecord creates an options template record, whose first
+// scopeFieldCount elements are the scope fields.
+func NewOptionsTemplateRecord(id uint16, numElements int, scopeFieldCount int, isDecoding bool) *templateRecord {
+	record := NewTemplateRecord(id, numElements, isDecoding)
+	record.buffer = make([]byte, 6)
+	record.scopeFieldCount = uint16(scopeFieldCount)
+	return record
+}
+
 func (b *baseRecord) GetTemplateID() uint16 {
 	return b.templateID
 }
@@ -116,50 +132,146 @@ func (b *baseRecord) GetElementMap() map[string]interface{} {
 	elements := make(map[string]interface{})
 	orderedElements := b.GetOrderedElementList()
 	for _, element := range orderedElements {
-		switch element.GetDataType() {
-		case Unsigned8:
-			elements[element.GetName()] = element.GetUnsigned8Value()
-		case Unsigned16:
-			elements[element.GetName()] = element.GetUnsigned16Value()
-		case Unsigned32:
-			elements[element.GetName()] = element.GetUnsigned32Value()
-		case Unsigned64:
-			elements[element.GetName()] = element.GetUnsigned64Value()
-		case Signed8:
-			elements[element.GetName()] = element.GetSigned8Value()
-		case Signed16:
-			elements[element.GetName()] = element.GetSigned16Value()
-		case Signed32:
-			elements[element.GetName()] = element.GetSigned32Value()
-		case Signed64:
-			elements[element.GetName()] = element.GetSigned64Value()
-		case Float32:
-			elements[element.GetName()] = element.GetFloat32Value()
-		case Float64:
-			elements[element.GetName()] = element.GetFloat64Value()
-		case Boolean:
-			elements[element.GetName()] = element.GetBooleanValue()
-		case DateTimeSeconds:
-			elements[element.GetName()] = element.GetUnsigned32Value()
-		case DateTimeMilliseconds:
-			elements[element.GetName()] = element.GetUnsigned64Value()
-		case DateTimeMicroseconds, DateTimeNanoseconds:
-			err := fmt.Errorf("API does not support micro and nano seconds types yet")
-			elements[element.GetName()] = err
-		case MacAddress:
-			elements[element.GetName()] = element.GetMacAddressValue()
-		case Ipv4Address, Ipv6Address:
-			elements[element.GetName()] = element.GetIPAddressValue()
-		case String:
-			elements[element.GetName()] = element.GetStringValue()
-		default:
-			err := fmt.Errorf("API supports only valid information elements with datatypes given in RFC7011")
-			elements[element.GetName()] = err
-		}
+		elements[element.GetName()] = getElementMapValue(element)
 	}
 	return elements
 }
 
+func (b *baseRecord) GetElementMapWithOptions(options ElementMapOptions) map[string]interface{} {
+	elements := make(map[string]interface{})
+	orderedElements := b.GetOrderedElementList()
+	keys := ElementMapKeys(orderedElements, options)
+	for i, element := range orderedElements {
+		elements[keys[i]] = getElementMapValue(element)
+	}
+	return elements
+}
+
+func getElementMapValue(element InfoElementWithValue) interface{} {
+	switch element.GetDataType() {
+	case Unsigned8:
+		return element.GetUnsigned8Value()
+	case Unsigned16:
+		return element.GetUnsigned16Value()
+	case Unsigned32:
+		return element.GetUnsigned32Value()
+	case Unsigned64:
+		return element.GetUnsigned64Value()
+	case Signed8:
+		return element.GetSigned8Value()
+	case Signed16:
+		return element.GetSigned16Value()
+	case Signed32:
+		return element.GetSigned32Value()
+	case Signed64:
+		return element.GetSigned64Value()
+	case Float32:
+		return element.GetFloat32Value()
+	case Float64:
+		return element.GetFloat64Value()
+	case Boolean:
+		return element.GetBooleanValue()
+	case DateTimeSeconds:
+		return element.GetUnsigned32Value()
+	case DateTimeMilliseconds, DateTimeMicroseconds, DateTimeNanoseconds:
+		return element.GetUnsigned64Value()
+	case MacAddress:
+		return element.GetMacAddressValue()
+	case Ipv4Address, Ipv6Address:
+		return element.GetIPAddressValue()
+	case String:
+		return element.GetStringValue()
+	case OctetArray:
+		return element.GetOctetArrayValue()
+	case BasicList:
+		basicList := element.GetBasicListValue()
+		if basicList == nil {
+			return nil
+		}
+		values := make([]interface{}, len(basicList.Values))
+		for i, value := range basicList.Values {
+			values[i] = getElementMapValue(value)
+		}
+		return values
+	case SubTemplateList:
+		subTemplateList := element.GetSubTemplateListValue()
+		if subTemplateList == nil {
+			return nil
+		}
+		return getRecordMapValues(subTemplateList.Records)
+	case SubTemplateMultiList:
+		subTemplateMultiList := element.GetSubTemplateMultiListValue()
+		if subTemplateMultiList == nil {
+			return nil
+		}
+		recordsByTemplateID := subTemplateMultiList.GetRecordsByTemplateID()
+		values := make(map[uint16][]map[string]interface{}, len(recordsByTemplateID))
+		for templateID, records := range recordsByTemplateID {
+			values[templateID] = getRecordMapValues(records)
+		}
+		return values
+	default:
+		return fmt.Errorf("API supports only valid information elements with datatypes given in RFC7011")
+	}
+}
+
+// getRecordMapValues returns the element map values of the records of a
+// structured data element.
+func getRecordMapValues(records [][]InfoElementWithValue) []map[string]interface{} {
+	values := make([]map[string]interface{}, len(records))
+	for i, record := range records {
+		values[i] = make(map[string]interface{}, len(record))
+		for _, value := range record {
+			values[i][value.GetName()] = getElementMapValue(value)
+		}
+	}
+	return values
+}
+
+// ElementMapOptions configures the keys used by GetElementMapWithOptions.
+type ElementMapOptions struct {
+	// NameCanonicalization presents enterprise-specific elements with their
+	// base name, i.e. without the enterprise ID prefix. If the same base name
+	// is used by elements of different enterprises within the record, these
+	// elements keep their enterprise-qualified names to avoid collisions.
+	NameCanonicalization bool
+}
+
+// GetQualifiedName returns the name of the element prefixed with its enterprise
+// ID, e.g. "56506:sourcePodName". IANA elements are returned without prefix.
+func GetQualifiedName(element *InfoElement) string {
+	if element.EnterpriseId == 0 {
+		return element.Name
+	}
+	return fmt.Sprintf("%d:%s", element.EnterpriseId, element.Name)
+}
+
+// ElementMapKeys returns the key for each of the given elements, in the same
+// order, following the provided options.
+func ElementMapKeys(elements []InfoElementWithValue, options ElementMapOptions) []string {
+	var enterprisesByName map[string]map[uint32]struct{}
+	if options.NameCanonicalization {
+		enterprisesByName = make(map[string]map[uint32]struct{})
+		for _, element := range elements {
+			ie := element.GetInfoElement()
+			if _, exist := enterprisesByName[ie.Name]; !exist {
+				enterprisesByName[ie.Name] = make(map[uint32]struct{})
+			}
+			enterprisesByName[ie.Name][ie.EnterpriseId] = struct{}{}
+		}
+	}
+	keys := make([]string, len(elements))
+	for i, element := range elements {
+		ie := element.GetInfoElement()
+		if options.NameCanonicalization && len(enterprisesByName[ie.Name]) == 1 {
+			keys[i] = ie.Name
+		} else {
+			keys[i] = GetQualifiedName(ie)
+		}
+	}
+	return keys
+}
+
 func (d *dataRecord) PrepareRecord() error {
 	// We do not have to do anything if it is data record
 	return nil
@@ -202,6 +314,9 @@ func (t *templateRecord) PrepareRecord() error {
 	// Add Template Record Header
 	binary.BigEndian.PutUint16(t.buffer[0:2], t.templateID)
 	binary.BigEndian.PutUint16(t.buffer[2:4], t.fieldCount)
+	if t.scopeFieldCount > 0 {
+		binary.BigEndian.PutUint16(t.buffer[4:6], t.scopeFieldCount)
+	}
 	return nil
 }
 
@@ -246,3 +361,34 @@ func (t *templateRecord) GetRecordLength() int {
 func (t *templateRecord) GetMinDataRecordLen() uint16 {
 	return t.minDataRecLength
 }
+
+// PartitionByIPVersion splits the given data records into IPv4 and IPv6 flows
+// based on the ipVersion element. Records that do not carry ipVersion fall back
+// to the presence of source IPv4/IPv6 address elements. Records that cannot be
+// classified are not included in either of the returned slices.
+func PartitionByIPVersion(records []Record) ([]Record, []Record) {
+	ipv4Records := make([]Record, 0)
+	ipv6Records := make([]Record, 0)
+	for _, record := range records {
+		switch getIPVersion(record) {
+		case 4:
+			ipv4Records = append(ipv4Records, record)
+		case 6:
+			ipv6Records = append(ipv6Records, record)
+		}
+	}
+	return ipv4Records, ipv6Records
+}
+
+func getIPVersion(record Record) uint8 {
+	if ie, _, exist := record.GetInfoElementWithValue("ipVersion"); exist && ie.GetDataType() == Unsigned8 {
+		return ie.GetUnsigned8Value()
+	}
+	if _, _, exist := record.GetInfoElementWithValue("sourceIPv4Address"); exist {
+		return 4
+	}
+	if _, _, exist := record.GetInfoElementWithValue("sourceIPv6Address"); exist {
+		return 6
+	}
+	return 0
+}
@@ -0,0 +1,263 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"fmt"
+	"math"
+	"net"
+)
+
+// This file contains helpers which derive values from the elements of a
+// decoded data record.
+
+// GetSelectionRatio returns the ratio of selected to observed counts given the
+// names of the observed and selected counter elements in the record, e.g.
+// selectorIdTotalPktsObserved and selectorIdTotalPktsSelected, or
+// selectorIDTotalFlowsObserved and selectorIDTotalFlowsSelected.
+func GetSelectionRatio(record Record, observedName, selectedName string) (float64, error) {
+	observed, err := getUnsigned64Value(record, observedName)
+	if err != nil {
+		return 0, err
+	}
+	selected, err := getUnsigned64Value(record, selectedName)
+	if err != nil {
+		return 0, err
+	}
+	if observed == 0 {
+		return 0, fmt.Errorf("observed count in element %s is 0", observedName)
+	}
+	return float64(selected) / float64(observed), nil
+}
+
+func getUnsigned64Value(record Record, name string) (uint64, error) {
+	ie, _, exist := record.GetInfoElementWithValue(name)
+	if !exist {
+		return 0, fmt.Errorf("element with name %s not present in the record", name)
+	}
+	switch ie.GetDataType() {
+	case Unsigned8:
+		return uint64(ie.GetUnsigned8Value()), nil
+	case Unsigned16:
+		return uint64(ie.GetUnsigned16Value()), nil
+	case Unsigned32:
+		return uint64(ie.GetUnsigned32Value()), nil
+	case Unsigned64:
+		return ie.GetUnsigned64Value(), nil
+	default:
+		return 0, fmt.Errorf("element with name %s is not of unsigned integer type", name)
+	}
+}
+
+// GetDropRatio returns the ratio of dropped to total (delivered plus dropped)
+// counts given the names of the delivered and dropped counter elements in the
+// record, e.g. octetDeltaCount and droppedOctetDeltaCount, or packetDeltaCount
+// and droppedPacketDeltaCount.
+func GetDropRatio(record Record, deliveredName, droppedName string) (float64, error) {
+	delivered, err := getUnsigned64Value(record, deliveredName)
+	if err != nil {
+		return 0, err
+	}
+	dropped, err := getUnsigned64Value(record, droppedName)
+	if err != nil {
+		return 0, err
+	}
+	if delivered+dropped == 0 {
+		return 0, fmt.Errorf("total count of elements %s and %s is 0", deliveredName, droppedName)
+	}
+	return float64(dropped) / float64(delivered+dropped), nil
+}
+
+var timeSamplingElementNames = [][2]string{
+	{"flowSamplingTimeInterval", "flowSamplingTimeSpacing"},
+	{"samplingTimeInterval", "samplingTimeSpace"},
+}
+
+// GetTimeSamplingFraction returns the fraction of time during which flows or
+// packets are sampled with time-based sampling, i.e. interval / (interval +
+// spacing). It is taken from flowSamplingTimeInterval and
+// flowSamplingTimeSpacing, or from samplingTimeInterval and samplingTimeSpace
+// if the former are not present in the record.
+func GetTimeSamplingFraction(record Record) (float64, error) {
+	for _, names := range timeSamplingElementNames {
+		if _, _, exist := record.GetInfoElementWithValue(names[0]); !exist {
+			continue
+		}
+		interval, err := getUnsigned64Value(record, names[0])
+		if err != nil {
+			return 0, err
+		}
+		spacing, err := getUnsigned64Value(record, names[1])
+		if err != nil {
+			return 0, err
+		}
+		if interval == 0 {
+			return 0, fmt.Errorf("sampling interval in element %s is 0", names[0])
+		}
+		return float64(interval) / float64(interval+spacing), nil
+	}
+	return 0, fmt.Errorf("no time-based sampling element present in the record")
+}
+
+// ScaleCountByTimeSampling estimates the total count from a count observed
+// with time-based sampling, by dividing it by the sampling fraction returned
+// by GetTimeSamplingFraction for the record.
+func ScaleCountByTimeSampling(record Record, count uint64) (uint64, error) {
+	fraction, err := GetTimeSamplingFraction(record)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(math.Round(float64(count) / fraction)), nil
+}
+
+// MPLSLabelStackEntry is an entry of an MPLS label stack as per RFC3032, as
+// exported in the mplsTopLabelStackSection and mplsLabelStackSection<N>
+// elements, which do not carry the TTL.
+type MPLSLabelStackEntry struct {
+	Label         uint32
+	TrafficClass  uint8
+	BottomOfStack bool
+}
+
+var mplsLabelStackSectionNames = []string{
+	"mplsTopLabelStackSection",
+	"mplsLabelStackSection2",
+	"mplsLabelStackSection3",
+	"mplsLabelStackSection4",
+	"mplsLabelStackSection5",
+	"mplsLabelStackSection6",
+	"mplsLabelStackSection7",
+	"mplsLabelStackSection8",
+	"mplsLabelStackSection9",
+	"mplsLabelStackSection10",
+}
+
+// GetMPLSLabelStack reconstructs the MPLS label stack from the label stack
+// section elements present in the record, starting from the top label. The
+// reconstruction stops at the first missing section or at the entry with the
+// bottom-of-stack bit set.
+func GetMPLSLabelStack(record Record) ([]MPLSLabelStackEntry, error) {
+	stack := make([]MPLSLabelStackEntry, 0)
+	for _, name := range mplsLabelStackSectionNames {
+		ie, _, exist := record.GetInfoElementWithValue(name)
+		if !exist {
+			break
+		}
+		if ie.GetDataType() != OctetArray {
+			return nil, fmt.Errorf("element with name %s is not of octetArray type", name)
+		}
+		section := ie.GetOctetArrayValue()
+		if len(section) < 3 {
+			return nil, fmt.Errorf("element with name %s has invalid length %d", name, len(section))
+		}
+		entry := MPLSLabelStackEntry{
+			Label:         uint32(section[0])<<12 | uint32(section[1])<<4 | uint32(section[2])>>4,
+			TrafficClass:  (section[2] >> 1) & 0x7,
+			BottomOfStack: section[2]&0x1 == 1,
+		}
+		stack = append(stack, entry)
+		if entry.BottomOfStack {
+			break
+		}
+	}
+	if len(stack) == 0 {
+		return nil, fmt.Errorf("no MPLS label stack section present in the record")
+	}
+	return stack, nil
+}
+
+// GetOriginalExporter returns the address and observation domain ID of the
+// original exporter of a record re-exported by a mediator, taken from
+// originalExporterIPv4Address or originalExporterIPv6Address, and
+// originalObservationDomainId. The observation domain ID is 0 if the latter is
+// not present in the record.
+func GetOriginalExporter(record Record) (net.IP, uint32, error) {
+	var address net.IP
+	for _, name := range []string{"originalExporterIPv4Address", "originalExporterIPv6Address"} {
+		if ie, _, exist := record.GetInfoElementWithValue(name); exist {
+			address = ie.GetIPAddressValue()
+			break
+		}
+	}
+	if address == nil {
+		return nil, 0, fmt.Errorf("no original exporter address present in the record")
+	}
+	var obsDomainID uint32
+	if ie, _, exist := record.GetInfoElementWithValue("originalObservationDomainId"); exist {
+		if ie.GetDataType() != Unsigned32 {
+			return nil, 0, fmt.Errorf("element with name originalObservationDomainId is not of unsigned32 type")
+		}
+		obsDomainID = ie.GetUnsigned32Value()
+	}
+	return address, obsDomainID, nil
+}
+
+// GetVLANID returns the VLAN ID of the record, taken from dot1qVlanId, or from
+// vlanId if dot1qVlanId is not present. Only the 12 VLAN identifier bits of the
+// element value are kept.
+func GetVLANID(record Record) (uint16, error) {
+	for _, name := range []string{"dot1qVlanId", "vlanId"} {
+		ie, _, exist := record.GetInfoElementWithValue(name)
+		if !exist {
+			continue
+		}
+		if ie.GetDataType() != Unsigned16 {
+			return 0, fmt.Errorf("element with name %s is not of unsigned16 type", name)
+		}
+		return ie.GetUnsigned16Value() & 0x0fff, nil
+	}
+	return 0, fmt.Errorf("no VLAN element present in the record")
+}
+
+// ICMPTypeCode is the ICMP type and code packed in the icmpTypeCodeIPv4 and
+// icmpTypeCodeIPv6 elements as type * 256 + code.
+type ICMPTypeCode struct {
+	Type uint8 `json:"type"`
+	Code uint8 `json:"code"`
+}
+
+// NewICMPTypeCode splits the value of an icmpTypeCodeIPv4 or icmpTypeCodeIPv6
+// element into the ICMP type and code.
+func NewICMPTypeCode(value uint16) ICMPTypeCode {
+	return ICMPTypeCode{Type: uint8(value >> 8), Code: uint8(value)}
+}
+
+// String renders the ICMP type and code as "type/code", e.g. "8/0" for an echo
+// request.
+func (c ICMPTypeCode) String() string {
+	return fmt.Sprintf("%d/%d", c.Type, c.Code)
+}
+
+// IsICMPTypeCodeElement returns whether the element is icmpTypeCodeIPv4 or
+// icmpTypeCodeIPv6.
+func IsICMPTypeCodeElement(element *InfoElement) bool {
+	return element.EnterpriseId == 0 && (element.ElementId == 32 || element.ElementId == 139)
+}
+
+// GetICMPTypeCode returns the ICMP type and code of the record, taken from
+// icmpTypeCodeIPv4, or from icmpTypeCodeIPv6 if icmpTypeCodeIPv4 is not present.
+func GetICMPTypeCode(record Record) (ICMPTypeCode, error) {
+	for _, name := range []string{"icmpTypeCodeIPv4", "icmpTypeCodeIPv6"} {
+		ie, _, exist := record.GetInfoElementWithValue(name)
+		if !exist {
+			continue
+		}
+		if ie.GetDataType() != Unsigned16 {
+			return ICMPTypeCode{}, fmt.Errorf("element with name %s is not of unsigned16 type", name)
+		}
+		return NewICMPTypeCode(ie.GetUnsigned16Value()), nil
+	}
+	return ICMPTypeCode{}, fmt.Errorf("no ICMP type and code element present in the record")
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDecodedRecord creates a decoded data record from the given elements and
+// their encoded values.
+func newDecodedRecord(t *testing.T, elements []*InfoElement, values [][]byte) Record {
+	record := NewDataRecord(uniqueTemplateID, len(elements), 0, true)
+	for i, element := range elements {
+		ie, err := DecodeAndCreateInfoElementWithValue(element, values[i])
+		require.NoError(t, err)
+		require.NoError(t, record.AddInfoElement(ie))
+	}
+	return record
+}
+
+func TestGetSelectionRatio(t *testing.T) {
+	record := newDecodedRecord(t, []*InfoElement{
+		NewInfoElement("selectorIDTotalFlowsObserved", 394, 4, 0, 8),
+		NewInfoElement("selectorIDTotalFlowsSelected", 395, 4, 0, 8),
+	}, [][]byte{
+		{0, 0, 0, 0, 0, 0, 0x3, 0xe8},
+		{0, 0, 0, 0, 0, 0, 0, 0xfa},
+	})
+	ie, _, exist := record.GetInfoElementWithValue("selectorIDTotalFlowsObserved")
+	require.True(t, exist)
+	assert.Equal(t, uint64(1000), ie.GetUnsigned64Value())
+	ratio, err := GetSelectionRatio(record, "selectorIDTotalFlowsObserved", "selectorIDTotalFlowsSelected")
+	require.NoError(t, err)
+	assert.Equal(t, 0.25, ratio)
+	_, err = GetSelectionRatio(record, "selectorIdTotalPktsObserved", "selectorIdTotalPktsSelected")
+	assert.Error(t, err)
+}
+
+func TestGetICMPTypeCode(t *testing.T) {
+	record := newDecodedRecord(t, []*InfoElement{
+		NewInfoElement("icmpTypeCodeIPv4", 32, Unsigned16, 0, 2),
+	}, [][]byte{{0x08, 0x00}})
+	typeCode, err := GetICMPTypeCode(record)
+	require.NoError(t, err)
+	assert.Equal(t, ICMPTypeCode{Type: 8, Code: 0}, typeCode)
+	assert.Equal(t, "8/0", typeCode.String())
+
+	// Destination unreachable, port unreachable.
+	typeCode, err = GetICMPTypeCode(newDecodedRecord(t, []*InfoElement{
+		NewInfoElement("icmpTypeCodeIPv6", 139, Unsigned16, 0, 2),
+	}, [][]byte{{0x01, 0x04}}))
+	require.NoError(t, err)
+	assert.Equal(t, ICMPTypeCode{Type: 1, Code: 4}, typeCode)
+	_, err = GetICMPTypeCode(newDecodedRecord(t, []*InfoElement{
+		NewInfoElement("vlanId", 58, Unsigned16, 0, 2),
+	}, [][]byte{{0x00, 0x0a}}))
+	assert.Error(t, err)
+}
+
+func TestGetDropRatio(t *testing.T) {
+	record := newDecodedRecord(t, []*InfoElement{
+		NewInfoElement("octetDeltaCount", 1, 4, 0, 8),
+		NewInfoElement("droppedOctetDeltaCount", 132, 4, 0, 8),
+	}, [][]byte{
+		{0, 0, 0, 0, 0, 0, 0x3, 0x84},
+		{0, 0, 0, 0, 0, 0, 0, 0x64},
+	})
+	ratio, err := GetDropRatio(record, "octetDeltaCount", "droppedOctetDeltaCount")
+	require.NoError(t, err)
+	assert.Equal(t, 0.1, ratio)
+	_, err = GetDropRatio(record, "packetDeltaCount", "droppedPacketDeltaCount")
+	assert.Error(t, err)
+}
+
+func TestGetMPLSLabelStack(t *testing.T) {
+	record := newDecodedRecord(t, []*InfoElement{
+		NewInfoElement("mplsTopLabelStackSection", 70, 0, 0, 3),
+		NewInfoElement("mplsLabelStackSection2", 71, 0, 0, 3),
+	}, [][]byte{
+		{0x00, 0x06, 0x40},
+		{0x00, 0x0c, 0x8b},
+	})
+	stack, err := GetMPLSLabelStack(record)
+	require.NoError(t, err)
+	assert.Equal(t, []MPLSLabelStackEntry{
+		{Label: 100, TrafficClass: 0, BottomOfStack: false},
+		{Label: 200, TrafficClass: 5, BottomOfStack: true},
+	}, stack)
+	_, err = GetMPLSLabelStack(newDecodedRecord(t, []*InfoElement{
+		NewInfoElement("mplsLabelStackDepth", 202, 3, 0, 4),
+	}, [][]byte{{0, 0, 0, 2}}))
+	assert.Error(t, err)
+}
+
+func TestScaleCountByTimeSampling(t *testing.T) {
+	// 100ms sampled every second.
+	record := newDecodedRecord(t, []*InfoElement{
+		NewInfoElement("flowSamplingTimeInterval", 398, Unsigned64, 0, 8),
+		NewInfoElement("flowSamplingTimeSpacing", 399, Unsigned64, 0, 8),
+	}, [][]byte{
+		{0, 0, 0, 0, 0, 0x1, 0x86, 0xa0},
+		{0, 0, 0, 0, 0, 0xd, 0xbb, 0xa0},
+	})
+	interval, _, exist := record.GetInfoElementWithValue("flowSamplingTimeInterval")
+	require.True(t, exist)
+	assert.Equal(t, uint64(100000), interval.GetUnsigned64Value())
+	fraction, err := GetTimeSamplingFraction(record)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.1, fraction, 1e-9)
+	count, err := ScaleCountByTimeSampling(record, 50)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(500), count)
+
+	// 10ms sampled every 40ms.
+	record = newDecodedRecord(t, []*InfoElement{
+		NewInfoElement("samplingTimeInterval", 307, Unsigned32, 0, 4),
+		NewInfoElement("samplingTimeSpace", 308, Unsigned32, 0, 4),
+	}, [][]byte{
+		{0, 0, 0x27, 0x10},
+		{0, 0, 0x75, 0x30},
+	})
+	count, err = ScaleCountByTimeSampling(record, 7)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(28), count)
+
+	_, err = ScaleCountByTimeSampling(newDecodedRecord(t, []*InfoElement{
+		NewInfoElement("flowSamplingTimeInterval", 398, Unsigned64, 0, 8),
+	}, [][]byte{{0, 0, 0, 0, 0, 0x1, 0x86, 0xa0}}), 50)
+	assert.Error(t, err)
+	_, err = ScaleCountByTimeSampling(newDecodedRecord(t, []*InfoElement{
+		NewInfoElement("vlanId", 58, Unsigned16, 0, 2),
+	}, [][]byte{{0x00, 0x0a}}), 50)
+	assert.Error(t, err)
+}
+
+func TestGetVLANID(t *testing.T) {
+	record := newDecodedRecord(t, []*InfoElement{
+		NewInfoElement("vlanId", 58, Unsigned16, 0, 2),
+		NewInfoElement("postVlanId", 59, Unsigned16, 0, 2),
+		NewInfoElement("dot1qVlanId", 243, Unsigned16, 0, 2),
+	}, [][]byte{
+		{0x00, 0x0a},
+		{0x00, 0x14},
+		{0x00, 0x64},
+	})
+	vlanID, err := GetVLANID(record)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(100), vlanID)
+	postVlanID, _, exist := record.GetInfoElementWithValue("postVlanId")
+	require.True(t, exist)
+	assert.Equal(t, uint16(20), postVlanID.GetUnsigned16Value())
+
+	vlanID, err = GetVLANID(newDecodedRecord(t, []*InfoElement{
+		NewInfoElement("vlanId", 58, Unsigned16, 0, 2),
+	}, [][]byte{{0x00, 0x0a}}))
+	require.NoError(t, err)
+	assert.Equal(t, uint16(10), vlanID)
+	_, err = GetVLANID(newDecodedRecord(t, []*InfoElement{
+		NewInfoElement("mplsLabelStackDepth", 202, Unsigned32, 0, 4),
+	}, [][]byte{{0, 0, 0, 2}}))
+	assert.Error(t, err)
+}
@@ -259,3 +259,53 @@ func TestGetElementMap(t *testing.T) {
 	assert.Equal(t, valList.ipAddr, elements["sourceIPv4Address"])
 	assert.Equal(t, valList.stringVal, elements["interfaceDescription"])
 }
+
+func TestPartitionByIPVersion(t *testing.T) {
+	ipVersionIE := NewInfoElement("ipVersion", 60, 1, 0, 1)
+	ipVersionBuff, err := EncodeToIEDataType(Unsigned8, uint8(6))
+	assert.NoError(t, err)
+	decodedIE, err := DecodeAndCreateInfoElementWithValue(ipVersionIE, ipVersionBuff)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(6), decodedIE.GetUnsigned8Value())
+
+	newRecord := func(elements ...InfoElementWithValue) Record {
+		record := NewDataRecord(uniqueTemplateID, len(elements), 0, true)
+		for _, element := range elements {
+			record.AddInfoElement(element)
+		}
+		return record
+	}
+	ipv4Record := newRecord(NewUnsigned8InfoElement(ipVersionIE, 4))
+	ipv6Record := newRecord(decodedIE)
+	// No ipVersion element: classified by the address elements.
+	ipv4AddrRecord := newRecord(NewIPAddressInfoElement(NewInfoElement("sourceIPv4Address", 8, 18, 0, 4), net.ParseIP("10.0.0.1").To4()))
+	unknownRecord := newRecord(NewUnsigned16InfoElement(NewInfoElement("sourceTransportPort", 7, 2, 0, 2), 443))
+
+	ipv4Records, ipv6Records := PartitionByIPVersion([]Record{ipv4Record, ipv6Record, ipv4AddrRecord, unknownRecord})
+	assert.Equal(t, []Record{ipv4Record, ipv4AddrRecord}, ipv4Records)
+	assert.Equal(t, []Record{ipv6Record}, ipv6Records)
+}
+
+func TestGetElementMapWithOptions(t *testing.T) {
+	record := NewDataRecord(uniqueTemplateID, 4, 0, true)
+	record.AddInfoElement(NewUnsigned64InfoElement(NewInfoElement("octetDeltaCount", 1, 4, 0, 8), 100))
+	record.AddInfoElement(NewStringInfoElement(NewInfoElement("sourcePodName", 101, 13, 56506, 65535), "pod1"))
+	record.AddInfoElement(NewStringInfoElement(NewInfoElement("applicationName", 1000, 13, 1111, 65535), "app1"))
+	record.AddInfoElement(NewStringInfoElement(NewInfoElement("applicationName", 1000, 13, 2222, 65535), "app2"))
+
+	elements := record.GetElementMapWithOptions(ElementMapOptions{})
+	assert.Equal(t, map[string]interface{}{
+		"octetDeltaCount":      uint64(100),
+		"56506:sourcePodName":  "pod1",
+		"1111:applicationName": "app1",
+		"2222:applicationName": "app2",
+	}, elements)
+
+	elements = record.GetElementMapWithOptions(ElementMapOptions{NameCanonicalization: true})
+	assert.Equal(t, map[string]interface{}{
+		"octetDeltaCount":      uint64(100),
+		"sourcePodName":        "pod1",
+		"1111:applicationName": "app1",
+		"2222:applicationName": "app2",
+	}, elements)
+}
@@ -27,8 +27,9 @@ const (
 	// TemplateTTL is the template time to live for collecting process
 	TemplateTTL = TemplateRefreshTimeOut * 3
 	// TemplateSetID is the setID for template record
-	TemplateSetID uint16 = 2
-	SetHeaderLen  int    = 4
+	TemplateSetID        uint16 = 2
+	OptionsTemplateSetID uint16 = 3
+	SetHeaderLen         int    = 4
 )
 
 type ContentType uint8
@@ -36,7 +37,7 @@ type ContentType uint8
 const (
 	Template ContentType = iota
 	Data
-	// Add OptionsTemplate too when it is supported
+	OptionsTemplate
 	Undefined = 255
 )
 
@@ -49,6 +50,7 @@ type Set interface {
 	UpdateLenInHeader()
 	AddRecord(elements []InfoElementWithValue, templateID uint16) error
 	AddRecordWithExtraElements(elements []InfoElementWithValue, numExtraElements int, templateID uint16) error
+	AddOptionsTemplateRecord(elements []InfoElementWithValue, scopeFieldCount int, templateID uint16) error
 	GetRecords() []Record
 	GetNumberOfRecords() uint32
 }
@@ -169,6 +171,29 @@ func (s *set) AddRecordWithExtraElements(elements []InfoElementWithValue, numExt
 	return nil
 }
 
+// AddOptionsTemplateRecord adds an options template record to an options
+// template set. The first scopeFieldCount elements are the scope fields.
+func (s *set) AddOptionsTemplateRecord(elements []InfoElementWithValue, scopeFieldCount int, templateID uint16) error {
+	if s.setType != OptionsTemplate {
+		return fmt.Errorf("set type is not options template")
+	}
+	if scopeFieldCount == 0 || scopeFieldCount > len(elements) {
+		return fmt.Errorf("invalid scope field count %d for %d elements", scopeFieldCount, len(elements))
+	}
+	record := NewOptionsTemplateRecord(templateID, len(elements), scopeFieldCount, s.isDecoding)
+	if err := record.PrepareRecord(); err != nil {
+		return err
+	}
+	for _, element := range elements {
+		if err := record.AddInfoElement(element); err != nil {
+			return err
+		}
+	}
+	s.records = append(s.records, record)
+	s.length += record.GetRecordLength()
+	return nil
+}
+
 func (s *set) GetRecords() []Record {
 	return s.records
 }
@@ -180,6 +205,8 @@ func (s *set) GetNumberOfRecords() uint32 {
 func (s *set) createHeader(setType ContentType, templateID uint16) {
 	if setType == Template {
 		binary.BigEndian.PutUint16(s.headerBuffer[0:2], TemplateSetID)
+	} else if setType == OptionsTemplate {
+		binary.BigEndian.PutUint16(s.headerBuffer[0:2], OptionsTemplateSetID)
 	} else if setType == Data {
 		binary.BigEndian.PutUint16(s.headerBuffer[0:2], templateID)
 	}
@@ -64,6 +64,22 @@ func TestAddRecordIPv6Addresses(t *testing.T) {
 	assert.Equal(t, append(srcIP, dstIP...), newSet.GetRecords()[0].GetBuffer())
 }
 
+func TestAddOptionsTemplateRecord(t *testing.T) {
+	elements := []InfoElementWithValue{
+		NewUnsigned64InfoElement(NewInfoElement("selectorId", 302, 4, 0, 8), 0),
+		NewUnsigned32InfoElement(NewInfoElement("samplingPacketSpace", 306, 3, 0, 4), 0),
+	}
+	encodingSet := NewSet(false)
+	err := encodingSet.PrepareSet(OptionsTemplate, testTemplateID)
+	assert.NoError(t, err)
+	assert.Error(t, encodingSet.AddOptionsTemplateRecord(elements, 3, testTemplateID))
+	err = encodingSet.AddOptionsTemplateRecord(elements, 1, testTemplateID)
+	assert.NoError(t, err)
+	encodingSet.UpdateLenInHeader()
+	assert.Equal(t, []byte{0x0, 0x3, 0x0, 0x12}, encodingSet.GetHeaderBuffer())
+	assert.Equal(t, []byte{0x1, 0x0, 0x0, 0x2, 0x0, 0x1, 0x1, 0x2e, 0x0, 0x8, 0x1, 0x32, 0x0, 0x4}, encodingSet.GetRecords()[0].GetBuffer())
+}
+
 func TestGetSetType(t *testing.T) {
 	newSet := NewSet(true)
 	_ = newSet.PrepareSet(Template, testTemplateID)
@@ -0,0 +1,368 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// DefaultMaxStructuredDataDepth is the maximum nesting depth of structured
+// data used when StructuredDataDecoder.MaxDepth is not set.
+const DefaultMaxStructuredDataDepth = 8
+
+// Semantics of the structured data lists (RFC 6313 section 4.4).
+const (
+	SemanticNoneOf       uint8 = 0x00
+	SemanticExactlyOneOf uint8 = 0x01
+	SemanticOneOrMoreOf  uint8 = 0x02
+	SemanticAllOf        uint8 = 0x03
+	SemanticOrdered      uint8 = 0x04
+	SemanticUndefined    uint8 = 0xff
+)
+
+// BasicListValue is the value of a basicList information element (RFC 6313
+// section 4.5.1). All the values of the list are of the same element.
+type BasicListValue struct {
+	Semantic uint8
+	Element  *InfoElement
+	Values   []InfoElementWithValue
+}
+
+// SubTemplateListValue is the value of a subTemplateList information element
+// (RFC 6313 section 4.5.2). All the records of the list use the same template.
+type SubTemplateListValue struct {
+	Semantic   uint8
+	TemplateID uint16
+	Records    [][]InfoElementWithValue
+}
+
+// getEncodedLength returns the length of the encoded list, without the
+// variable-length prefix.
+func (v *SubTemplateListValue) getEncodedLength() int {
+	length := 3
+	if v == nil {
+		return length
+	}
+	for _, record := range v.Records {
+		for _, element := range record {
+			length += element.GetLength()
+		}
+	}
+	return length
+}
+
+// GetRecords returns the records of the list as data records of the template
+// of the list. An empty list has no records.
+func (v *SubTemplateListValue) GetRecords() []Record {
+	records := make([]Record, len(v.Records))
+	for i, elements := range v.Records {
+		record := NewDataRecord(v.TemplateID, len(elements), 0, true)
+		for _, element := range elements {
+			record.AddInfoElement(element)
+		}
+		records[i] = record
+	}
+	return records
+}
+
+// SubTemplateMultiListValue is the value of a subTemplateMultiList information
+// element (RFC 6313 section 4.5.3). The records of the list are grouped in
+// blocks, and each block uses its own template. The blocks have the semantic
+// of the list.
+type SubTemplateMultiListValue struct {
+	Semantic uint8
+	Blocks   []*SubTemplateListValue
+}
+
+// GetRecordsByTemplateID returns the records of the list keyed by the ID of
+// their template. The records of the blocks which use the same template are
+// kept in the order of the list.
+func (v *SubTemplateMultiListValue) GetRecordsByTemplateID() map[uint16][][]InfoElementWithValue {
+	records := make(map[uint16][][]InfoElementWithValue)
+	for _, block := range v.Blocks {
+		records[block.TemplateID] = append(records[block.TemplateID], block.Records...)
+	}
+	return records
+}
+
+// StructuredDataDecoder decodes structured data information elements, which
+// can be nested in each other, e.g. a subTemplateList whose records contain
+// basicLists.
+type StructuredDataDecoder struct {
+	// GetInfoElement returns the element used by a basicList.
+	GetInfoElement func(elementID uint16, enterpriseID uint32) (*InfoElement, error)
+	// GetTemplate returns the template used by a subTemplateList, or by a
+	// block of a subTemplateMultiList.
+	GetTemplate func(templateID uint16) ([]*InfoElement, error)
+	// MaxDepth is the maximum nesting depth of structured data, to protect
+	// against malicious input. A list which is not nested in another list
+	// has a depth of 1. If it is 0, DefaultMaxStructuredDataDepth is used.
+	MaxDepth int
+}
+
+// IsStructuredDataType returns whether the data type is a structured data type
+// supported by StructuredDataDecoder.
+func IsStructuredDataType(dataType IEDataType) bool {
+	return dataType == BasicList || dataType == SubTemplateList || dataType == SubTemplateMultiList
+}
+
+// Decode decodes the value of the given structured data element. Elements of
+// other data types are decoded with DecodeAndCreateInfoElementWithValue.
+func (d *StructuredDataDecoder) Decode(element *InfoElement, value []byte) (InfoElementWithValue, error) {
+	return d.decode(element, value, 1)
+}
+
+func (d *StructuredDataDecoder) decode(element *InfoElement, value []byte, depth int) (InfoElementWithValue, error) {
+	if !IsStructuredDataType(element.DataType) {
+		return DecodeAndCreateInfoElementWithValue(element, value)
+	}
+	maxDepth := d.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = DefaultMaxStructuredDataDepth
+	}
+	if depth > maxDepth {
+		return nil, fmt.Errorf("structured data element %s exceeds the maximum nesting depth %d", element.Name, maxDepth)
+	}
+	buffer := bytes.NewBuffer(value)
+	switch element.DataType {
+	case BasicList:
+		basicList, err := d.decodeBasicList(buffer, depth)
+		if err != nil {
+			return nil, fmt.Errorf("error when decoding basicList element %s: %v", element.Name, err)
+		}
+		return NewBasicListInfoElement(element, basicList), nil
+	case SubTemplateList:
+		subTemplateList, err := d.decodeSubTemplateList(buffer, depth)
+		if err != nil {
+			return nil, fmt.Errorf("error when decoding subTemplateList element %s: %v", element.Name, err)
+		}
+		return NewSubTemplateListInfoElement(element, subTemplateList), nil
+	default:
+		subTemplateMultiList, err := d.decodeSubTemplateMultiList(buffer, depth)
+		if err != nil {
+			return nil, fmt.Errorf("error when decoding subTemplateMultiList element %s: %v", element.Name, err)
+		}
+		return NewSubTemplateMultiListInfoElement(element, subTemplateMultiList), nil
+	}
+}
+
+func (d *StructuredDataDecoder) decodeBasicList(buffer *bytes.Buffer, depth int) (*BasicListValue, error) {
+	/*
+		Encoding format for basicList:
+		 0                   1                   2                   3
+		 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+		+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+		|    Semantic   |E|         Field ID            |   Element...  |
+		+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+		| ...Length     |           Enterprise Number (if E = 1) ...    |
+		+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+		|     ...       |   basicList Content ...                       |
+		+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+		(Reference: https://tools.ietf.org/html/rfc6313#section-4.5.1)
+	*/
+	if buffer.Len() < 5 {
+		return nil, fmt.Errorf("basicList header is too short")
+	}
+	if d.GetInfoElement == nil {
+		return nil, fmt.Errorf("no element lookup is provided")
+	}
+	semantic, _ := buffer.ReadByte()
+	fieldID := binary.BigEndian.Uint16(buffer.Next(2))
+	elementLength := binary.BigEndian.Uint16(buffer.Next(2))
+	var enterpriseID uint32
+	if fieldID>>15 == 1 {
+		if buffer.Len() < 4 {
+			return nil, fmt.Errorf("basicList header is too short")
+		}
+		fieldID = fieldID ^ 0x8000
+		enterpriseID = binary.BigEndian.Uint32(buffer.Next(4))
+	}
+	element, err := d.GetInfoElement(fieldID, enterpriseID)
+	if err != nil {
+		return nil, err
+	}
+	if elementLength == 0 {
+		return nil, fmt.Errorf("invalid length 0 for basicList element %s", element.Name)
+	}
+	if err := ValidateAddressLength(element, int(elementLength)); err != nil {
+		return nil, err
+	}
+	if elementLength < element.Len && IsReducedSizeEncodingSupported(element.DataType) {
+		reducedElement := *element
+		reducedElement.Len = elementLength
+		element = &reducedElement
+	}
+	basicList := &BasicListValue{
+		Semantic: semantic,
+		Element:  element,
+	}
+	for buffer.Len() > 0 {
+		remaining := buffer.Len()
+		value, err := d.decodeField(element, buffer, depth)
+		if err != nil {
+			return nil, err
+		}
+		if buffer.Len() == remaining {
+			return nil, fmt.Errorf("basicList value of element %s does not contain any byte", element.Name)
+		}
+		basicList.Values = append(basicList.Values, value)
+	}
+	return basicList, nil
+}
+
+func (d *StructuredDataDecoder) decodeSubTemplateList(buffer *bytes.Buffer, depth int) (*SubTemplateListValue, error) {
+	/*
+		Encoding format for subTemplateList:
+		 0                   1                   2                   3
+		 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+		+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+		|   Semantic    |         Template ID           |     ...       |
+		+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+		|                subTemplateList Content    ...                 |
+		+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+		(Reference: https://tools.ietf.org/html/rfc6313#section-4.5.2)
+	*/
+	if buffer.Len() < 3 {
+		return nil, fmt.Errorf("subTemplateList header is too short")
+	}
+	if d.GetTemplate == nil {
+		return nil, fmt.Errorf("no template lookup is provided")
+	}
+	semantic, _ := buffer.ReadByte()
+	templateID := binary.BigEndian.Uint16(buffer.Next(2))
+	template, err := d.GetTemplate(templateID)
+	if err != nil {
+		return nil, err
+	}
+	subTemplateList := &SubTemplateListValue{
+		Semantic:   semantic,
+		TemplateID: templateID,
+	}
+	if subTemplateList.Records, err = d.decodeRecords(template, buffer, depth); err != nil {
+		return nil, err
+	}
+	return subTemplateList, nil
+}
+
+func (d *StructuredDataDecoder) decodeSubTemplateMultiList(buffer *bytes.Buffer, depth int) (*SubTemplateMultiListValue, error) {
+	/*
+		Encoding format for subTemplateMultiList:
+		 0                   1                   2                   3
+		 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+		+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+		|   Semantic    |         Template ID X         |Data Records   |
+		+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+		|  Length X     |    Data Record X.1 Content   ...              |
+		+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+		|   ...         |         Template ID Y         |     ...       |
+		+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+		(Reference: https://tools.ietf.org/html/rfc6313#section-4.5.3)
+		The Data Records Length of a block includes the Template ID and the
+		length fields.
+	*/
+	if buffer.Len() < 1 {
+		return nil, fmt.Errorf("subTemplateMultiList header is too short")
+	}
+	if d.GetTemplate == nil {
+		return nil, fmt.Errorf("no template lookup is provided")
+	}
+	semantic, _ := buffer.ReadByte()
+	subTemplateMultiList := &SubTemplateMultiListValue{
+		Semantic: semantic,
+	}
+	for buffer.Len() > 0 {
+		remaining := buffer.Len()
+		if remaining < 4 {
+			return nil, fmt.Errorf("subTemplateMultiList block header is too short")
+		}
+		templateID := binary.BigEndian.Uint16(buffer.Next(2))
+		blockLength := int(binary.BigEndian.Uint16(buffer.Next(2)))
+		if blockLength < 4 || blockLength-4 > buffer.Len() {
+			return nil, fmt.Errorf("subTemplateMultiList block of template %d has invalid length %d with %d remaining bytes", templateID, blockLength, buffer.Len()+4)
+		}
+		template, err := d.GetTemplate(templateID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot find template %d of subTemplateMultiList block: %v", templateID, err)
+		}
+		records, err := d.decodeRecords(template, bytes.NewBuffer(buffer.Next(blockLength-4)), depth)
+		if err != nil {
+			return nil, err
+		}
+		subTemplateMultiList.Blocks = append(subTemplateMultiList.Blocks, &SubTemplateListValue{
+			Semantic:   semantic,
+			TemplateID: templateID,
+			Records:    records,
+		})
+		if buffer.Len() == remaining {
+			return nil, fmt.Errorf("subTemplateMultiList block of template %d does not contain any byte", templateID)
+		}
+	}
+	return subTemplateMultiList, nil
+}
+
+// decodeRecords decodes the records of the given template until the buffer is
+// consumed.
+func (d *StructuredDataDecoder) decodeRecords(template []*InfoElement, buffer *bytes.Buffer, depth int) ([][]InfoElementWithValue, error) {
+	var records [][]InfoElementWithValue
+	for buffer.Len() > 0 {
+		remaining := buffer.Len()
+		record := make([]InfoElementWithValue, len(template))
+		for i, element := range template {
+			var err error
+			if record[i], err = d.decodeField(element, buffer, depth); err != nil {
+				return nil, err
+			}
+		}
+		if buffer.Len() == remaining {
+			return nil, fmt.Errorf("record of structured data does not contain any byte")
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// decodeField decodes the next field of a list, which may itself be structured
+// data nested one level deeper.
+func (d *StructuredDataDecoder) decodeField(element *InfoElement, buffer *bytes.Buffer, depth int) (InfoElementWithValue, error) {
+	length := int(element.Len)
+	if element.Len == VariableLength {
+		var err error
+		if length, err = readVariableLength(buffer); err != nil {
+			return nil, err
+		}
+	}
+	if length > buffer.Len() {
+		return nil, fmt.Errorf("element %s with length %d exceeds the remaining %d bytes", element.Name, length, buffer.Len())
+	}
+	return d.decode(element, buffer.Next(length), depth+1)
+}
+
+// readVariableLength reads the length of a variable-length field, which is
+// encoded in 1 byte, or in 3 bytes if the first byte is 255 (RFC 7011 section 7).
+func readVariableLength(buffer *bytes.Buffer) (int, error) {
+	oneByte, err := buffer.ReadByte()
+	if err != nil {
+		return 0, fmt.Errorf("cannot read the length of the variable-length field")
+	}
+	if oneByte < 255 {
+		return int(oneByte), nil
+	}
+	if buffer.Len() < 2 {
+		return 0, fmt.Errorf("cannot read the length of the variable-length field")
+	}
+	return int(binary.BigEndian.Uint16(buffer.Next(2))), nil
+}
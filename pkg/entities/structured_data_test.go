@@ -0,0 +1,238 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	sourceTransportPortElement  = NewInfoElement("sourceTransportPort", 7, Unsigned16, 0, 2)
+	sourceIPv4AddressElement    = NewInfoElement("sourceIPv4Address", 8, Ipv4Address, 0, 4)
+	basicListElement            = NewInfoElement("basicList", 291, BasicList, 0, VariableLength)
+	subTemplateListElement      = NewInfoElement("subTemplateList", 292, SubTemplateList, 0, VariableLength)
+	subTemplateMultiListElement = NewInfoElement("subTemplateMultiList", 293, SubTemplateMultiList, 0, VariableLength)
+	// subTemplateList with template 300 and one record, which contains
+	// sourceTransportPort 8080 and a basicList with 2 sourceIPv4Address.
+	nestedSubTemplateList = []byte{
+		0xff, 0x01, 0x2c, // semantic, template ID
+		0x1f, 0x90, // sourceTransportPort
+		13,                           // basicList length
+		0x03, 0x00, 0x08, 0x00, 0x04, // semantic, field ID, element length
+		1, 2, 3, 4, 5, 6, 7, 8, // sourceIPv4Address values
+	}
+)
+
+func newTestStructuredDataDecoder(maxDepth int) *StructuredDataDecoder {
+	return &StructuredDataDecoder{
+		GetInfoElement: func(elementID uint16, enterpriseID uint32) (*InfoElement, error) {
+			if elementID == 8 && enterpriseID == 0 {
+				return sourceIPv4AddressElement, nil
+			}
+			return nil, fmt.Errorf("element %d with enterpriseID %d not found", elementID, enterpriseID)
+		},
+		GetTemplate: func(templateID uint16) ([]*InfoElement, error) {
+			if templateID == 300 {
+				return []*InfoElement{sourceTransportPortElement, basicListElement}, nil
+			}
+			if templateID == 302 {
+				return []*InfoElement{sourceIPv4AddressElement}, nil
+			}
+			return nil, fmt.Errorf("template %d not found", templateID)
+		},
+		MaxDepth: maxDepth,
+	}
+}
+
+func TestStructuredDataDecoder_NestedLists(t *testing.T) {
+	decoder := newTestStructuredDataDecoder(0)
+	ie, err := decoder.Decode(subTemplateListElement, nestedSubTemplateList)
+	require.NoError(t, err)
+	subTemplateList := ie.GetSubTemplateListValue()
+	require.NotNil(t, subTemplateList)
+	assert.Equal(t, uint8(0xff), subTemplateList.Semantic)
+	assert.Equal(t, uint16(300), subTemplateList.TemplateID)
+	require.Len(t, subTemplateList.Records, 1)
+	record := subTemplateList.Records[0]
+	assert.Equal(t, uint16(8080), record[0].GetUnsigned16Value())
+	basicList := record[1].GetBasicListValue()
+	require.NotNil(t, basicList)
+	assert.Equal(t, uint8(0x03), basicList.Semantic)
+	assert.Equal(t, sourceIPv4AddressElement, basicList.Element)
+	require.Len(t, basicList.Values, 2)
+	assert.Equal(t, net.IP([]byte{1, 2, 3, 4}), basicList.Values[0].GetIPAddressValue())
+	assert.Equal(t, net.IP([]byte{5, 6, 7, 8}), basicList.Values[1].GetIPAddressValue())
+
+	expectedValue := []map[string]interface{}{
+		{
+			"sourceTransportPort": uint16(8080),
+			"basicList":           []interface{}{net.IP([]byte{1, 2, 3, 4}), net.IP([]byte{5, 6, 7, 8})},
+		},
+	}
+	assert.Equal(t, expectedValue, getElementMapValue(ie))
+}
+
+func TestStructuredDataDecoder_SubTemplateListRecords(t *testing.T) {
+	decoder := newTestStructuredDataDecoder(0)
+	ie, err := decoder.Decode(subTemplateListElement, nestedSubTemplateList)
+	require.NoError(t, err)
+	records := ie.GetSubTemplateListValue().GetRecords()
+	require.Len(t, records, 1)
+	assert.Equal(t, uint16(300), records[0].GetTemplateID())
+	port, _, exist := records[0].GetInfoElementWithValue("sourceTransportPort")
+	require.True(t, exist)
+	assert.Equal(t, uint16(8080), port.GetUnsigned16Value())
+	_, _, exist = records[0].GetInfoElementWithValue("basicList")
+	assert.True(t, exist)
+
+	// An empty list has no records.
+	ie, err = decoder.Decode(subTemplateListElement, []byte{0xff, 0x01, 0x2c})
+	require.NoError(t, err)
+	assert.Empty(t, ie.GetSubTemplateListValue().Records)
+	assert.Empty(t, ie.GetSubTemplateListValue().GetRecords())
+}
+
+func TestStructuredDataDecoder_SubTemplateMultiList(t *testing.T) {
+	decoder := newTestStructuredDataDecoder(0)
+	subTemplateMultiList := []byte{
+		0x03,                   // semantic
+		0x01, 0x2e, 0x00, 0x0c, // template ID 302, block length
+		1, 2, 3, 4, 5, 6, 7, 8, // 2 records of sourceIPv4Address
+		0x01, 0x2c, 0x00, 0x14, // template ID 300, block length
+	}
+	// The record of template 300 of nestedSubTemplateList.
+	subTemplateMultiList = append(subTemplateMultiList, nestedSubTemplateList[3:]...)
+	ie, err := decoder.Decode(subTemplateMultiListElement, subTemplateMultiList)
+	require.NoError(t, err)
+	value := ie.GetSubTemplateMultiListValue()
+	require.NotNil(t, value)
+	assert.Equal(t, uint8(0x03), value.Semantic)
+	require.Len(t, value.Blocks, 2)
+	assert.Equal(t, uint16(302), value.Blocks[0].TemplateID)
+	assert.Equal(t, uint16(300), value.Blocks[1].TemplateID)
+	records := value.GetRecordsByTemplateID()
+	require.Len(t, records[302], 2)
+	assert.Equal(t, net.IP([]byte{5, 6, 7, 8}), records[302][1][0].GetIPAddressValue())
+	require.Len(t, records[300], 1)
+	assert.Equal(t, uint16(8080), records[300][0][0].GetUnsigned16Value())
+
+	expectedValue := map[uint16][]map[string]interface{}{
+		300: {
+			{
+				"sourceTransportPort": uint16(8080),
+				"basicList":           []interface{}{net.IP([]byte{1, 2, 3, 4}), net.IP([]byte{5, 6, 7, 8})},
+			},
+		},
+		302: {
+			{"sourceIPv4Address": net.IP([]byte{1, 2, 3, 4})},
+			{"sourceIPv4Address": net.IP([]byte{5, 6, 7, 8})},
+		},
+	}
+	assert.Equal(t, expectedValue, getElementMapValue(ie))
+
+	// The block length exceeds the remaining bytes.
+	malformed := append([]byte(nil), subTemplateMultiList...)
+	malformed[4] = 0x28
+	_, err = decoder.Decode(subTemplateMultiListElement, malformed)
+	assert.ErrorContains(t, err, "block of template 302 has invalid length 40")
+	// The block length does not include the block header.
+	malformed[4] = 0x02
+	_, err = decoder.Decode(subTemplateMultiListElement, malformed)
+	assert.ErrorContains(t, err, "block of template 302 has invalid length 2")
+	// Unknown template.
+	_, err = decoder.Decode(subTemplateMultiListElement, []byte{0x03, 0x01, 0x2f, 0x00, 0x04})
+	assert.ErrorContains(t, err, "cannot find template 303 of subTemplateMultiList block")
+}
+
+func TestEncodeSubTemplateList(t *testing.T) {
+	interfaceNameElement := NewInfoElement("interfaceName", 82, String, 0, VariableLength)
+	decoder := &StructuredDataDecoder{
+		GetTemplate: func(templateID uint16) ([]*InfoElement, error) {
+			return []*InfoElement{sourceTransportPortElement, interfaceNameElement}, nil
+		},
+	}
+	for _, numRecords := range []int{0, 2, 100} {
+		value := &SubTemplateListValue{Semantic: SemanticAllOf, TemplateID: 300}
+		for i := 0; i < numRecords; i++ {
+			value.Records = append(value.Records, []InfoElementWithValue{
+				NewUnsigned16InfoElement(sourceTransportPortElement, uint16(i)),
+				NewStringInfoElement(interfaceNameElement, fmt.Sprintf("eth%d", i)),
+			})
+		}
+		record := NewDataRecord(300, 1, 0, false)
+		require.NoError(t, record.AddInfoElement(NewSubTemplateListInfoElement(subTemplateListElement, value)))
+		buffer := record.GetBuffer()
+		require.Len(t, buffer, record.GetRecordLength())
+
+		// Skip the variable-length prefix, which is 3 bytes for long lists.
+		prefixLength := 1
+		if buffer[0] == 255 {
+			prefixLength = 3
+		}
+		ie, err := decoder.Decode(subTemplateListElement, buffer[prefixLength:])
+		require.NoError(t, err)
+		assert.Equal(t, SemanticAllOf, ie.GetSubTemplateListValue().Semantic)
+		assert.Equal(t, uint16(300), ie.GetSubTemplateListValue().TemplateID)
+		records := ie.GetSubTemplateListValue().Records
+		require.Len(t, records, numRecords)
+		if numRecords > 0 {
+			assert.Equal(t, uint16(numRecords-1), records[numRecords-1][0].GetUnsigned16Value())
+			assert.Equal(t, fmt.Sprintf("eth%d", numRecords-1), records[numRecords-1][1].GetStringValue())
+		}
+	}
+	assert.Error(t, encodeInfoElementValueToBuff(NewSubTemplateListInfoElement(subTemplateListElement, nil), make([]byte, 4), 0))
+}
+
+func TestStructuredDataDecoder_MaxDepth(t *testing.T) {
+	// The basicList nested in the subTemplateList has a depth of 2.
+	decoder := newTestStructuredDataDecoder(1)
+	_, err := decoder.Decode(subTemplateListElement, nestedSubTemplateList)
+	assert.ErrorContains(t, err, "exceeds the maximum nesting depth 1")
+	decoder = newTestStructuredDataDecoder(2)
+	_, err = decoder.Decode(subTemplateListElement, nestedSubTemplateList)
+	assert.NoError(t, err)
+}
+
+func TestStructuredDataDecoder_MalformedList(t *testing.T) {
+	decoder := newTestStructuredDataDecoder(0)
+	// The basicList length exceeds the remaining bytes.
+	_, err := decoder.Decode(subTemplateListElement, nestedSubTemplateList[:len(nestedSubTemplateList)-1])
+	assert.Error(t, err)
+	// Unknown template.
+	_, err = decoder.Decode(subTemplateListElement, []byte{0xff, 0x01, 0x2d})
+	assert.Error(t, err)
+}
+
+func TestStructuredDataDecoder_ZeroLengthField(t *testing.T) {
+	decoder := newTestStructuredDataDecoder(0)
+	// The basicList declares an element length of 0.
+	_, err := decoder.Decode(basicListElement, []byte{0x03, 0x00, 0x08, 0x00, 0x00, 1, 2, 3, 4})
+	assert.ErrorContains(t, err, "invalid length 0 for basicList element sourceIPv4Address")
+
+	// The records of a template with a zero-length element do not consume
+	// any byte.
+	decoder.GetTemplate = func(templateID uint16) ([]*InfoElement, error) {
+		return []*InfoElement{NewInfoElement("paddingOctets", 210, OctetArray, 0, 0)}, nil
+	}
+	_, err = decoder.Decode(subTemplateListElement, []byte{0xff, 0x01, 0x2c, 0x00})
+	assert.ErrorContains(t, err, "does not contain any byte")
+	_, err = decoder.Decode(subTemplateMultiListElement, []byte{0x03, 0x01, 0x2c, 0x00, 0x05, 0x00})
+	assert.ErrorContains(t, err, "does not contain any byte")
+}
@@ -66,6 +66,20 @@ func (mr *MockRecordMockRecorder) AddInfoElement(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddInfoElement", reflect.TypeOf((*MockRecord)(nil).AddInfoElement), arg0)
 }
 
+// FlowKeyHash mocks base method.
+func (m *MockRecord) FlowKeyHash(arg0 []string) uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlowKeyHash", arg0)
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// FlowKeyHash indicates an expected call of FlowKeyHash.
+func (mr *MockRecordMockRecorder) FlowKeyHash(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlowKeyHash", reflect.TypeOf((*MockRecord)(nil).FlowKeyHash), arg0)
+}
+
 // GetBuffer mocks base method.
 func (m *MockRecord) GetBuffer() []byte {
 	m.ctrl.T.Helper()
@@ -94,6 +108,20 @@ func (mr *MockRecordMockRecorder) GetElementMap() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetElementMap", reflect.TypeOf((*MockRecord)(nil).GetElementMap))
 }
 
+// GetElementMapWithOptions mocks base method.
+func (m *MockRecord) GetElementMapWithOptions(arg0 entities.ElementMapOptions) map[string]any {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetElementMapWithOptions", arg0)
+	ret0, _ := ret[0].(map[string]any)
+	return ret0
+}
+
+// GetElementMapWithOptions indicates an expected call of GetElementMapWithOptions.
+func (mr *MockRecordMockRecorder) GetElementMapWithOptions(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetElementMapWithOptions", reflect.TypeOf((*MockRecord)(nil).GetElementMapWithOptions), arg0)
+}
+
 // GetFieldCount mocks base method.
 func (m *MockRecord) GetFieldCount() uint16 {
 	m.ctrl.T.Helper()
@@ -193,3 +221,17 @@ func (mr *MockRecordMockRecorder) PrepareRecord() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrepareRecord", reflect.TypeOf((*MockRecord)(nil).PrepareRecord))
 }
+
+// ToCEF mocks base method.
+func (m *MockRecord) ToCEF(arg0 entities.CEFOptions) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ToCEF", arg0)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ToCEF indicates an expected call of ToCEF.
+func (mr *MockRecordMockRecorder) ToCEF(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ToCEF", reflect.TypeOf((*MockRecord)(nil).ToCEF), arg0)
+}
@@ -52,6 +52,20 @@ func (m *MockSet) EXPECT() *MockSetMockRecorder {
 	return m.recorder
 }
 
+// AddOptionsTemplateRecord mocks base method.
+func (m *MockSet) AddOptionsTemplateRecord(arg0 []entities.InfoElementWithValue, arg1 int, arg2 uint16) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOptionsTemplateRecord", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddOptionsTemplateRecord indicates an expected call of AddOptionsTemplateRecord.
+func (mr *MockSetMockRecorder) AddOptionsTemplateRecord(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOptionsTemplateRecord", reflect.TypeOf((*MockSet)(nil).AddOptionsTemplateRecord), arg0, arg1, arg2)
+}
+
 // AddRecord mocks base method.
 func (m *MockSet) AddRecord(arg0 []entities.InfoElementWithValue, arg1 uint16) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,163 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+const defaultHTTP2RequestTimeout = 10 * time.Second
+
+// HTTP2ExporterInput configures an HTTP2ExportingProcess.
+type HTTP2ExporterInput struct {
+	// URL is the endpoint to which the IPFIX messages are POSTed. With the
+	// "https" scheme, HTTP/2 is negotiated during the TLS handshake. With the
+	// "http" scheme, HTTP/2 is used without TLS (h2c with prior knowledge).
+	URL string
+	// Headers are added to every request, e.g. for authentication.
+	Headers map[string]string
+	// ObservationDomainID is the observation domain ID of the messages.
+	ObservationDomainID uint32
+	// TLSClientConfig is set to verify the server certificate with the given
+	// CA, and to authenticate with a client certificate. By default, the
+	// system roots are used.
+	TLSClientConfig *ExporterTLSClientConfig
+	// RequestTimeout is the timeout of every request. Default is 10s.
+	RequestTimeout time.Duration
+}
+
+// HTTP2ExportingProcess sends IPFIX messages to a collector behind an HTTP/2
+// endpoint, e.g. for cloud ingestion. Every message is the body of a POST
+// request, and the requests share a persistent HTTP/2 connection. As with TCP,
+// templates are sent once and are not refreshed. Sending fails if the server
+// does not respond with a 2xx status code.
+type HTTP2ExportingProcess struct {
+	*ExportingProcess
+}
+
+// InitHTTP2ExportingProcess creates an HTTP2ExportingProcess. The connection
+// to the server is established when the first message is sent.
+func InitHTTP2ExportingProcess(input HTTP2ExporterInput) (*HTTP2ExportingProcess, error) {
+	endpoint, err := url.Parse(input.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %s: %v", input.URL, err)
+	}
+	transport := &http2.Transport{}
+	switch endpoint.Scheme {
+	case "https":
+		if input.TLSClientConfig != nil {
+			if transport.TLSClientConfig, err = createClientConfig(input.TLSClientConfig); err != nil {
+				return nil, err
+			}
+		}
+	case "http":
+		transport.AllowHTTP = true
+		transport.DialTLSContext = func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, addr)
+		}
+	default:
+		return nil, fmt.Errorf("URL scheme %s is not supported, use http or https", endpoint.Scheme)
+	}
+	timeout := input.RequestTimeout
+	if timeout == 0 {
+		timeout = defaultHTTP2RequestTimeout
+	}
+	conn := &httpPostConn{
+		client:  &http.Client{Transport: transport, Timeout: timeout},
+		url:     endpoint.String(),
+		headers: input.Headers,
+	}
+	return &HTTP2ExportingProcess{
+		ExportingProcess: &ExportingProcess{
+			connToCollector:   conn,
+			obsDomainID:       input.ObservationDomainID,
+			templateID:        startTemplateID,
+			templatesMap:      make(map[uint16]templateValue),
+			templateRefCh:     make(chan struct{}),
+			collectorProtocol: "http2",
+			startTime:         time.Now(),
+		},
+	}, nil
+}
+
+// httpPostConn is the connection to the collector of an HTTP2ExportingProcess,
+// which POSTs every message written to it.
+type httpPostConn struct {
+	client  *http.Client
+	url     string
+	headers map[string]string
+}
+
+func (c *httpPostConn) Write(msg []byte) (int, error) {
+	request, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(msg))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/octet-stream")
+	for key, value := range c.headers {
+		request.Header.Set(key, value)
+	}
+	response, err := c.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	// Read the body so that the stream is fully consumed.
+	io.Copy(io.Discard, response.Body)
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return 0, fmt.Errorf("collector responded with status %s", response.Status)
+	}
+	return len(msg), nil
+}
+
+func (c *httpPostConn) Read(b []byte) (int, error) {
+	return 0, fmt.Errorf("reading from an HTTP/2 collector is not supported")
+}
+
+func (c *httpPostConn) Close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+func (c *httpPostConn) LocalAddr() net.Addr {
+	return nil
+}
+
+func (c *httpPostConn) RemoteAddr() net.Addr {
+	return nil
+}
+
+func (c *httpPostConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *httpPostConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *httpPostConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"github.com/vmware/go-ipfix/pkg/entities"
+	"github.com/vmware/go-ipfix/pkg/registry"
+)
+
+type postedMsg struct {
+	protoMajor    int
+	authorization string
+	body          []byte
+}
+
+func TestHTTP2ExportingProcess(t *testing.T) {
+	element, err := registry.GetInfoElement("sourceIPv4Address", registry.IANAEnterpriseID)
+	require.NoError(t, err)
+	newHandler := func(msgCh chan<- postedMsg) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, http.MethodPost, r.Method)
+			msgCh <- postedMsg{r.ProtoMajor, r.Header.Get("Authorization"), body}
+		})
+	}
+
+	for _, tc := range []struct {
+		name        string
+		startServer func(handler http.Handler) (*httptest.Server, *ExporterTLSClientConfig)
+	}{
+		{
+			name: "TLS",
+			startServer: func(handler http.Handler) (*httptest.Server, *ExporterTLSClientConfig) {
+				server := httptest.NewUnstartedServer(handler)
+				server.EnableHTTP2 = true
+				server.StartTLS()
+				caData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+				return server, &ExporterTLSClientConfig{CAData: caData}
+			},
+		},
+		{
+			name: "cleartext",
+			startServer: func(handler http.Handler) (*httptest.Server, *ExporterTLSClientConfig) {
+				return httptest.NewServer(h2c.NewHandler(handler, &http2.Server{})), nil
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			msgCh := make(chan postedMsg, 2)
+			server, tlsClientConfig := tc.startServer(newHandler(msgCh))
+			defer server.Close()
+			exporter, err := InitHTTP2ExportingProcess(HTTP2ExporterInput{
+				URL:                 server.URL + "/ipfix",
+				Headers:             map[string]string{"Authorization": "Bearer token"},
+				ObservationDomainID: 1,
+				TLSClientConfig:     tlsClientConfig,
+			})
+			require.NoError(t, err)
+			defer exporter.CloseConnToCollector()
+
+			templateID := exporter.NewTemplateID()
+			templateSet := entities.NewSet(false)
+			require.NoError(t, templateSet.PrepareSet(entities.Template, templateID))
+			require.NoError(t, templateSet.AddRecord([]entities.InfoElementWithValue{entities.NewIPAddressInfoElement(element, nil)}, templateID))
+			bytesSent, err := exporter.SendSet(templateSet)
+			require.NoError(t, err)
+			assert.Equal(t, 28, bytesSent)
+			dataSet := entities.NewSet(false)
+			require.NoError(t, dataSet.PrepareSet(entities.Data, templateID))
+			require.NoError(t, dataSet.AddRecord([]entities.InfoElementWithValue{entities.NewIPAddressInfoElement(element, net.IP{1, 2, 3, 4})}, templateID))
+			bytesSent, err = exporter.SendSet(dataSet)
+			require.NoError(t, err)
+			assert.Equal(t, 24, bytesSent)
+
+			msg := <-msgCh
+			assert.Equal(t, 2, msg.protoMajor)
+			assert.Equal(t, "Bearer token", msg.authorization)
+			require.Len(t, msg.body, 28)
+			assert.Equal(t, []byte{0, 10, 0, 28}, msg.body[0:4])
+			assert.Equal(t, []byte{0, 0, 0, 1}, msg.body[12:16])
+			assert.Equal(t, []byte{0, 2, 0, 12, 1, 0, 0, 1, 0, 8, 0, 4}, msg.body[16:28])
+			msg = <-msgCh
+			require.Len(t, msg.body, 24)
+			// The sequence number is the number of data records sent before
+			// the message.
+			assert.Equal(t, []byte{0, 0, 0, 0}, msg.body[8:12])
+			assert.Equal(t, []byte{1, 0, 0, 8, 1, 2, 3, 4}, msg.body[16:24])
+		})
+	}
+}
+
+func TestHTTP2ExportingProcess_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}), &http2.Server{}))
+	defer server.Close()
+	exporter, err := InitHTTP2ExportingProcess(HTTP2ExporterInput{
+		URL:                 server.URL,
+		ObservationDomainID: 1,
+	})
+	require.NoError(t, err)
+	defer exporter.CloseConnToCollector()
+	element, err := registry.GetInfoElement("sourceIPv4Address", registry.IANAEnterpriseID)
+	require.NoError(t, err)
+	templateID := exporter.NewTemplateID()
+	templateSet := entities.NewSet(false)
+	require.NoError(t, templateSet.PrepareSet(entities.Template, templateID))
+	require.NoError(t, templateSet.AddRecord([]entities.InfoElementWithValue{entities.NewIPAddressInfoElement(element, nil)}, templateID))
+	_, err = exporter.SendSet(templateSet)
+	assert.ErrorContains(t, err, "collector responded with status 503 Service Unavailable")
+
+	_, err = InitHTTP2ExportingProcess(HTTP2ExporterInput{URL: "ftp://127.0.0.1"})
+	assert.Error(t, err)
+}
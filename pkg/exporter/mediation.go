@@ -0,0 +1,66 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/vmware/go-ipfix/pkg/entities"
+	"github.com/vmware/go-ipfix/pkg/registry"
+)
+
+// NewOriginalExporterElements creates the elements which record the provenance
+// of a flow exported by a mediator (RFC 6183): originalExporterIPv4Address or
+// originalExporterIPv6Address, depending on the family of originalExporter,
+// followed by originalObservationDomainId. They are meant to be appended to
+// the elements of the template and data records re-exported by the mediator.
+func NewOriginalExporterElements(originalExporter net.IP, originalObsDomainID uint32) ([]entities.InfoElementWithValue, error) {
+	name := "originalExporterIPv6Address"
+	if ip := originalExporter.To4(); ip != nil {
+		name = "originalExporterIPv4Address"
+		originalExporter = ip
+	} else if originalExporter.To16() == nil {
+		return nil, fmt.Errorf("invalid original exporter address %v", originalExporter)
+	}
+	addressElement, err := registry.GetInfoElement(name, registry.IANAEnterpriseID)
+	if err != nil {
+		return nil, err
+	}
+	obsDomainElement, err := registry.GetInfoElement("originalObservationDomainId", registry.IANAEnterpriseID)
+	if err != nil {
+		return nil, err
+	}
+	return []entities.InfoElementWithValue{
+		entities.NewIPAddressInfoElement(addressElement, originalExporter),
+		entities.NewUnsigned32InfoElement(obsDomainElement, originalObsDomainID),
+	}, nil
+}
+
+// AppendOriginalExporterElements appends the original exporter elements, see
+// NewOriginalExporterElements, to the elements of a record received in the
+// given message. The original exporter is the export address of the message,
+// and the original observation domain is the one of the message.
+func AppendOriginalExporterElements(elements []entities.InfoElementWithValue, message *entities.Message) ([]entities.InfoElementWithValue, error) {
+	originalExporter := net.ParseIP(message.GetExportAddress())
+	if originalExporter == nil {
+		return nil, fmt.Errorf("invalid export address %q of message", message.GetExportAddress())
+	}
+	originalExporterElements, err := NewOriginalExporterElements(originalExporter, message.GetObsDomainID())
+	if err != nil {
+		return nil, err
+	}
+	return append(elements, originalExporterElements...), nil
+}
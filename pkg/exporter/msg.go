@@ -15,6 +15,9 @@
 package exporter
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
 	"fmt"
 	"time"
 
@@ -22,12 +25,20 @@ import (
 )
 
 func CreateIPFIXMsg(set entities.Set, obsDomainID uint32, seqNumber uint32, exportTime time.Time) ([]byte, error) {
-	// Create a new message and use it to send the set.
+	return createIPFIXMsgWithSets([]entities.Set{set}, obsDomainID, seqNumber, exportTime)
+}
+
+// createIPFIXMsgWithSets creates a single IPFIX message containing all the given sets.
+func createIPFIXMsgWithSets(sets []entities.Set, obsDomainID uint32, seqNumber uint32, exportTime time.Time) ([]byte, error) {
+	// Create a new message and use it to send the sets.
 	msg := entities.NewMessage(false)
 
-	// Check if message is exceeding the limit after adding the set. Include message
+	// Check if message is exceeding the limit after adding the sets. Include message
 	// header length too.
-	msgLen := entities.MsgHeaderLength + set.GetSetLength()
+	msgLen := entities.MsgHeaderLength
+	for _, set := range sets {
+		msgLen += set.GetSetLength()
+	}
 	if msgLen > entities.MaxSocketMsgSize {
 		// This is applicable for both TCP and UDP sockets.
 		return nil, fmt.Errorf("message size exceeds max socket buffer size")
@@ -44,13 +55,36 @@ func CreateIPFIXMsg(set entities.Set, obsDomainID uint32, seqNumber uint32, expo
 
 	bytesSlice := make([]byte, msgLen)
 	copy(bytesSlice[:entities.MsgHeaderLength], msg.GetMsgHeader())
-	copy(bytesSlice[entities.MsgHeaderLength:entities.MsgHeaderLength+entities.SetHeaderLen], set.GetHeaderBuffer())
-	index := entities.MsgHeaderLength + entities.SetHeaderLen
-	for _, record := range set.GetRecords() {
-		len := record.GetRecordLength()
-		copy(bytesSlice[index:index+len], record.GetBuffer())
-		index += len
+	index := entities.MsgHeaderLength
+	for _, set := range sets {
+		copy(bytesSlice[index:index+entities.SetHeaderLen], set.GetHeaderBuffer())
+		index += entities.SetHeaderLen
+		for _, record := range set.GetRecords() {
+			len := record.GetRecordLength()
+			copy(bytesSlice[index:index+len], record.GetBuffer())
+			index += len
+		}
 	}
 
 	return bytesSlice, nil
 }
+
+// CreateCompressedIPFIXMsg gzip-compresses the given IPFIX message and prepends
+// the wrapper header (flags and payload length) expected by collectors that are
+// configured to decompress messages. This is not part of RFC7011 and should only
+// be used with collectors that support it.
+func CreateCompressedIPFIXMsg(msg []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(make([]byte, entities.CompressedMsgHeaderLength))
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(msg); err != nil {
+		return nil, fmt.Errorf("error when compressing message: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("error when compressing message: %v", err)
+	}
+	bytesSlice := buf.Bytes()
+	binary.BigEndian.PutUint16(bytesSlice[0:2], entities.CompressedMsgFlagGzip)
+	binary.BigEndian.PutUint32(bytesSlice[2:6], uint32(len(bytesSlice)-entities.CompressedMsgHeaderLength))
+	return bytesSlice, nil
+}
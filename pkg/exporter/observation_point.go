@@ -0,0 +1,128 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"github.com/vmware/go-ipfix/pkg/entities"
+	"github.com/vmware/go-ipfix/pkg/registry"
+)
+
+// observationPoint is the observation point declared with SetObservationPoint.
+type observationPoint struct {
+	id         uint64
+	domainName string
+	// templateID is the ID of the observation point options template
+	templateID uint16
+	// pending indicates whether the options record is sent before the next
+	// message
+	pending bool
+}
+
+var observationPointOptionsElements = []string{"observationDomainId", "observationPointId", "observationDomainName"}
+
+// SetObservationPoint declares the observation point of the exported flows and
+// the name of the observation domain. They are sent to the collector in an
+// options record, with observationDomainId as scope, before the next message
+// sent by the exporting process, and every time the templates are refreshed.
+// A template ID is allocated for the options template when it is first called.
+func (ep *ExportingProcess) SetObservationPoint(observationPointID uint64, observationDomainName string) {
+	ep.observationPointMutex.Lock()
+	defer ep.observationPointMutex.Unlock()
+	if ep.observationPoint == nil {
+		ep.observationPoint = &observationPoint{templateID: ep.NewTemplateID()}
+	}
+	ep.observationPoint.id = observationPointID
+	ep.observationPoint.domainName = observationDomainName
+	ep.observationPoint.pending = true
+}
+
+// sendPendingObservationPoint sends the observation point options template and
+// data record if they have not been sent since SetObservationPoint was called.
+func (ep *ExportingProcess) sendPendingObservationPoint() error {
+	ep.observationPointMutex.Lock()
+	if ep.observationPoint == nil || !ep.observationPoint.pending || ep.sendJSONRecord {
+		ep.observationPointMutex.Unlock()
+		return nil
+	}
+	ep.observationPoint.pending = false
+	point := *ep.observationPoint
+	ep.observationPointMutex.Unlock()
+	if err := ep.sendObservationPointOptions(point, true); err != nil {
+		ep.observationPointMutex.Lock()
+		ep.observationPoint.pending = true
+		ep.observationPointMutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+// refreshObservationPoint sends the observation point options data record
+// again. The options template is refreshed along with the other templates.
+func (ep *ExportingProcess) refreshObservationPoint() error {
+	ep.observationPointMutex.Lock()
+	if ep.observationPoint == nil || ep.observationPoint.pending {
+		ep.observationPointMutex.Unlock()
+		return nil
+	}
+	point := *ep.observationPoint
+	ep.observationPointMutex.Unlock()
+	return ep.sendObservationPointOptions(point, false)
+}
+
+// sendObservationPointOptions sends the observation point options data record,
+// preceded by the options template if sendTemplate is true.
+func (ep *ExportingProcess) sendObservationPointOptions(point observationPoint, sendTemplate bool) error {
+	elements := make([]*entities.InfoElement, len(observationPointOptionsElements))
+	for i, name := range observationPointOptionsElements {
+		element, err := registry.GetInfoElement(name, registry.IANAEnterpriseID)
+		if err != nil {
+			return err
+		}
+		elements[i] = element
+	}
+	if sendTemplate {
+		templateElements := make([]entities.InfoElementWithValue, len(elements))
+		for i, element := range elements {
+			var err error
+			if templateElements[i], err = entities.DecodeAndCreateInfoElementWithValue(element, nil); err != nil {
+				return err
+			}
+		}
+		templateSet := entities.NewSet(false)
+		if err := templateSet.PrepareSet(entities.OptionsTemplate, point.templateID); err != nil {
+			return err
+		}
+		if err := templateSet.AddOptionsTemplateRecord(templateElements, 1, point.templateID); err != nil {
+			return err
+		}
+		if _, err := ep.SendSet(templateSet); err != nil {
+			return err
+		}
+	}
+	dataSet := entities.NewSet(false)
+	if err := dataSet.PrepareSet(entities.Data, point.templateID); err != nil {
+		return err
+	}
+	dataElements := []entities.InfoElementWithValue{
+		entities.NewUnsigned32InfoElement(elements[0], ep.obsDomainID),
+		entities.NewUnsigned64InfoElement(elements[1], point.id),
+		entities.NewStringInfoElement(elements[2], point.domainName),
+	}
+	if err := dataSet.AddRecord(dataElements, point.templateID); err != nil {
+		return err
+	}
+	_, err := ep.SendSet(dataSet)
+	return err
+}
@@ -18,11 +18,14 @@ import (
 	"bytes"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pion/dtls/v2"
@@ -38,6 +41,8 @@ const defaultJSONBufferLen = 5000
 type templateValue struct {
 	elements      []*entities.InfoElement
 	minDataRecLen uint16
+	// scopeFieldCount is non-zero for options templates
+	scopeFieldCount int
 }
 
 //  1. Tested one exportingProcess process per exporter. Can support multiple collector scenario by
@@ -59,6 +64,42 @@ type ExportingProcess struct {
 	templateMutex   sync.Mutex
 	sendJSONRecord  bool
 	jsonBufferLen   int
+	// compressMessages indicates whether each IPFIX message is gzip-compressed
+	// and wrapped with a header before sending.
+	compressMessages bool
+	// jsonNameCanonicalization indicates whether enterprise-specific elements are
+	// keyed by their base name in JSON records.
+	jsonNameCanonicalization bool
+	// samplingConfig is exported through options records when set
+	samplingConfig            *SamplingConfig
+	samplingOptionsTemplateID uint16
+	// reconnectPolicy is set to reconnect to the collector on failures
+	reconnectPolicy *ReconnectPolicy
+	// dial creates a new connection to the collector
+	dial func() (net.Conn, error)
+	// connMutex protects connToCollector and the reconnection state
+	connMutex sync.Mutex
+	// reconnecting indicates whether the reconnection goroutine is running
+	reconnecting bool
+	// bufferedMsgs are the messages to send once reconnected
+	bufferedMsgs  [][]byte
+	bufferedBytes int
+	// collectorProtocol is the transport protocol of the connection
+	collectorProtocol string
+	// withdrawnTemplateIDs are the IDs of withdrawn templates, which are
+	// reused by NewTemplateID
+	withdrawnTemplateIDs []uint16
+	// startTime is the time when the exporting process was created, including
+	// its monotonic clock reading
+	startTime time.Time
+	// rateLimiter limits the rate of the messages sent to the collector
+	rateLimiter *rateLimiter
+	// observationPoint is exported through options records when set
+	observationPoint      *observationPoint
+	observationPointMutex sync.Mutex
+	// numRedundantTemplateSets is the number of template sets not sent
+	// because they only contained active templates
+	numRedundantTemplateSets atomic.Uint64
 }
 
 type ExporterTLSClientConfig struct {
@@ -88,6 +129,29 @@ type ExporterInput struct {
 	SendJSONRecord    bool
 	JSONBufferLen     int
 	CheckConnInterval time.Duration
+	// CompressMessages enables gzip compression of every IPFIX message. This is
+	// a non-standard framing and the collector must be configured with
+	// DecompressMessages to be able to decode the messages.
+	CompressMessages bool
+	// JSONNameCanonicalization applies entities.ElementMapOptions.NameCanonicalization
+	// to the JSON records. Elements of different enterprises which share the same
+	// name keep their enterprise-qualified names.
+	JSONNameCanonicalization bool
+	// SamplingConfig is set when the exported flows are sampled. The sampling
+	// configuration is then sent to the collector in an options record when the
+	// exporting process is initialized, so that the collector can scale the
+	// flow statistics. A template ID is allocated for the options template.
+	SamplingConfig *SamplingConfig
+	// ReconnectPolicy is set to keep the exporting process running when the
+	// collector is unavailable. InitExportingProcess then succeeds even if the
+	// collector cannot be reached, and the messages are buffered until the
+	// connection is established. By default, sending fails when the collector
+	// is unavailable.
+	ReconnectPolicy *ReconnectPolicy
+	// RateLimit is set to limit the rate of the messages sent to the collector,
+	// so that the collector is not overwhelmed. By default, messages are sent
+	// as fast as possible.
+	RateLimit *RateLimit
 }
 
 // InitExportingProcess takes in collector address(net.Addr format), obsID(observation ID)
@@ -102,60 +166,36 @@ type ExporterInput struct {
 // JSONBufferLen is recommended for sending json record. If not given a valid value,
 // we consider a default 5000B.
 func InitExportingProcess(input ExporterInput) (*ExportingProcess, error) {
-	var conn net.Conn
-	var err error
-	if input.TLSClientConfig != nil {
-		tlsConfig := input.TLSClientConfig
-		if input.CollectorProtocol == "tcp" { // use TLS
-			config, configErr := createClientConfig(tlsConfig)
-			if configErr != nil {
-				return nil, configErr
-			}
-			conn, err = tls.Dial(input.CollectorProtocol, input.CollectorAddress, config)
-			if err != nil {
-				klog.Errorf("Cannot the create the tls connection to the Collector %s: %v", input.CollectorAddress, err)
-				return nil, err
-			}
-		} else if input.CollectorProtocol == "udp" { // use DTLS
-			// TODO: support client authentication
-			if len(tlsConfig.CertData) > 0 || len(tlsConfig.KeyData) > 0 {
-				klog.Error("Client-authentication is not supported yet for DTLS, cert and key data will be ignored")
-			}
-			roots := x509.NewCertPool()
-			ok := roots.AppendCertsFromPEM(tlsConfig.CAData)
-			if !ok {
-				return nil, fmt.Errorf("failed to parse root certificate")
-			}
-			config := &dtls.Config{
-				RootCAs:              roots,
-				ExtendedMasterSecret: dtls.RequireExtendedMasterSecret,
-				ServerName:           tlsConfig.ServerName,
-			}
-			udpAddr, err := net.ResolveUDPAddr(input.CollectorProtocol, input.CollectorAddress)
-			if err != nil {
-				return nil, err
-			}
-			conn, err = dtls.Dial(udpAddr.Network(), udpAddr, config)
-			if err != nil {
-				klog.Errorf("Cannot the create the dtls connection to the Collector %s: %v", udpAddr.String(), err)
-				return nil, err
-			}
-		}
-	} else {
-		conn, err = net.Dial(input.CollectorProtocol, input.CollectorAddress)
-		if err != nil {
-			klog.Errorf("Cannot the create the connection to the Collector %s: %v", input.CollectorAddress, err)
-			return nil, err
-		}
+	conn, err := dialCollector(input)
+	if err != nil && input.ReconnectPolicy == nil {
+		return nil, err
 	}
 	expProc := &ExportingProcess{
-		connToCollector: conn,
-		obsDomainID:     input.ObservationDomainID,
-		seqNumber:       0,
-		templateID:      startTemplateID,
-		templatesMap:    make(map[uint16]templateValue),
-		templateRefCh:   make(chan struct{}),
-		sendJSONRecord:  input.SendJSONRecord,
+		connToCollector:          conn,
+		obsDomainID:              input.ObservationDomainID,
+		seqNumber:                0,
+		templateID:               startTemplateID,
+		templatesMap:             make(map[uint16]templateValue),
+		templateRefCh:            make(chan struct{}),
+		sendJSONRecord:           input.SendJSONRecord,
+		compressMessages:         input.CompressMessages,
+		jsonNameCanonicalization: input.JSONNameCanonicalization,
+		samplingConfig:           input.SamplingConfig,
+		collectorProtocol:        input.CollectorProtocol,
+		startTime:                time.Now(),
+		rateLimiter:              newRateLimiter(input.RateLimit),
+	}
+	if input.ReconnectPolicy != nil {
+		expProc.reconnectPolicy = input.ReconnectPolicy.withDefaults()
+		expProc.dial = func() (net.Conn, error) {
+			return dialCollector(input)
+		}
+		if conn == nil {
+			klog.InfoS("Collector is unavailable, reconnecting", "address", input.CollectorAddress)
+			expProc.connMutex.Lock()
+			expProc.startReconnectLocked()
+			expProc.connMutex.Unlock()
+		}
 	}
 
 	// Start a goroutine for checking whether connection to collector is still open
@@ -172,6 +212,11 @@ func InitExportingProcess(input ExporterInput) (*ExportingProcess, error) {
 				select {
 				case <-ticker.C:
 					isConnected := expProc.checkConnToCollector(oneByteForRead)
+					if !isConnected && expProc.reconnectPolicy != nil {
+						klog.Error("Connection to collector is closed, reconnecting.")
+						expProc.handleConnFailure()
+						continue
+					}
 					if !isConnected {
 						expProc.CloseConnToCollector()
 						klog.Error("Error when connecting to collector because connection is closed.")
@@ -194,9 +239,16 @@ func InitExportingProcess(input ExporterInput) (*ExportingProcess, error) {
 			for {
 				select {
 				case <-expProc.templateRefCh:
-					break
+					// exporting process is closed
+					return
 				case <-ticker.C:
 					err := expProc.sendRefreshedTemplates()
+					if err == nil && expProc.samplingConfig != nil {
+						err = expProc.sendSamplingOptions(false)
+					}
+					if err == nil {
+						err = expProc.refreshObservationPoint()
+					}
 					if err != nil {
 						// Other option is sending messages through channel to library consumers
 						klog.Errorf("Error when sending refreshed templates: %v. Closing the connection to IPFIX controller", err)
@@ -213,18 +265,122 @@ func InitExportingProcess(input ExporterInput) (*ExportingProcess, error) {
 			expProc.jsonBufferLen = input.JSONBufferLen
 		}
 	}
+	if expProc.samplingConfig != nil && !expProc.sendJSONRecord {
+		expProc.samplingOptionsTemplateID = expProc.NewTemplateID()
+		if err := expProc.sendSamplingOptions(true); err != nil {
+			expProc.CloseConnToCollector()
+			return nil, fmt.Errorf("error when sending sampling options: %v", err)
+		}
+	}
 	return expProc, nil
 }
 
+// dialCollector creates the connection to the collector.
+func dialCollector(input ExporterInput) (net.Conn, error) {
+	var conn net.Conn
+	var err error
+	if input.TLSClientConfig != nil {
+		tlsConfig := input.TLSClientConfig
+		if input.CollectorProtocol == "tcp" { // use TLS
+			config, configErr := createClientConfig(tlsConfig)
+			if configErr != nil {
+				return nil, configErr
+			}
+			conn, err = tls.Dial(input.CollectorProtocol, input.CollectorAddress, config)
+			if err != nil {
+				klog.Errorf("Cannot the create the tls connection to the Collector %s: %v", input.CollectorAddress, err)
+				return nil, err
+			}
+		} else if input.CollectorProtocol == "udp" { // use DTLS
+			// TODO: support client authentication
+			if len(tlsConfig.CertData) > 0 || len(tlsConfig.KeyData) > 0 {
+				klog.Error("Client-authentication is not supported yet for DTLS, cert and key data will be ignored")
+			}
+			roots := x509.NewCertPool()
+			ok := roots.AppendCertsFromPEM(tlsConfig.CAData)
+			if !ok {
+				return nil, fmt.Errorf("failed to parse root certificate")
+			}
+			config := &dtls.Config{
+				RootCAs:              roots,
+				ExtendedMasterSecret: dtls.RequireExtendedMasterSecret,
+				ServerName:           tlsConfig.ServerName,
+			}
+			udpAddr, err := net.ResolveUDPAddr(input.CollectorProtocol, input.CollectorAddress)
+			if err != nil {
+				return nil, err
+			}
+			conn, err = dtls.Dial(udpAddr.Network(), udpAddr, config)
+			if err != nil {
+				klog.Errorf("Cannot the create the dtls connection to the Collector %s: %v", udpAddr.String(), err)
+				return nil, err
+			}
+		}
+	} else {
+		conn, err = net.Dial(input.CollectorProtocol, input.CollectorAddress)
+		if err != nil {
+			klog.Errorf("Cannot the create the connection to the Collector %s: %v", input.CollectorAddress, err)
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// SendSet sends the set to the collector in its own message. A template or
+// options template set whose records are all identical to active templates is
+// not sent, as the collector already has these templates, and is counted in
+// GetNumRedundantTemplateSets instead; it returns 0 bytes sent in that case.
+// Active templates are still refreshed periodically over UDP, and sent again
+// after reconnecting to the collector.
 func (ep *ExportingProcess) SendSet(set entities.Set) (int, error) {
+	return ep.sendSet(set, true)
+}
+
+// GetNumRedundantTemplateSets returns the number of template sets which were
+// not sent by SendSet because they only contained active templates.
+func (ep *ExportingProcess) GetNumRedundantTemplateSets() uint64 {
+	return ep.numRedundantTemplateSets.Load()
+}
+
+func (ep *ExportingProcess) sendSet(set entities.Set, skipRedundantTemplates bool) (int, error) {
 	// Iterate over all records in the set.
 	setType := set.GetSetType()
 	if setType == entities.Undefined {
 		return 0, fmt.Errorf("set type is not properly defined")
 	}
+	if err := ep.sendPendingObservationPoint(); err != nil {
+		return 0, fmt.Errorf("error when sending observation point options: %v", err)
+	}
+	isTemplateSet := setType == entities.Template || setType == entities.OptionsTemplate
+	if isTemplateSet && skipRedundantTemplates && len(set.GetRecords()) > 0 {
+		isRedundant := true
+		for _, record := range set.GetRecords() {
+			if exist, redefined := ep.compareTemplate(record, setType); !exist || redefined {
+				isRedundant = false
+				break
+			}
+		}
+		if isRedundant {
+			ep.numRedundantTemplateSets.Add(1)
+			return 0, nil
+		}
+	}
 	for _, record := range set.GetRecords() {
+		if isTemplateSet {
+			if _, redefined := ep.compareTemplate(record, setType); redefined {
+				// The template ID is reused for a new definition, which must be
+				// preceded by the withdrawal of the previous one.
+				if err := ep.withdrawTemplate(record.GetTemplateID(), false); err != nil {
+					return 0, err
+				}
+			}
+		}
 		if setType == entities.Template {
 			ep.updateTemplate(record.GetTemplateID(), record.GetOrderedElementList(), record.GetMinDataRecordLen())
+		} else if setType == entities.OptionsTemplate {
+			// The scope field count follows the template ID and field count in the record header.
+			scopeFieldCount := int(binary.BigEndian.Uint16(record.GetBuffer()[4:6]))
+			ep.updateOptionsTemplate(record.GetTemplateID(), record.GetOrderedElementList(), record.GetMinDataRecordLen(), scopeFieldCount)
 		} else if setType == entities.Data {
 			err := ep.dataRecSanityCheck(record)
 			if err != nil {
@@ -250,6 +406,86 @@ func (ep *ExportingProcess) SendSet(set entities.Set) (int, error) {
 	return bytesSent, nil
 }
 
+// AddRecordAndSendMsg sends a single template, options template or data record
+// in its own set, which is a shorthand for SendSet with a set of one record.
+// The record is usually a record received by a collecting process, e.g. when
+// relaying records to another collector.
+func (ep *ExportingProcess) AddRecordAndSendMsg(setType entities.ContentType, record entities.Record) (int, error) {
+	set := entities.NewSet(false)
+	if err := set.PrepareSet(setType, record.GetTemplateID()); err != nil {
+		return 0, err
+	}
+	var err error
+	if setType == entities.OptionsTemplate {
+		// The scope field count follows the template ID and field count in the record header.
+		scopeFieldCount := int(binary.BigEndian.Uint16(record.GetBuffer()[4:6]))
+		err = set.AddOptionsTemplateRecord(record.GetOrderedElementList(), scopeFieldCount, record.GetTemplateID())
+	} else {
+		err = set.AddRecord(record.GetOrderedElementList(), record.GetTemplateID())
+	}
+	if err != nil {
+		return 0, err
+	}
+	return ep.SendSet(set)
+}
+
+// MixedRecord is a data record sent with SendMixedRecords, along with the ID
+// of the template it follows.
+type MixedRecord struct {
+	TemplateID uint16
+	Record     entities.Record
+}
+
+// SendMixedRecords sends data records of different templates in a single IPFIX
+// message, using one data set per template ID. Data sets are ordered by the
+// first occurrence of their template ID in records. When sending JSON records,
+// each record is sent individually as with SendSet.
+func (ep *ExportingProcess) SendMixedRecords(records []MixedRecord) (int, error) {
+	if len(records) == 0 {
+		return 0, fmt.Errorf("no records to send")
+	}
+	if err := ep.sendPendingObservationPoint(); err != nil {
+		return 0, fmt.Errorf("error when sending observation point options: %v", err)
+	}
+	sets := make([]entities.Set, 0)
+	setsByTemplateID := make(map[uint16]entities.Set)
+	for _, mixedRecord := range records {
+		set, exist := setsByTemplateID[mixedRecord.TemplateID]
+		if !exist {
+			set = entities.NewSet(false)
+			if err := set.PrepareSet(entities.Data, mixedRecord.TemplateID); err != nil {
+				return 0, err
+			}
+			setsByTemplateID[mixedRecord.TemplateID] = set
+			sets = append(sets, set)
+		}
+		if err := set.AddRecord(mixedRecord.Record.GetOrderedElementList(), mixedRecord.TemplateID); err != nil {
+			return 0, err
+		}
+	}
+	for _, set := range sets {
+		for _, record := range set.GetRecords() {
+			if err := ep.dataRecSanityCheck(record); err != nil {
+				return 0, fmt.Errorf("error when doing sanity check:%v", err)
+			}
+		}
+		set.UpdateLenInHeader()
+	}
+
+	if ep.sendJSONRecord {
+		var bytesSent int
+		for _, set := range sets {
+			n, err := ep.createAndSendJSONMsg(set)
+			bytesSent += n
+			if err != nil {
+				return bytesSent, err
+			}
+		}
+		return bytesSent, nil
+	}
+	return ep.createAndSendIPFIXMsgWithSets(sets)
+}
+
 func (ep *ExportingProcess) GetMsgSizeLimit() int {
 	return entities.MaxSocketMsgSize
 }
@@ -258,6 +494,11 @@ func (ep *ExportingProcess) CloseConnToCollector() {
 	if !isChanClosed(ep.templateRefCh) {
 		close(ep.templateRefCh) // Close template refresh channel
 	}
+	ep.connMutex.Lock()
+	defer ep.connMutex.Unlock()
+	if ep.connToCollector == nil {
+		return
+	}
 	err := ep.connToCollector.Close()
 	// Just log the error that happened when closing the connection. Not returning error as we do not expect library
 	// consumers to exit their programs with this error.
@@ -269,6 +510,11 @@ func (ep *ExportingProcess) CloseConnToCollector() {
 // checkConnToCollector checks whether the connection from exporter is still open
 // by trying to read from connection. Closed connection will return EOF from read.
 func (ep *ExportingProcess) checkConnToCollector(oneByteForRead []byte) bool {
+	ep.connMutex.Lock()
+	defer ep.connMutex.Unlock()
+	if ep.connToCollector == nil {
+		return true
+	}
 	ep.connToCollector.SetReadDeadline(time.Now().Add(time.Millisecond))
 	if _, err := ep.connToCollector.Read(oneByteForRead); err == io.EOF {
 		return false
@@ -276,29 +522,130 @@ func (ep *ExportingProcess) checkConnToCollector(oneByteForRead []byte) bool {
 	return true
 }
 
-// NewTemplateID is called to get ID when creating new template record.
+// NewTemplateID is called to get ID when creating new template record. The IDs
+// of withdrawn templates are reused first.
 func (ep *ExportingProcess) NewTemplateID() uint16 {
+	ep.templateMutex.Lock()
+	defer ep.templateMutex.Unlock()
+	if len(ep.withdrawnTemplateIDs) > 0 {
+		id := ep.withdrawnTemplateIDs[0]
+		ep.withdrawnTemplateIDs = ep.withdrawnTemplateIDs[1:]
+		return id
+	}
 	ep.templateID++
 	return ep.templateID
 }
 
+// WithdrawTemplate withdraws the template with the given ID, which is then
+// returned again by NewTemplateID to be reused for a new template definition.
+// A template withdrawal message is sent to the collector, except for UDP as
+// per RFC7011: UDP collectors expire the template instead.
+func (ep *ExportingProcess) WithdrawTemplate(id uint16) error {
+	return ep.withdrawTemplate(id, true)
+}
+
+// withdrawTemplate deletes the template and sends the template withdrawal
+// message. If recycle is true, the template ID is reused by NewTemplateID.
+func (ep *ExportingProcess) withdrawTemplate(id uint16, recycle bool) error {
+	if err := ep.deleteTemplate(id); err != nil {
+		return err
+	}
+	if recycle {
+		ep.templateMutex.Lock()
+		ep.withdrawnTemplateIDs = append(ep.withdrawnTemplateIDs, id)
+		ep.templateMutex.Unlock()
+	}
+	if ep.sendJSONRecord || ep.collectorProtocol == "udp" {
+		return nil
+	}
+	// A template withdrawal is a template record with a field count of 0.
+	withdrawalSet := entities.NewSet(false)
+	if err := withdrawalSet.PrepareSet(entities.Template, id); err != nil {
+		return err
+	}
+	if err := withdrawalSet.AddRecord(nil, id); err != nil {
+		return err
+	}
+	withdrawalSet.UpdateLenInHeader()
+	if _, err := ep.createAndSendIPFIXMsg(withdrawalSet); err != nil {
+		return fmt.Errorf("error when sending template withdrawal: %v", err)
+	}
+	return nil
+}
+
+// compareTemplate returns whether a template with the ID of the template
+// record exists, and if so whether the record redefines it with a different
+// definition.
+func (ep *ExportingProcess) compareTemplate(record entities.Record, setType entities.ContentType) (exist bool, redefined bool) {
+	ep.templateMutex.Lock()
+	defer ep.templateMutex.Unlock()
+	existing, exist := ep.templatesMap[record.GetTemplateID()]
+	if !exist {
+		return false, false
+	}
+	elements := record.GetOrderedElementList()
+	if len(elements) != len(existing.elements) {
+		return true, true
+	}
+	for i, element := range elements {
+		ie := element.GetInfoElement()
+		if ie.ElementId != existing.elements[i].ElementId || ie.EnterpriseId != existing.elements[i].EnterpriseId || ie.Len != existing.elements[i].Len {
+			return true, true
+		}
+	}
+	scopeFieldCount := 0
+	if setType == entities.OptionsTemplate {
+		scopeFieldCount = int(binary.BigEndian.Uint16(record.GetBuffer()[4:6]))
+	}
+	return true, scopeFieldCount != existing.scopeFieldCount
+}
+
 // createAndSendIPFIXMsg takes in a set as input, creates the IPFIX message, and sends it out.
-// TODO: This method will change when we support sending multiple sets.
 func (ep *ExportingProcess) createAndSendIPFIXMsg(set entities.Set) (int, error) {
-	if set.GetSetType() == entities.Data {
-		ep.seqNumber = ep.seqNumber + set.GetNumberOfRecords()
+	return ep.createAndSendIPFIXMsgWithSets([]entities.Set{set})
+}
+
+// exportTime returns the current time for the export time of a message. It is
+// computed with the monotonic clock from the start time of the exporting
+// process, so that the export time of successive messages never goes backwards
+// when the wall clock is adjusted.
+func (ep *ExportingProcess) exportTime() time.Time {
+	if ep.startTime.IsZero() {
+		return time.Now()
 	}
-	bytesSlice, err := CreateIPFIXMsg(set, ep.obsDomainID, ep.seqNumber, time.Now())
+	return ep.startTime.Add(time.Since(ep.startTime))
+}
+
+// createAndSendIPFIXMsgWithSets creates a single IPFIX message containing all
+// the given sets, and sends it out.
+func (ep *ExportingProcess) createAndSendIPFIXMsgWithSets(sets []entities.Set) (int, error) {
+	// The sequence number of the message is the number of data records sent
+	// before it, modulo 2^32: it wraps around to 0 after 2^32-1 (RFC 7011
+	// section 3.1).
+	seqNumber := ep.seqNumber
+	for _, set := range sets {
+		if set.GetSetType() == entities.Data {
+			ep.seqNumber = ep.seqNumber + set.GetNumberOfRecords()
+		}
+	}
+	bytesSlice, err := createIPFIXMsgWithSets(sets, ep.obsDomainID, seqNumber, ep.exportTime())
 	if err != nil {
 		return 0, err
 	}
+	if ep.compressMessages {
+		bytesSlice, err = CreateCompressedIPFIXMsg(bytesSlice)
+		if err != nil {
+			return 0, err
+		}
+	}
 
 	// Send the message on the exporter connection.
-	bytesSent, err := ep.connToCollector.Write(bytesSlice)
+	bytesSent, err := ep.writeToCollector(bytesSlice)
 
 	if err != nil {
 		return bytesSent, fmt.Errorf("error when sending message on the connection: %v", err)
-	} else if bytesSent != len(bytesSlice) {
+	} else if bytesSent != len(bytesSlice) && ep.reconnectPolicy == nil {
+		// With a reconnect policy, 0 bytes are sent when the message is buffered.
 		return bytesSent, fmt.Errorf("could not send the complete message on the connection")
 	}
 
@@ -311,42 +658,56 @@ func (ep *ExportingProcess) createAndSendJSONMsg(set entities.Set) (int, error)
 	for _, record := range set.GetRecords() {
 		elements := make(map[string]interface{})
 		orderedElements := record.GetOrderedElementList()
-		for _, element := range orderedElements {
+		keys := make([]string, len(orderedElements))
+		if ep.jsonNameCanonicalization {
+			keys = entities.ElementMapKeys(orderedElements, entities.ElementMapOptions{NameCanonicalization: true})
+		} else {
+			for i, element := range orderedElements {
+				keys[i] = element.GetName()
+			}
+		}
+		for i, element := range orderedElements {
 			switch element.GetDataType() {
 			case entities.Unsigned8:
-				elements[element.GetName()] = element.GetUnsigned8Value()
+				elements[keys[i]] = element.GetUnsigned8Value()
 			case entities.Unsigned16:
-				elements[element.GetName()] = element.GetUnsigned16Value()
+				if entities.IsICMPTypeCodeElement(element.GetInfoElement()) {
+					// Render the ICMP type and code packed in the value separately.
+					elements[keys[i]] = entities.NewICMPTypeCode(element.GetUnsigned16Value())
+				} else {
+					elements[keys[i]] = element.GetUnsigned16Value()
+				}
 			case entities.Unsigned32:
-				elements[element.GetName()] = element.GetUnsigned32Value()
+				elements[keys[i]] = element.GetUnsigned32Value()
 			case entities.Unsigned64:
-				elements[element.GetName()] = element.GetUnsigned64Value()
+				elements[keys[i]] = element.GetUnsigned64Value()
 			case entities.Signed8:
-				elements[element.GetName()] = element.GetSigned8Value()
+				elements[keys[i]] = element.GetSigned8Value()
 			case entities.Signed16:
-				elements[element.GetName()] = element.GetSigned16Value()
+				elements[keys[i]] = element.GetSigned16Value()
 			case entities.Signed32:
-				elements[element.GetName()] = element.GetSigned32Value()
+				elements[keys[i]] = element.GetSigned32Value()
 			case entities.Signed64:
-				elements[element.GetName()] = element.GetSigned64Value()
+				elements[keys[i]] = element.GetSigned64Value()
 			case entities.Float32:
-				elements[element.GetName()] = element.GetFloat32Value()
+				elements[keys[i]] = element.GetFloat32Value()
 			case entities.Float64:
-				elements[element.GetName()] = element.GetFloat64Value()
+				elements[keys[i]] = element.GetFloat64Value()
 			case entities.Boolean:
-				elements[element.GetName()] = element.GetBooleanValue()
+				elements[keys[i]] = element.GetBooleanValue()
 			case entities.DateTimeSeconds:
-				elements[element.GetName()] = element.GetUnsigned32Value()
-			case entities.DateTimeMilliseconds:
-				elements[element.GetName()] = element.GetUnsigned64Value()
-			case entities.DateTimeMicroseconds, entities.DateTimeNanoseconds:
-				return bytesSent, fmt.Errorf("API does not support micro and nano seconds types yet")
+				elements[keys[i]] = element.GetUnsigned32Value()
+			case entities.DateTimeMilliseconds, entities.DateTimeMicroseconds, entities.DateTimeNanoseconds:
+				elements[keys[i]] = element.GetUnsigned64Value()
 			case entities.MacAddress:
-				elements[element.GetName()] = element.GetMacAddressValue()
+				elements[keys[i]] = element.GetMacAddressValue()
 			case entities.Ipv4Address, entities.Ipv6Address:
-				elements[element.GetName()] = element.GetIPAddressValue()
+				elements[keys[i]] = element.GetIPAddressValue()
 			case entities.String:
-				elements[element.GetName()] = element.GetStringValue()
+				elements[keys[i]] = element.GetStringValue()
+			case entities.OctetArray:
+				// Render octet arrays, e.g. certificate fingerprints, as hex strings.
+				elements[keys[i]] = hex.EncodeToString(element.GetOctetArrayValue())
 			default:
 				return bytesSent, fmt.Errorf("API supports only valid information elements with datatypes given in RFC7011")
 			}
@@ -361,7 +722,7 @@ func (ep *ExportingProcess) createAndSendJSONMsg(set entities.Set) (int, error)
 			return bytesSent, fmt.Errorf("error when encoding message to JSON: %v", err)
 		}
 		// Send the message on the exporter connection.
-		bytes, err := ep.connToCollector.Write(writer.Bytes())
+		bytes, err := ep.writeToCollector(writer.Bytes())
 		if err != nil {
 			return bytes, fmt.Errorf("error when sending message on the connection: %v", err)
 		}
@@ -371,6 +732,12 @@ func (ep *ExportingProcess) createAndSendJSONMsg(set entities.Set) (int, error)
 }
 
 func (ep *ExportingProcess) updateTemplate(id uint16, elements []entities.InfoElementWithValue, minDataRecLen uint16) {
+	ep.updateOptionsTemplate(id, elements, minDataRecLen, 0)
+}
+
+// updateOptionsTemplate stores an options template, or a template if
+// scopeFieldCount is 0.
+func (ep *ExportingProcess) updateOptionsTemplate(id uint16, elements []entities.InfoElementWithValue, minDataRecLen uint16, scopeFieldCount int) {
 	ep.templateMutex.Lock()
 	defer ep.templateMutex.Unlock()
 
@@ -380,6 +747,7 @@ func (ep *ExportingProcess) updateTemplate(id uint16, elements []entities.InfoEl
 	ep.templatesMap[id] = templateValue{
 		make([]*entities.InfoElement, len(elements)),
 		minDataRecLen,
+		scopeFieldCount,
 	}
 	for i, elem := range elements {
 		ep.templatesMap[id].elements[i] = elem.GetInfoElement()
@@ -387,7 +755,6 @@ func (ep *ExportingProcess) updateTemplate(id uint16, elements []entities.InfoEl
 	return
 }
 
-//nolint:unused // Keeping this function for reference.
 func (ep *ExportingProcess) deleteTemplate(id uint16) error {
 	ep.templateMutex.Lock()
 	defer ep.templateMutex.Unlock()
@@ -401,35 +768,52 @@ func (ep *ExportingProcess) deleteTemplate(id uint16) error {
 
 func (ep *ExportingProcess) sendRefreshedTemplates() error {
 	// Send refreshed template for every template in template map
+	templateSets, err := ep.createTemplateSets()
+	if err != nil {
+		return err
+	}
+	for _, templateSet := range templateSets {
+		if _, err := ep.sendSet(templateSet, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createTemplateSets creates a template set, or an options template set, for
+// every template in the template map.
+func (ep *ExportingProcess) createTemplateSets() ([]entities.Set, error) {
 	templateSets := make([]entities.Set, 0)
 
 	ep.templateMutex.Lock()
+	defer ep.templateMutex.Unlock()
 	for templateID, tempValue := range ep.templatesMap {
 		tempSet := entities.NewSet(false)
-		if err := tempSet.PrepareSet(entities.Template, templateID); err != nil {
-			return err
+		setType := entities.Template
+		if tempValue.scopeFieldCount > 0 {
+			setType = entities.OptionsTemplate
+		}
+		if err := tempSet.PrepareSet(setType, templateID); err != nil {
+			return nil, err
 		}
 		elements := make([]entities.InfoElementWithValue, len(tempValue.elements))
 		var err error
 		for i, element := range tempValue.elements {
 			if elements[i], err = entities.DecodeAndCreateInfoElementWithValue(element, nil); err != nil {
-				return err
+				return nil, err
 			}
 		}
-		err = tempSet.AddRecord(elements, templateID)
+		if tempValue.scopeFieldCount > 0 {
+			err = tempSet.AddOptionsTemplateRecord(elements, tempValue.scopeFieldCount, templateID)
+		} else {
+			err = tempSet.AddRecord(elements, templateID)
+		}
 		if err != nil {
-			return err
+			return nil, err
 		}
 		templateSets = append(templateSets, tempSet)
 	}
-	ep.templateMutex.Unlock()
-
-	for _, templateSet := range templateSets {
-		if _, err := ep.SendSet(templateSet); err != nil {
-			return err
-		}
-	}
-	return nil
+	return templateSets, nil
 }
 
 func (ep *ExportingProcess) dataRecSanityCheck(rec entities.Record) error {
@@ -447,6 +831,23 @@ func (ep *ExportingProcess) dataRecSanityCheck(rec entities.Record) error {
 	if len(rec.GetBuffer()) < int(ep.templatesMap[templateID].minDataRecLen) {
 		return fmt.Errorf("process: Data Record does not pass the min required length (%d) check for template ID %d", ep.templatesMap[templateID].minDataRecLen, templateID)
 	}
+	// The templates referenced by subTemplateList elements must be sent before
+	// the data record.
+	for _, element := range rec.GetOrderedElementList() {
+		if element.GetDataType() != entities.SubTemplateList || element.GetSubTemplateListValue() == nil {
+			continue
+		}
+		subTemplateList := element.GetSubTemplateListValue()
+		subTemplate, exist := ep.templatesMap[subTemplateList.TemplateID]
+		if !exist {
+			return fmt.Errorf("process: templateID %d referenced by element %s does not exist in exporting process", subTemplateList.TemplateID, element.GetName())
+		}
+		for _, record := range subTemplateList.Records {
+			if len(record) != len(subTemplate.elements) {
+				return fmt.Errorf("process: field count of record in element %s does not match templateID %d", element.GetName(), subTemplateList.TemplateID)
+			}
+		}
+	}
 	return nil
 }
 
@@ -17,7 +17,10 @@ package exporter
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
 	"io"
+	"math"
 	"net"
 	"testing"
 	"time"
@@ -194,6 +197,125 @@ func TestExportingProcess_SendingTemplateRecordToLocalUDPServer(t *testing.T) {
 
 }
 
+func TestExportingProcess_SequenceNumberWraparound(t *testing.T) {
+	udpAddr, err := net.ResolveUDPAddr("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	conn, err := net.ListenUDP("udp", udpAddr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	exporter, err := InitExportingProcess(ExporterInput{
+		CollectorAddress:    conn.LocalAddr().String(),
+		CollectorProtocol:   conn.LocalAddr().Network(),
+		ObservationDomainID: 1,
+	})
+	require.NoError(t, err)
+	defer exporter.CloseConnToCollector()
+	element, err := registry.GetInfoElement("sourceIPv4Address", registry.IANAEnterpriseID)
+	require.NoError(t, err)
+	// Start right before the wraparound of the sequence number.
+	exporter.seqNumber = math.MaxUint32 - 1
+
+	templateID := exporter.NewTemplateID()
+	templateSet := entities.NewSet(false)
+	require.NoError(t, templateSet.PrepareSet(entities.Template, templateID))
+	require.NoError(t, templateSet.AddRecord([]entities.InfoElementWithValue{entities.NewIPAddressInfoElement(element, nil)}, templateID))
+	_, err = exporter.SendSet(templateSet)
+	require.NoError(t, err)
+	for _, numRecords := range []int{3, 1} {
+		dataSet := entities.NewSet(false)
+		require.NoError(t, dataSet.PrepareSet(entities.Data, templateID))
+		for i := 0; i < numRecords; i++ {
+			require.NoError(t, dataSet.AddRecord([]entities.InfoElementWithValue{entities.NewIPAddressInfoElement(element, net.IP{1, 2, 3, byte(i)})}, templateID))
+		}
+		_, err = exporter.SendSet(dataSet)
+		require.NoError(t, err)
+	}
+
+	// The sequence number of every message is the number of data records sent
+	// before it, modulo 2^32.
+	var sequenceNums []uint32
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	buffer := make([]byte, 512)
+	for i := 0; i < 3; i++ {
+		n, err := conn.Read(buffer)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, n, entities.MsgHeaderLength)
+		sequenceNums = append(sequenceNums, binary.BigEndian.Uint32(buffer[8:12]))
+	}
+	assert.Equal(t, []uint32{math.MaxUint32 - 1, math.MaxUint32 - 1, 1}, sequenceNums)
+	assert.Equal(t, uint32(2), exporter.seqNumber)
+}
+
+func TestExportingProcess_TemplateRefreshUDP(t *testing.T) {
+	udpAddr, err := net.ResolveUDPAddr("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	conn, err := net.ListenUDP("udp", udpAddr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	exporter, err := InitExportingProcess(ExporterInput{
+		CollectorAddress:    conn.LocalAddr().String(),
+		CollectorProtocol:   conn.LocalAddr().Network(),
+		ObservationDomainID: 1,
+		TempRefTimeout:      1,
+	})
+	require.NoError(t, err)
+	defer exporter.CloseConnToCollector()
+	element, err := registry.GetInfoElement("sourceIPv4Address", registry.IANAEnterpriseID)
+	require.NoError(t, err)
+
+	// Count the template sets received for every template ID.
+	start := time.Now()
+	templatesCh := make(chan map[uint16]int)
+	go func() {
+		templates := make(map[uint16]int)
+		conn.SetReadDeadline(start.Add(1500 * time.Millisecond))
+		buffer := make([]byte, 512)
+		for {
+			n, err := conn.Read(buffer)
+			if err != nil {
+				break
+			}
+			if n >= 22 && binary.BigEndian.Uint16(buffer[16:18]) == entities.TemplateSetID {
+				templates[binary.BigEndian.Uint16(buffer[20:22])]++
+			}
+		}
+		templatesCh <- templates
+	}()
+
+	sendTemplate := func() uint16 {
+		templateID := exporter.NewTemplateID()
+		templateSet := entities.NewSet(false)
+		require.NoError(t, templateSet.PrepareSet(entities.Template, templateID))
+		require.NoError(t, templateSet.AddRecord([]entities.InfoElementWithValue{entities.NewIPAddressInfoElement(element, nil)}, templateID))
+		_, err := exporter.SendSet(templateSet)
+		require.NoError(t, err)
+		return templateID
+	}
+	sendData := func(templateID uint16) {
+		dataSet := entities.NewSet(false)
+		require.NoError(t, dataSet.PrepareSet(entities.Data, templateID))
+		require.NoError(t, dataSet.AddRecord([]entities.InfoElementWithValue{entities.NewIPAddressInfoElement(element, net.IP{1, 2, 3, 4})}, templateID))
+		_, err := exporter.SendSet(dataSet)
+		require.NoError(t, err)
+	}
+	// Data records are sent continuously, which must not delay the refresh, and
+	// the second template is defined in between two refreshes.
+	templateID1 := sendTemplate()
+	var templateID2 uint16
+	for time.Since(start) < 1300*time.Millisecond {
+		if templateID2 == 0 && time.Since(start) > 500*time.Millisecond {
+			templateID2 = sendTemplate()
+		}
+		sendData(templateID1)
+		time.Sleep(100 * time.Millisecond)
+	}
+	templates := <-templatesCh
+	assert.Equal(t, 2, templates[templateID1], "template should be sent once and refreshed once")
+	assert.Equal(t, 2, templates[templateID2], "new template should be included in the next refresh")
+}
+
 func TestExportingProcess_SendingDataRecordToLocalTCPServer(t *testing.T) {
 	// Create local server for testing
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
@@ -395,6 +517,279 @@ func TestExportingProcess_SendingDataRecordToLocalUDPServer(t *testing.T) {
 	exporter.CloseConnToCollector()
 }
 
+func TestExportingProcess_SendMixedRecords(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	buffCh := make(chan []byte)
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buff := make([]byte, 34)
+		_, err = io.ReadFull(conn, buff)
+		assert.NoError(t, err)
+		buffCh <- buff
+	}()
+
+	input := ExporterInput{
+		CollectorAddress:    listener.Addr().String(),
+		CollectorProtocol:   listener.Addr().Network(),
+		ObservationDomainID: 1,
+	}
+	exporter, err := InitExportingProcess(input)
+	require.NoError(t, err)
+	defer exporter.CloseConnToCollector()
+
+	ipElement, err := registry.GetInfoElement("sourceIPv4Address", registry.IANAEnterpriseID)
+	require.NoError(t, err)
+	portElement, err := registry.GetInfoElement("sourceTransportPort", registry.IANAEnterpriseID)
+	require.NoError(t, err)
+	// [Only for testing] Ensure corresponding templates exist in the exporting process before sending data
+	ipTemplateID := exporter.NewTemplateID()
+	exporter.updateTemplate(ipTemplateID, []entities.InfoElementWithValue{entities.NewIPAddressInfoElement(ipElement, nil)}, 4)
+	portTemplateID := exporter.NewTemplateID()
+	exporter.updateTemplate(portTemplateID, []entities.InfoElementWithValue{entities.NewUnsigned16InfoElement(portElement, 0)}, 2)
+
+	createRecord := func(templateID uint16, ie entities.InfoElementWithValue) entities.Record {
+		record := entities.NewDataRecord(templateID, 1, 0, false)
+		require.NoError(t, record.AddInfoElement(ie))
+		return record
+	}
+	records := []MixedRecord{
+		{ipTemplateID, createRecord(ipTemplateID, entities.NewIPAddressInfoElement(ipElement, net.IP{1, 2, 3, 4}))},
+		{portTemplateID, createRecord(portTemplateID, entities.NewUnsigned16InfoElement(portElement, 80))},
+		{ipTemplateID, createRecord(ipTemplateID, entities.NewIPAddressInfoElement(ipElement, net.IP{5, 6, 7, 8}))},
+	}
+	bytesSent, err := exporter.SendMixedRecords(records)
+	require.NoError(t, err)
+	// Message header (16 bytes), data set of template 256 with 2 records (12 bytes)
+	// and data set of template 257 with 1 record (6 bytes).
+	assert.Equal(t, 34, bytesSent)
+	msg := <-buffCh
+	assert.Equal(t, []byte{0, 10, 0, 34}, msg[0:4])
+	assert.Equal(t, []byte{1, 0, 0, 12, 1, 2, 3, 4, 5, 6, 7, 8}, msg[16:28])
+	assert.Equal(t, []byte{1, 1, 0, 6, 0, 80}, msg[28:34])
+
+	_, err = exporter.SendMixedRecords([]MixedRecord{{300, createRecord(300, entities.NewUnsigned16InfoElement(portElement, 80))}})
+	assert.Error(t, err, "Sending records of an unknown template should fail")
+}
+
+func TestExportingProcess_AddRecordAndSendMsg(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	buffCh := make(chan []byte)
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// Template message (28 bytes) followed by data message (24 bytes).
+		buff := make([]byte, 52)
+		_, err = io.ReadFull(conn, buff)
+		assert.NoError(t, err)
+		buffCh <- buff
+	}()
+
+	exporter, err := InitExportingProcess(ExporterInput{
+		CollectorAddress:    listener.Addr().String(),
+		CollectorProtocol:   listener.Addr().Network(),
+		ObservationDomainID: 1,
+	})
+	require.NoError(t, err)
+	defer exporter.CloseConnToCollector()
+
+	element, err := registry.GetInfoElement("sourceIPv4Address", registry.IANAEnterpriseID)
+	require.NoError(t, err)
+	templateID := exporter.NewTemplateID()
+	templateRecord := entities.NewTemplateRecord(templateID, 1, false)
+	require.NoError(t, templateRecord.PrepareRecord())
+	require.NoError(t, templateRecord.AddInfoElement(entities.NewIPAddressInfoElement(element, nil)))
+	bytesSent, err := exporter.AddRecordAndSendMsg(entities.Template, templateRecord)
+	require.NoError(t, err)
+	assert.Equal(t, 28, bytesSent)
+
+	dataRecord := entities.NewDataRecord(templateID, 1, 0, false)
+	require.NoError(t, dataRecord.AddInfoElement(entities.NewIPAddressInfoElement(element, net.IP{1, 2, 3, 4})))
+	bytesSent, err = exporter.AddRecordAndSendMsg(entities.Data, dataRecord)
+	require.NoError(t, err)
+	assert.Equal(t, 24, bytesSent)
+
+	msg := <-buffCh
+	assert.Equal(t, []byte{0, 2, 0, 12, 1, 0, 0, 1, 0, 8, 0, 4}, msg[16:28])
+	assert.Equal(t, []byte{1, 0, 0, 8, 1, 2, 3, 4}, msg[44:52])
+
+	dataRecord = entities.NewDataRecord(300, 1, 0, false)
+	require.NoError(t, dataRecord.AddInfoElement(entities.NewIPAddressInfoElement(element, net.IP{1, 2, 3, 4})))
+	_, err = exporter.AddRecordAndSendMsg(entities.Data, dataRecord)
+	assert.Error(t, err, "Sending a record of an unknown template should fail")
+}
+
+func TestExportingProcess_ReconnectPolicy(t *testing.T) {
+	// Reserve an address on which the collector is not listening yet.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	input := ExporterInput{
+		CollectorAddress:    address,
+		CollectorProtocol:   "tcp",
+		ObservationDomainID: 1,
+		ReconnectPolicy: &ReconnectPolicy{
+			InitialBackoff: 50 * time.Millisecond,
+			MaxBackoff:     100 * time.Millisecond,
+			MaxRetries:     50,
+		},
+	}
+	exporter, err := InitExportingProcess(input)
+	require.NoError(t, err, "Exporting process should start while the collector is unavailable")
+	defer exporter.CloseConnToCollector()
+
+	element, err := registry.GetInfoElement("sourceTransportPort", registry.IANAEnterpriseID)
+	require.NoError(t, err)
+	templateID := exporter.NewTemplateID()
+	templateSet := entities.NewSet(false)
+	require.NoError(t, templateSet.PrepareSet(entities.Template, templateID))
+	require.NoError(t, templateSet.AddRecord([]entities.InfoElementWithValue{entities.NewUnsigned16InfoElement(element, 0)}, templateID))
+	bytesSent, err := exporter.SendSet(templateSet)
+	require.NoError(t, err)
+	assert.Equal(t, 0, bytesSent, "Template should be buffered")
+	dataSet := entities.NewSet(false)
+	require.NoError(t, dataSet.PrepareSet(entities.Data, templateID))
+	require.NoError(t, dataSet.AddRecord([]entities.InfoElementWithValue{entities.NewUnsigned16InfoElement(element, 80)}, templateID))
+	bytesSent, err = exporter.SendSet(dataSet)
+	require.NoError(t, err)
+	assert.Equal(t, 0, bytesSent, "Data should be buffered")
+
+	// The collector comes up after the first connection failure.
+	listener, err = net.Listen("tcp", address)
+	require.NoError(t, err)
+	defer listener.Close()
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	setIDs := make([]uint16, 0)
+	var msg []byte
+	for len(setIDs) < 3 {
+		header := make([]byte, entities.MsgHeaderLength)
+		_, err = io.ReadFull(conn, header)
+		require.NoError(t, err)
+		msg = make([]byte, binary.BigEndian.Uint16(header[2:4]))
+		copy(msg, header)
+		_, err = io.ReadFull(conn, msg[entities.MsgHeaderLength:])
+		require.NoError(t, err)
+		setIDs = append(setIDs, binary.BigEndian.Uint16(msg[16:18]))
+	}
+	// The known template is sent first, followed by the buffered messages.
+	assert.Equal(t, []uint16{entities.TemplateSetID, entities.TemplateSetID, templateID}, setIDs)
+	assert.Equal(t, []byte{0, 80}, msg[20:22])
+}
+
+func TestExportingProcess_SendJSONOctetArray(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	exporter := &ExportingProcess{
+		connToCollector: clientConn,
+		templatesMap:    make(map[uint16]templateValue),
+		sendJSONRecord:  true,
+		jsonBufferLen:   defaultJSONBufferLen,
+	}
+	fingerprint := make([]byte, 32)
+	for i := range fingerprint {
+		fingerprint[i] = byte(i)
+	}
+	element := entities.NewInfoElement("exporterCertificateFingerprint", 1, entities.OctetArray, 9999, 32)
+	ie, err := entities.DecodeAndCreateInfoElementWithValue(element, fingerprint)
+	require.NoError(t, err)
+	dataSet := entities.NewSet(false)
+	require.NoError(t, dataSet.PrepareSet(entities.Data, 256))
+	require.NoError(t, dataSet.AddRecord([]entities.InfoElementWithValue{ie}, 256))
+
+	jsonCh := make(chan map[string]interface{})
+	go func() {
+		var message map[string]interface{}
+		if err := json.NewDecoder(serverConn).Decode(&message); err != nil {
+			t.Error(err)
+		}
+		jsonCh <- message
+	}()
+	_, err = exporter.createAndSendJSONMsg(dataSet)
+	require.NoError(t, err)
+	message := <-jsonCh
+	assert.Equal(t, map[string]interface{}{
+		"exporterCertificateFingerprint": "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+	}, message["ipfix"])
+}
+
+func TestExportingProcess_SendJSONICMPTypeCode(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	exporter := &ExportingProcess{
+		connToCollector: clientConn,
+		templatesMap:    make(map[uint16]templateValue),
+		sendJSONRecord:  true,
+		jsonBufferLen:   defaultJSONBufferLen,
+	}
+	element, err := registry.GetInfoElement("icmpTypeCodeIPv4", registry.IANAEnterpriseID)
+	require.NoError(t, err)
+	dataSet := entities.NewSet(false)
+	require.NoError(t, dataSet.PrepareSet(entities.Data, 256))
+	require.NoError(t, dataSet.AddRecord([]entities.InfoElementWithValue{entities.NewUnsigned16InfoElement(element, 0x0800)}, 256))
+
+	jsonCh := make(chan map[string]interface{})
+	go func() {
+		var message map[string]interface{}
+		if err := json.NewDecoder(serverConn).Decode(&message); err != nil {
+			t.Error(err)
+		}
+		jsonCh <- message
+	}()
+	_, err = exporter.createAndSendJSONMsg(dataSet)
+	require.NoError(t, err)
+	message := <-jsonCh
+	assert.Equal(t, map[string]interface{}{
+		"icmpTypeCodeIPv4": map[string]interface{}{"type": float64(8), "code": float64(0)},
+	}, message["ipfix"])
+}
+
+func TestExportingProcess_RateLimit(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	go io.Copy(io.Discard, serverConn)
+	exporter := &ExportingProcess{
+		connToCollector: clientConn,
+		templatesMap:    make(map[uint16]templateValue),
+		rateLimiter:     newRateLimiter(&RateLimit{MessagesPerSecond: 50}),
+	}
+	msg := make([]byte, 100)
+	interval := 500 * time.Millisecond
+	numSent := 0
+	start := time.Now()
+	for time.Since(start) < interval {
+		_, err := exporter.writeToCollector(msg)
+		require.NoError(t, err)
+		numSent++
+	}
+	elapsed := time.Since(start)
+	// The burst of one second worth of messages, followed by the refill rate.
+	assert.GreaterOrEqual(t, numSent, 50)
+	assert.LessOrEqual(t, float64(numSent), 50+50*elapsed.Seconds()+1)
+
+	exporter.rateLimiter = newRateLimiter(&RateLimit{BytesPerSecond: 250, DropWhenExceeded: true})
+	for i := 0; i < 2; i++ {
+		_, err := exporter.writeToCollector(msg)
+		require.NoError(t, err)
+	}
+	_, err := exporter.writeToCollector(msg)
+	assert.Error(t, err)
+}
+
 func TestInitExportingProcessWithTLS(t *testing.T) {
 	caCert, caKey, caData, err := testcerts.GenerateCACert()
 	require.NoError(t, err, "Error when generating CA cert")
@@ -768,3 +1163,74 @@ func TestExportingProcess_CheckConnToCollector(t *testing.T) {
 	isOpen = exporter.checkConnToCollector(oneByte)
 	assert.False(t, isOpen)
 }
+
+func TestNewOriginalExporterElements(t *testing.T) {
+	elements, err := NewOriginalExporterElements(net.ParseIP("10.0.0.1"), 5)
+	require.NoError(t, err)
+	require.Len(t, elements, 2)
+	assert.Equal(t, "originalExporterIPv4Address", elements[0].GetName())
+	assert.Equal(t, net.IP{10, 0, 0, 1}, elements[0].GetIPAddressValue())
+	assert.Equal(t, "originalObservationDomainId", elements[1].GetName())
+	assert.Equal(t, uint32(5), elements[1].GetUnsigned32Value())
+
+	elements, err = NewOriginalExporterElements(net.ParseIP("2001:db8::1"), 5)
+	require.NoError(t, err)
+	assert.Equal(t, "originalExporterIPv6Address", elements[0].GetName())
+	assert.Equal(t, net.ParseIP("2001:db8::1"), elements[0].GetIPAddressValue())
+
+	_, err = NewOriginalExporterElements(nil, 5)
+	assert.Error(t, err)
+}
+
+func TestExportingProcess_SkipRedundantTemplate(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer conn.Close()
+	exporter, err := InitExportingProcess(ExporterInput{
+		CollectorAddress:    conn.LocalAddr().String(),
+		CollectorProtocol:   conn.LocalAddr().Network(),
+		ObservationDomainID: 1,
+	})
+	require.NoError(t, err)
+	defer exporter.CloseConnToCollector()
+	element, err := registry.GetInfoElement("sourceIPv4Address", registry.IANAEnterpriseID)
+	require.NoError(t, err)
+
+	templateID := exporter.NewTemplateID()
+	sendTemplate := func() int {
+		templateSet := entities.NewSet(false)
+		require.NoError(t, templateSet.PrepareSet(entities.Template, templateID))
+		require.NoError(t, templateSet.AddRecord([]entities.InfoElementWithValue{entities.NewIPAddressInfoElement(element, nil)}, templateID))
+		bytesSent, err := exporter.SendSet(templateSet)
+		require.NoError(t, err)
+		return bytesSent
+	}
+	assert.Equal(t, 28, sendTemplate())
+	assert.Equal(t, 0, sendTemplate(), "identical template should not be sent again")
+	assert.Equal(t, uint64(1), exporter.GetNumRedundantTemplateSets())
+	dataSet := entities.NewSet(false)
+	require.NoError(t, dataSet.PrepareSet(entities.Data, templateID))
+	require.NoError(t, dataSet.AddRecord([]entities.InfoElementWithValue{entities.NewIPAddressInfoElement(element, net.IP{1, 2, 3, 4})}, templateID))
+	_, err = exporter.SendSet(dataSet)
+	require.NoError(t, err)
+
+	// Only the first template set is received before the data set.
+	buffer := make([]byte, 512)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, err := conn.Read(buffer)
+	require.NoError(t, err)
+	assert.Equal(t, 28, n)
+	assert.Equal(t, entities.TemplateSetID, binary.BigEndian.Uint16(buffer[16:18]))
+	n, err = conn.Read(buffer)
+	require.NoError(t, err)
+	assert.Equal(t, 24, n)
+	assert.Equal(t, templateID, binary.BigEndian.Uint16(buffer[16:18]))
+
+	// Templates are still sent on refresh, and after withdrawal.
+	require.NoError(t, exporter.sendRefreshedTemplates())
+	n, err = conn.Read(buffer)
+	require.NoError(t, err)
+	assert.Equal(t, 28, n)
+	require.NoError(t, exporter.WithdrawTemplate(templateID))
+	assert.Equal(t, 28, sendTemplate())
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimit configures the maximum rate at which the exporting process sends
+// messages to the collector. The limits are enforced with token buckets which
+// allow bursts of up to one second worth of messages or bytes.
+type RateLimit struct {
+	// MessagesPerSecond is the maximum number of messages sent per second.
+	// Default is 0 (no limit).
+	MessagesPerSecond float64
+	// BytesPerSecond is the maximum number of bytes sent per second. A message
+	// larger than the limit is sent once the bucket is full. Default is 0 (no
+	// limit).
+	BytesPerSecond float64
+	// DropWhenExceeded is set to drop the messages exceeding the limit, and
+	// return an error to the caller. By default, sending blocks until the
+	// message can be sent within the limit.
+	DropWhenExceeded bool
+}
+
+// tokenBucket refills at rate tokens per second, up to its capacity. The
+// number of tokens may go negative when tokens are reserved in advance.
+type tokenBucket struct {
+	rate     float64
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(rate float64, now time.Time) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	capacity := rate
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &tokenBucket{
+		rate:     rate,
+		capacity: capacity,
+		tokens:   capacity,
+		last:     now,
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+}
+
+// available returns whether n tokens can be taken. More tokens than the
+// capacity can be taken from a full bucket.
+func (b *tokenBucket) available(n float64, now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	b.refill(now)
+	return b.tokens >= n || b.tokens >= b.capacity
+}
+
+func (b *tokenBucket) take(n float64) {
+	if b != nil {
+		b.tokens -= n
+	}
+}
+
+// reserve takes n tokens and returns the delay after which they are actually
+// available.
+func (b *tokenBucket) reserve(n float64, now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.refill(now)
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	// A message larger than the capacity only has to wait for a full bucket.
+	deficit := -b.tokens
+	if n > b.capacity {
+		deficit -= n - b.capacity
+	}
+	return time.Duration(deficit / b.rate * float64(time.Second))
+}
+
+type rateLimiter struct {
+	mutex    sync.Mutex
+	messages *tokenBucket
+	bytes    *tokenBucket
+	drop     bool
+}
+
+func newRateLimiter(limit *RateLimit) *rateLimiter {
+	if limit == nil || (limit.MessagesPerSecond <= 0 && limit.BytesPerSecond <= 0) {
+		return nil
+	}
+	now := time.Now()
+	return &rateLimiter{
+		messages: newTokenBucket(limit.MessagesPerSecond, now),
+		bytes:    newTokenBucket(limit.BytesPerSecond, now),
+		drop:     limit.DropWhenExceeded,
+	}
+}
+
+// wait blocks until a message of msgLen bytes can be sent within the limits.
+// When messages are dropped instead, it returns false without blocking if the
+// message exceeds the limits.
+func (l *rateLimiter) wait(msgLen int) bool {
+	l.mutex.Lock()
+	now := time.Now()
+	if l.drop {
+		allowed := l.messages.available(1, now) && l.bytes.available(float64(msgLen), now)
+		if allowed {
+			l.messages.take(1)
+			l.bytes.take(float64(msgLen))
+		}
+		l.mutex.Unlock()
+		return allowed
+	}
+	delay := l.messages.reserve(1, now)
+	if bytesDelay := l.bytes.reserve(float64(msgLen), now); bytesDelay > delay {
+		delay = bytesDelay
+	}
+	l.mutex.Unlock()
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	return true
+}
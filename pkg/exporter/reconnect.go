@@ -0,0 +1,215 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/vmware/go-ipfix/pkg/entities"
+)
+
+const (
+	defaultReconnectInitialBackoff   = time.Second
+	defaultReconnectMaxBackoff       = time.Minute
+	defaultReconnectMaxBufferedBytes = 1 << 20
+)
+
+// ReconnectPolicy configures how the exporting process reconnects to the
+// collector when the connection cannot be established or fails. While the
+// exporting process is disconnected, messages are buffered and sending them
+// does not return an error. Once reconnected, the known templates are sent
+// again, followed by the buffered messages in order.
+type ReconnectPolicy struct {
+	// InitialBackoff is the delay before the first reconnection attempt.
+	// Default is 1s.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum delay between reconnection attempts. The delay
+	// is doubled after every failed attempt. Default is 1min.
+	MaxBackoff time.Duration
+	// MaxRetries is the maximum number of reconnection attempts per outage.
+	// The buffered messages are dropped when it is exceeded. Default is 0
+	// (no limit).
+	MaxRetries int
+	// MaxBufferedBytes is the maximum total size of the messages buffered
+	// during an outage. Sending a message which does not fit in the buffer
+	// returns an error. Default is 1MiB.
+	MaxBufferedBytes int
+}
+
+func (p ReconnectPolicy) withDefaults() *ReconnectPolicy {
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = defaultReconnectInitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaultReconnectMaxBackoff
+	}
+	if p.MaxBackoff < p.InitialBackoff {
+		p.MaxBackoff = p.InitialBackoff
+	}
+	if p.MaxBufferedBytes <= 0 {
+		p.MaxBufferedBytes = defaultReconnectMaxBufferedBytes
+	}
+	return &p
+}
+
+// writeToCollector sends the message on the connection to the collector, once
+// allowed by the rate limiter. With a reconnect policy, the message is buffered
+// if the exporting process is disconnected or if sending fails.
+func (ep *ExportingProcess) writeToCollector(msg []byte) (int, error) {
+	if ep.rateLimiter != nil && !ep.rateLimiter.wait(len(msg)) {
+		return 0, fmt.Errorf("message dropped as the rate limit is exceeded")
+	}
+	ep.connMutex.Lock()
+	defer ep.connMutex.Unlock()
+	if ep.reconnectPolicy == nil {
+		return ep.connToCollector.Write(msg)
+	}
+	if ep.connToCollector != nil && !ep.reconnecting {
+		bytesSent, err := ep.connToCollector.Write(msg)
+		if err == nil {
+			return bytesSent, nil
+		}
+		klog.ErrorS(err, "Error when sending message to the collector, reconnecting")
+		ep.connToCollector.Close()
+		ep.connToCollector = nil
+	}
+	if ep.bufferedBytes+len(msg) > ep.reconnectPolicy.MaxBufferedBytes {
+		return 0, fmt.Errorf("cannot buffer message while reconnecting to the collector: buffer limit of %d bytes reached", ep.reconnectPolicy.MaxBufferedBytes)
+	}
+	ep.bufferedMsgs = append(ep.bufferedMsgs, msg)
+	ep.bufferedBytes += len(msg)
+	ep.startReconnectLocked()
+	return 0, nil
+}
+
+// handleConnFailure closes the failed connection and starts reconnecting. It is
+// only used with a reconnect policy.
+func (ep *ExportingProcess) handleConnFailure() {
+	ep.connMutex.Lock()
+	defer ep.connMutex.Unlock()
+	if ep.connToCollector != nil {
+		ep.connToCollector.Close()
+		ep.connToCollector = nil
+	}
+	ep.startReconnectLocked()
+}
+
+// startReconnectLocked starts the reconnection goroutine unless it is already
+// running. The caller must hold connMutex.
+func (ep *ExportingProcess) startReconnectLocked() {
+	if ep.reconnecting || isChanClosed(ep.templateRefCh) {
+		return
+	}
+	ep.reconnecting = true
+	go ep.reconnect()
+}
+
+func (ep *ExportingProcess) reconnect() {
+	policy := ep.reconnectPolicy
+	backoff := policy.InitialBackoff
+	for attempt := 1; policy.MaxRetries == 0 || attempt <= policy.MaxRetries; attempt++ {
+		select {
+		case <-ep.templateRefCh: // exporting process is closed
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+		conn, err := ep.dial()
+		if err != nil {
+			klog.V(2).InfoS("Failed to reconnect to the collector", "attempt", attempt, "error", err)
+			continue
+		}
+		ep.connMutex.Lock()
+		if isChanClosed(ep.templateRefCh) {
+			ep.connMutex.Unlock()
+			conn.Close()
+			return
+		}
+		ep.connToCollector = conn
+		if err := ep.flushBufferedMsgsLocked(); err != nil {
+			klog.ErrorS(err, "Error when sending buffered messages to the collector")
+			conn.Close()
+			ep.connToCollector = nil
+			ep.connMutex.Unlock()
+			continue
+		}
+		ep.reconnecting = false
+		ep.connMutex.Unlock()
+		klog.InfoS("Reconnected to the collector", "attempt", attempt)
+		return
+	}
+	ep.connMutex.Lock()
+	defer ep.connMutex.Unlock()
+	klog.ErrorS(nil, "Failed to reconnect to the collector, dropping buffered messages", "retries", policy.MaxRetries, "numMessages", len(ep.bufferedMsgs))
+	ep.bufferedMsgs = nil
+	ep.bufferedBytes = 0
+	ep.reconnecting = false
+}
+
+// flushBufferedMsgsLocked sends the known templates and the buffered messages
+// on the new connection. The caller must hold connMutex. Buffered messages are
+// only removed once they have been sent.
+func (ep *ExportingProcess) flushBufferedMsgsLocked() error {
+	if !ep.sendJSONRecord {
+		templateMsgs, err := ep.createTemplateMsgs()
+		if err != nil {
+			return err
+		}
+		for _, msg := range templateMsgs {
+			if _, err := ep.connToCollector.Write(msg); err != nil {
+				return err
+			}
+		}
+	}
+	for len(ep.bufferedMsgs) > 0 {
+		msg := ep.bufferedMsgs[0]
+		if _, err := ep.connToCollector.Write(msg); err != nil {
+			return err
+		}
+		ep.bufferedMsgs = ep.bufferedMsgs[1:]
+		ep.bufferedBytes -= len(msg)
+	}
+	ep.bufferedMsgs = nil
+	return nil
+}
+
+// createTemplateMsgs creates one IPFIX message for every known template.
+func (ep *ExportingProcess) createTemplateMsgs() ([][]byte, error) {
+	templateSets, err := ep.createTemplateSets()
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([][]byte, 0, len(templateSets))
+	for _, templateSet := range templateSets {
+		templateSet.UpdateLenInHeader()
+		msg, err := createIPFIXMsgWithSets([]entities.Set{templateSet}, ep.obsDomainID, ep.seqNumber, ep.exportTime())
+		if err != nil {
+			return nil, err
+		}
+		if ep.compressMessages {
+			if msg, err = CreateCompressedIPFIXMsg(msg); err != nil {
+				return nil, err
+			}
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+
+	"github.com/vmware/go-ipfix/pkg/entities"
+	"github.com/vmware/go-ipfix/pkg/registry"
+)
+
+// FlowSelectorAlgorithm values as per the IANA "Packet Sampling (PSAMP)
+// Parameters" registry.
+const (
+	FlowSelectorAlgorithmSystematicCountBased uint16 = 1
+	FlowSelectorAlgorithmSystematicTimeBased  uint16 = 2
+	FlowSelectorAlgorithmRandomNOutOfN        uint16 = 3
+)
+
+// SamplingConfig describes the packet sampling applied by the exporter.
+type SamplingConfig struct {
+	// SelectorID identifies the sampling selector and is used as the scope of
+	// the sampling options record.
+	SelectorID uint64
+	// Algorithm is the flowSelectorAlgorithm. Default is
+	// FlowSelectorAlgorithmSystematicCountBased.
+	Algorithm uint16
+	// SamplingRate means that 1 out of SamplingRate packets is sampled.
+	SamplingRate uint32
+}
+
+var samplingOptionsElements = []string{"selectorId", "flowSelectorAlgorithm", "samplingPacketInterval", "samplingPacketSpace"}
+
+// sendSamplingOptions sends the sampling options data record, preceded by the
+// sampling options template if sendTemplate is true. The options template has
+// selectorId as scope, and the sampling rate is exported as 1 sampled packet
+// (samplingPacketInterval) followed by SamplingRate-1 unsampled packets
+// (samplingPacketSpace).
+func (ep *ExportingProcess) sendSamplingOptions(sendTemplate bool) error {
+	config := ep.samplingConfig
+	if config.SamplingRate == 0 {
+		return fmt.Errorf("sampling rate must be greater than 0")
+	}
+	algorithm := config.Algorithm
+	if algorithm == 0 {
+		algorithm = FlowSelectorAlgorithmSystematicCountBased
+	}
+	elements := make([]*entities.InfoElement, len(samplingOptionsElements))
+	for i, name := range samplingOptionsElements {
+		element, err := registry.GetInfoElement(name, registry.IANAEnterpriseID)
+		if err != nil {
+			return err
+		}
+		elements[i] = element
+	}
+	if sendTemplate {
+		templateElements := make([]entities.InfoElementWithValue, len(elements))
+		for i, element := range elements {
+			var err error
+			if templateElements[i], err = entities.DecodeAndCreateInfoElementWithValue(element, nil); err != nil {
+				return err
+			}
+		}
+		templateSet := entities.NewSet(false)
+		if err := templateSet.PrepareSet(entities.OptionsTemplate, ep.samplingOptionsTemplateID); err != nil {
+			return err
+		}
+		if err := templateSet.AddOptionsTemplateRecord(templateElements, 1, ep.samplingOptionsTemplateID); err != nil {
+			return err
+		}
+		if _, err := ep.SendSet(templateSet); err != nil {
+			return err
+		}
+	}
+	dataSet := entities.NewSet(false)
+	if err := dataSet.PrepareSet(entities.Data, ep.samplingOptionsTemplateID); err != nil {
+		return err
+	}
+	dataElements := []entities.InfoElementWithValue{
+		entities.NewUnsigned64InfoElement(elements[0], config.SelectorID),
+		entities.NewUnsigned16InfoElement(elements[1], algorithm),
+		entities.NewUnsigned32InfoElement(elements[2], 1),
+		entities.NewUnsigned32InfoElement(elements[3], config.SamplingRate-1),
+	}
+	if err := dataSet.AddRecord(dataElements, ep.samplingOptionsTemplateID); err != nil {
+		return err
+	}
+	_, err := ep.SendSet(dataSet)
+	return err
+}
@@ -67,6 +67,18 @@ type AggregationProcess struct {
 	inactiveExpiryTimeout time.Duration
 	// stopChan is the channel to receive stop message
 	stopChan chan bool
+	// correlateBiflows indicates whether unidirectional records are correlated
+	// into biflow records instead of being aggregated.
+	correlateBiflows bool
+	// biflowCounterElements are the counters merged into biflow records.
+	biflowCounterElements []string
+	// flowKeyFields are the names of the elements forming the flow key; the
+	// 5-tuple is used if empty.
+	flowKeyFields []string
+	// missingFlowKeyPolicy specifies what happens to the records missing some
+	// of the flowKeyFields elements.
+	missingFlowKeyPolicy MissingFlowKeyPolicy
+	passthroughHandler   func(record entities.Record)
 }
 
 type AggregationInput struct {
@@ -76,6 +88,32 @@ type AggregationInput struct {
 	AggregateElements     *AggregationElements
 	ActiveExpiryTimeout   time.Duration
 	InactiveExpiryTimeout time.Duration
+	// CorrelateBiflows specifies whether the unidirectional flow records are
+	// correlated into biflow records (RFC 5103) instead of being aggregated
+	// with CorrelateFields and AggregateElements. The first record of a flow
+	// is stored as the forward direction, and the records whose 5-tuple is
+	// the reverse of a stored flow are merged into its reverse counters.
+	CorrelateBiflows bool
+	// BiflowCounterElements are the counters merged into biflow records when
+	// CorrelateBiflows is set. They must be delta counters, since the values
+	// of the records of each direction are added up. Default is
+	// DefaultBiflowCounterElements.
+	BiflowCounterElements []string
+	// FlowKeyFields are the names of the elements forming the flow key by
+	// which the records are bucketed, e.g. to ignore the ports. The addresses,
+	// ports and protocol are stored in the corresponding fields of FlowKey,
+	// and the values of the other elements in FlowKey.OtherKeyFields. The
+	// elements must exist in the registry. Default is the 5-tuple, with either
+	// the IPv4 or the IPv6 addresses.
+	FlowKeyFields []string
+	// MissingFlowKeyPolicy specifies what happens to the records missing some
+	// of the FlowKeyFields elements when FlowKeyFields is set. Default is
+	// MissingFlowKeyDrop.
+	MissingFlowKeyPolicy MissingFlowKeyPolicy
+	// PassthroughHandler is called with every record missing some of the
+	// FlowKeyFields elements when MissingFlowKeyPolicy is
+	// MissingFlowKeyPassthrough.
+	PassthroughHandler func(record entities.Record)
 }
 
 // InitAggregationProcess takes in message channel (e.g. from collector) as input
@@ -95,6 +133,18 @@ func InitAggregationProcess(input AggregationInput) (*AggregationProcess, error)
 			return nil, fmt.Errorf("throughput elements, source throughput elements and destination throughput elemenst length should be equal")
 		}
 	}
+	for _, name := range input.FlowKeyFields {
+		if !registry.HasInfoElement(name) {
+			return nil, fmt.Errorf("flow key element %s does not exist in the registry", name)
+		}
+	}
+	if input.MissingFlowKeyPolicy == MissingFlowKeyPassthrough && input.PassthroughHandler == nil {
+		return nil, fmt.Errorf("passthrough handler is required with MissingFlowKeyPassthrough policy")
+	}
+	biflowCounterElements := input.BiflowCounterElements
+	if len(biflowCounterElements) == 0 {
+		biflowCounterElements = DefaultBiflowCounterElements
+	}
 	return &AggregationProcess{
 		make(map[FlowKey]*AggregationFlowRecord),
 		make(TimeToExpirePriorityQueue, 0),
@@ -107,6 +157,11 @@ func InitAggregationProcess(input AggregationInput) (*AggregationProcess, error)
 		input.ActiveExpiryTimeout,
 		input.InactiveExpiryTimeout,
 		make(chan bool),
+		input.CorrelateBiflows,
+		biflowCounterElements,
+		input.FlowKeyFields,
+		input.MissingFlowKeyPolicy,
+		input.PassthroughHandler,
 	}, nil
 }
 
@@ -153,11 +208,27 @@ func (a *AggregationProcess) AggregateMsgByFlowKey(message *entities.Message) er
 			klog.Errorf("Invalid data record because decoded values of elements are not valid.")
 			invalidRecs = invalidRecs + 1
 		} else {
-			flowKey, isIPv4, err := getFlowKeyFromRecord(record)
-			if err != nil {
-				return err
+			var flowKey *FlowKey
+			var isIPv4 bool
+			var err error
+			if len(a.flowKeyFields) > 0 {
+				flowKey, isIPv4, err = getConfiguredFlowKeyFromRecord(record, a.flowKeyFields)
+				if err != nil {
+					a.handleRecordMissingFlowKey(record, err)
+					continue
+				}
+			} else {
+				flowKey, isIPv4, err = getFlowKeyFromRecord(record)
+				if err != nil {
+					return err
+				}
+			}
+			if a.correlateBiflows {
+				err = a.addOrUpdateBiflowRecordInMap(flowKey, record, isIPv4)
+			} else {
+				err = a.addOrUpdateRecordInMap(flowKey, record, isIPv4)
 			}
-			if err = a.addOrUpdateRecordInMap(flowKey, record, isIPv4); err != nil {
+			if err != nil {
 				return err
 			}
 		}
@@ -550,6 +621,8 @@ func (a *AggregationProcess) aggregateRecords(incomingRecord, existingRecord ent
 		}
 	}
 
+	aggregateMinMaxElements(incomingRecord, existingRecord)
+
 	statsElementList := a.aggregateElements.StatsElements
 	antreaSourceStatsElements := a.aggregateElements.AggregatedSourceStatsElements
 	antreaDestinationStatsElements := a.aggregateElements.AggregatedDestinationStatsElements
@@ -833,6 +906,62 @@ func (a *AggregationProcess) updateFlowEndSecondsFromNodes(incomingRecord, exist
 }
 
 // TODO: We can consider to add similar methods into record interface.
+// aggregateMinMaxElements updates the elements with minimum or maximum
+// semantics in the existing record, e.g. minimumTTL and maximumTTL, so that
+// they hold the minimum or maximum of the values in both records.
+func aggregateMinMaxElements(incomingRecord, existingRecord entities.Record) {
+	for _, ieWithValue := range incomingRecord.GetOrderedElementList() {
+		if ieWithValue == nil {
+			continue
+		}
+		semantic := registry.GetAggregationSemantic(ieWithValue.GetName())
+		if semantic == registry.AggregationSemanticNone {
+			continue
+		}
+		existingIeWithValue, _, exist := existingRecord.GetInfoElementWithValue(ieWithValue.GetName())
+		if !exist {
+			continue
+		}
+		incomingVal, ok := getUnsignedValue(ieWithValue)
+		if !ok {
+			continue
+		}
+		existingVal, _ := getUnsignedValue(existingIeWithValue)
+		if (semantic == registry.AggregationSemanticMinimum && incomingVal < existingVal) ||
+			(semantic == registry.AggregationSemanticMaximum && incomingVal > existingVal) {
+			setUnsignedValue(existingIeWithValue, incomingVal)
+		}
+	}
+}
+
+func getUnsignedValue(ieWithValue entities.InfoElementWithValue) (uint64, bool) {
+	switch ieWithValue.GetDataType() {
+	case entities.Unsigned8:
+		return uint64(ieWithValue.GetUnsigned8Value()), true
+	case entities.Unsigned16:
+		return uint64(ieWithValue.GetUnsigned16Value()), true
+	case entities.Unsigned32, entities.DateTimeSeconds:
+		return uint64(ieWithValue.GetUnsigned32Value()), true
+	case entities.Unsigned64, entities.DateTimeMilliseconds, entities.DateTimeMicroseconds, entities.DateTimeNanoseconds:
+		return ieWithValue.GetUnsigned64Value(), true
+	default:
+		return 0, false
+	}
+}
+
+func setUnsignedValue(ieWithValue entities.InfoElementWithValue, val uint64) {
+	switch ieWithValue.GetDataType() {
+	case entities.Unsigned8:
+		ieWithValue.SetUnsigned8Value(uint8(val))
+	case entities.Unsigned16:
+		ieWithValue.SetUnsigned16Value(uint16(val))
+	case entities.Unsigned32, entities.DateTimeSeconds:
+		ieWithValue.SetUnsigned32Value(uint32(val))
+	case entities.Unsigned64, entities.DateTimeMilliseconds, entities.DateTimeMicroseconds, entities.DateTimeNanoseconds:
+		ieWithValue.SetUnsigned64Value(val)
+	}
+}
+
 func getUnsigned64ValueByIeName(record entities.Record, ieName string) (uint64, error) {
 	if ieWithValue, _, exist := record.GetInfoElementWithValue(ieName); exist {
 		return ieWithValue.GetUnsigned64Value(), nil
@@ -445,7 +445,7 @@ func TestAggregateMsgByFlowKey(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotZero(t, uint64(1), aggregationProcess.GetNumFlows())
 	assert.NotZero(t, aggregationProcess.expirePriorityQueue.Len())
-	flowKey := FlowKey{"10.0.0.1", "10.0.0.2", 6, 1234, 5678}
+	flowKey := FlowKey{SourceAddress: "10.0.0.1", DestinationAddress: "10.0.0.2", Protocol: 6, SourcePort: 1234, DestinationPort: 5678}
 	aggRecord := aggregationProcess.flowKeyRecordMap[flowKey]
 	assert.NotNil(t, aggregationProcess.flowKeyRecordMap[flowKey])
 	item := aggregationProcess.expirePriorityQueue.Peek()
@@ -468,7 +468,7 @@ func TestAggregateMsgByFlowKey(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, int64(2), aggregationProcess.GetNumFlows())
 	assert.Equal(t, 2, aggregationProcess.expirePriorityQueue.Len())
-	flowKey = FlowKey{"2001:0:3238:dfe1:63::fefb", "2001:0:3238:dfe1:63::fefc", 6, 1234, 5678}
+	flowKey = FlowKey{SourceAddress: "2001:0:3238:dfe1:63::fefb", DestinationAddress: "2001:0:3238:dfe1:63::fefc", Protocol: 6, SourcePort: 1234, DestinationPort: 5678}
 	assert.NotNil(t, aggregationProcess.flowKeyRecordMap[flowKey])
 	aggRecord = aggregationProcess.flowKeyRecordMap[flowKey]
 	ieWithValue, _, exist = aggRecord.Record.GetInfoElementWithValue("sourceIPv6Address")
@@ -505,7 +505,7 @@ func TestAggregationProcess(t *testing.T) {
 	// Proper usage of aggregation process is to have Start() in a goroutine with external channel
 	aggregationProcess.Start()
 	flowKey := FlowKey{
-		"10.0.0.1", "10.0.0.2", 6, 1234, 5678,
+		SourceAddress: "10.0.0.1", DestinationAddress: "10.0.0.2", Protocol: 6, SourcePort: 1234, DestinationPort: 5678,
 	}
 	aggRecord := aggregationProcess.flowKeyRecordMap[flowKey]
 	assert.Equalf(t, aggRecord.Record, dataMsg.GetSet().GetRecords()[0], "records should be equal")
@@ -661,6 +661,34 @@ func TestAggregateRecordsForInterNodeFlow(t *testing.T) {
 	runAggregationAndCheckResult(t, ap, srcRecord, dstRecord, latestSrcRecord, latestDstRecord, false)
 }
 
+func TestAggregateRecordsForMinMaxElements(t *testing.T) {
+	input := AggregationInput{
+		MessageChan:       make(chan *entities.Message),
+		WorkerNum:         2,
+		AggregateElements: &AggregationElements{},
+	}
+	ap, _ := InitAggregationProcess(input)
+	createRecord := func(flowEndSeconds uint32, minTTL, maxTTL uint8) entities.Record {
+		record := entities.NewDataRecord(256, 3, 0, true)
+		ie, _ := registry.GetInfoElement("flowEndSeconds", registry.IANAEnterpriseID)
+		record.AddInfoElement(entities.NewDateTimeSecondsInfoElement(ie, flowEndSeconds))
+		ie, _ = registry.GetInfoElement("minimumTTL", registry.IANAEnterpriseID)
+		record.AddInfoElement(entities.NewUnsigned8InfoElement(ie, minTTL))
+		ie, _ = registry.GetInfoElement("maximumTTL", registry.IANAEnterpriseID)
+		record.AddInfoElement(entities.NewUnsigned8InfoElement(ie, maxTTL))
+		return record
+	}
+	existingRecord := createRecord(1, 60, 64)
+	err := ap.aggregateRecords(createRecord(2, 50, 62), existingRecord, false, false)
+	assert.NoError(t, err)
+	err = ap.aggregateRecords(createRecord(3, 55, 128), existingRecord, false, false)
+	assert.NoError(t, err)
+	ieWithValue, _, _ := existingRecord.GetInfoElementWithValue("minimumTTL")
+	assert.Equal(t, uint8(50), ieWithValue.GetUnsigned8Value())
+	ieWithValue, _, _ = existingRecord.GetInfoElementWithValue("maximumTTL")
+	assert.Equal(t, uint8(128), ieWithValue.GetUnsigned8Value())
+}
+
 func TestDeleteFlowKeyFromMapWithLock(t *testing.T) {
 	messageChan := make(chan *entities.Message)
 	input := AggregationInput{
@@ -670,8 +698,8 @@ func TestDeleteFlowKeyFromMapWithLock(t *testing.T) {
 	}
 	aggregationProcess, _ := InitAggregationProcess(input)
 	message := createDataMsgForSrc(t, false, false, false, false, false)
-	flowKey1 := FlowKey{"10.0.0.1", "10.0.0.2", 6, 1234, 5678}
-	flowKey2 := FlowKey{"2001:0:3238:dfe1:63::fefb", "2001:0:3238:dfe1:63::fefc", 6, 1234, 5678}
+	flowKey1 := FlowKey{SourceAddress: "10.0.0.1", DestinationAddress: "10.0.0.2", Protocol: 6, SourcePort: 1234, DestinationPort: 5678}
+	flowKey2 := FlowKey{SourceAddress: "2001:0:3238:dfe1:63::fefb", DestinationAddress: "2001:0:3238:dfe1:63::fefc", Protocol: 6, SourcePort: 1234, DestinationPort: 5678}
 	aggFlowRecord := &AggregationFlowRecord{
 		Record:                    message.GetSet().GetRecords()[0],
 		PriorityQueueItem:         &ItemToExpire{},
@@ -0,0 +1,154 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intermediate
+
+import (
+	"container/heap"
+	"fmt"
+	"time"
+
+	"github.com/vmware/go-ipfix/pkg/entities"
+	"github.com/vmware/go-ipfix/pkg/registry"
+)
+
+// DefaultBiflowCounterElements are the counters merged into biflow records
+// when AggregationInput.BiflowCounterElements is not set.
+var DefaultBiflowCounterElements = []string{
+	"packetDeltaCount",
+	"octetDeltaCount",
+}
+
+// reverseFlowKey returns the flow key of the opposite direction of the flow.
+func reverseFlowKey(flowKey *FlowKey) *FlowKey {
+	return &FlowKey{
+		SourceAddress:      flowKey.DestinationAddress,
+		DestinationAddress: flowKey.SourceAddress,
+		Protocol:           flowKey.Protocol,
+		SourcePort:         flowKey.DestinationPort,
+		DestinationPort:    flowKey.SourcePort,
+		OtherKeyFields:     flowKey.OtherKeyFields,
+	}
+}
+
+// addOrUpdateBiflowRecordInMap either adds the record to flowKeyMap as the
+// forward direction of a new biflow, or merges its counters into the biflow
+// record of the same flow, whichever the direction of the record is.
+func (a *AggregationProcess) addOrUpdateBiflowRecordInMap(flowKey *FlowKey, record entities.Record, isIPv4 bool) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	currTime := time.Now()
+	isReverse := false
+	aggregationRecord, exist := a.flowKeyRecordMap[*flowKey]
+	if !exist {
+		reverseKey := reverseFlowKey(flowKey)
+		if aggregationRecord, exist = a.flowKeyRecordMap[*reverseKey]; exist {
+			flowKey = reverseKey
+			isReverse = true
+		}
+	}
+	if exist {
+		if err := a.mergeBiflowCounters(record, aggregationRecord.Record, isReverse); err != nil {
+			return err
+		}
+		// Reset the inactive expiry time in the queue item with updated biflow
+		// record.
+		a.expirePriorityQueue.Update(aggregationRecord.PriorityQueueItem,
+			flowKey, aggregationRecord, aggregationRecord.PriorityQueueItem.activeExpireTime, currTime.Add(a.inactiveExpiryTimeout))
+		return nil
+	}
+
+	if err := a.addReverseCounters(record); err != nil {
+		return err
+	}
+	// A biflow is ready to send even if no record is received for the reverse
+	// direction, in which case its reverse counters are zero.
+	aggregationRecord = &AggregationFlowRecord{
+		Record:                    record,
+		ReadyToSend:               true,
+		areCorrelatedFieldsFilled: true,
+		isIPv4:                    isIPv4,
+	}
+	pqItem := &ItemToExpire{
+		flowKey:            flowKey,
+		flowRecord:         aggregationRecord,
+		activeExpireTime:   currTime.Add(a.activeExpiryTimeout),
+		inactiveExpireTime: currTime.Add(a.inactiveExpiryTimeout),
+	}
+	aggregationRecord.PriorityQueueItem = pqItem
+	heap.Push(&a.expirePriorityQueue, pqItem)
+	a.flowKeyRecordMap[*flowKey] = aggregationRecord
+	return nil
+}
+
+// addReverseCounters adds the reverse elements (enterprise 29305) of the
+// biflow counters present in the record, initialized to zero.
+func (a *AggregationProcess) addReverseCounters(record entities.Record) error {
+	for _, name := range a.biflowCounterElements {
+		ieWithValue, _, exist := record.GetInfoElementWithValue(name)
+		if !exist {
+			continue
+		}
+		reverseIE, err := registry.GetInfoElementFromID(ieWithValue.GetInfoElement().ElementId, registry.IANAReversedEnterpriseID)
+		if err != nil {
+			return err
+		}
+		if _, _, exist = record.GetInfoElementWithValue(reverseIE.Name); exist {
+			continue
+		}
+		reverseIEWithValue, err := entities.DecodeAndCreateInfoElementWithValue(reverseIE, nil)
+		if err != nil {
+			return err
+		}
+		if err = record.AddInfoElement(reverseIEWithValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeBiflowCounters adds the counters of the incoming record to the counters
+// of the existing biflow record, or to their reverse elements if the incoming
+// record belongs to the reverse direction of the flow.
+func (a *AggregationProcess) mergeBiflowCounters(incomingRecord, existingRecord entities.Record, isReverse bool) error {
+	for _, name := range a.biflowCounterElements {
+		incomingIeWithValue, _, exist := incomingRecord.GetInfoElementWithValue(name)
+		if !exist {
+			continue
+		}
+		existingName := name
+		if isReverse {
+			reverseIE, err := registry.GetInfoElementFromID(incomingIeWithValue.GetInfoElement().ElementId, registry.IANAReversedEnterpriseID)
+			if err != nil {
+				return err
+			}
+			existingName = reverseIE.Name
+		}
+		existingIeWithValue, _, exist := existingRecord.GetInfoElementWithValue(existingName)
+		if !exist {
+			return fmt.Errorf("element with name %s not present in the biflow record", existingName)
+		}
+		incomingVal, ok := getUnsignedValue(incomingIeWithValue)
+		if !ok {
+			return fmt.Errorf("biflow counter %s is not an unsigned element", name)
+		}
+		existingVal, ok := getUnsignedValue(existingIeWithValue)
+		if !ok {
+			return fmt.Errorf("biflow counter %s is not an unsigned element", existingName)
+		}
+		setUnsignedValue(existingIeWithValue, existingVal+incomingVal)
+	}
+	return nil
+}
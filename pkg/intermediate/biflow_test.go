@@ -0,0 +1,187 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intermediate
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/go-ipfix/pkg/entities"
+	"github.com/vmware/go-ipfix/pkg/registry"
+)
+
+func createUniflowMsg(t *testing.T, srcIP, dstIP string, srcPort, dstPort uint16, packets, octets uint64) *entities.Message {
+	values := []struct {
+		name  string
+		value interface{}
+	}{
+		{"sourceIPv4Address", net.ParseIP(srcIP)},
+		{"destinationIPv4Address", net.ParseIP(dstIP)},
+		{"sourceTransportPort", srcPort},
+		{"destinationTransportPort", dstPort},
+		{"protocolIdentifier", uint8(6)},
+		{"packetDeltaCount", packets},
+		{"octetDeltaCount", octets},
+	}
+	elements := make([]entities.InfoElementWithValue, 0, len(values))
+	for _, v := range values {
+		ie, err := registry.GetInfoElement(v.name, registry.IANAEnterpriseID)
+		require.NoError(t, err)
+		ieWithValue, err := entities.DecodeAndCreateInfoElementWithValue(ie, nil)
+		require.NoError(t, err)
+		switch val := v.value.(type) {
+		case net.IP:
+			ieWithValue.SetIPAddressValue(val)
+		case uint16:
+			ieWithValue.SetUnsigned16Value(val)
+		case uint8:
+			ieWithValue.SetUnsigned8Value(val)
+		case uint64:
+			ieWithValue.SetUnsigned64Value(val)
+		}
+		elements = append(elements, ieWithValue)
+	}
+	set := entities.NewSet(true)
+	require.NoError(t, set.PrepareSet(entities.Data, testTemplateID))
+	require.NoError(t, set.AddRecord(elements, testTemplateID))
+	message := entities.NewMessage(true)
+	message.SetVersion(10)
+	message.SetObsDomainID(5678)
+	message.SetExportAddress("127.0.0.1")
+	message.AddSet(set)
+	return message
+}
+
+func TestAggregationProcess_CorrelateBiflows(t *testing.T) {
+	input := AggregationInput{
+		MessageChan:           make(chan *entities.Message),
+		WorkerNum:             1,
+		ActiveExpiryTimeout:   testActiveExpiry,
+		InactiveExpiryTimeout: testInactiveExpiry,
+		CorrelateBiflows:      true,
+	}
+	ap, err := InitAggregationProcess(input)
+	require.NoError(t, err)
+
+	forwardKey := FlowKey{
+		SourceAddress:      "10.0.0.1",
+		DestinationAddress: "10.0.0.2",
+		Protocol:           6,
+		SourcePort:         1234,
+		DestinationPort:    80,
+	}
+	messages := []*entities.Message{
+		createUniflowMsg(t, "10.0.0.1", "10.0.0.2", 1234, 80, 5, 500),
+		createUniflowMsg(t, "10.0.0.2", "10.0.0.1", 80, 1234, 3, 3000),
+		createUniflowMsg(t, "10.0.0.1", "10.0.0.2", 1234, 80, 2, 200),
+		// Not the reverse direction, since the ports do not match.
+		createUniflowMsg(t, "10.0.0.2", "10.0.0.1", 80, 4321, 1, 100),
+	}
+	for _, message := range messages {
+		require.NoError(t, ap.AggregateMsgByFlowKey(message))
+	}
+	assert.Equal(t, int64(2), ap.GetNumFlows())
+
+	aggRecord, exist := ap.flowKeyRecordMap[forwardKey]
+	require.True(t, exist)
+	assert.True(t, aggRecord.ReadyToSend)
+	assert.True(t, ap.IsAggregatedRecordIPv4(*aggRecord))
+	biflow, err := entities.MergeBiflow(aggRecord.Record)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), biflow.Forward["packetDeltaCount"].GetUnsigned64Value())
+	assert.Equal(t, uint64(700), biflow.Forward["octetDeltaCount"].GetUnsigned64Value())
+	assert.Equal(t, uint64(3), biflow.Reverse["packetDeltaCount"].GetUnsigned64Value())
+	assert.Equal(t, uint64(3000), biflow.Reverse["octetDeltaCount"].GetUnsigned64Value())
+	assert.Equal(t, registry.IANAReversedEnterpriseID, biflow.Reverse["octetDeltaCount"].GetInfoElement().EnterpriseId)
+
+	// A flow without any record in the reverse direction has zero reverse
+	// counters.
+	otherKey := FlowKey{
+		SourceAddress:      "10.0.0.2",
+		DestinationAddress: "10.0.0.1",
+		Protocol:           6,
+		SourcePort:         80,
+		DestinationPort:    4321,
+	}
+	aggRecord, exist = ap.flowKeyRecordMap[otherKey]
+	require.True(t, exist)
+	ieWithValue, _, exist := aggRecord.Record.GetInfoElementWithValue("reversePacketDeltaCount")
+	require.True(t, exist)
+	assert.Equal(t, uint64(0), ieWithValue.GetUnsigned64Value())
+
+	// The biflows are emitted on active expiry and deleted on inactive expiry.
+	var expiredKeys []FlowKey
+	callback := func(key FlowKey, record *AggregationFlowRecord) error {
+		expiredKeys = append(expiredKeys, key)
+		return nil
+	}
+	time.Sleep(testActiveExpiry)
+	require.NoError(t, ap.ForAllExpiredFlowRecordsDo(callback))
+	assert.ElementsMatch(t, []FlowKey{forwardKey, otherKey}, expiredKeys)
+	assert.Equal(t, int64(2), ap.GetNumFlows())
+	time.Sleep(testInactiveExpiry)
+	require.NoError(t, ap.ForAllExpiredFlowRecordsDo(callback))
+	assert.Equal(t, int64(0), ap.GetNumFlows())
+}
+
+func TestAggregationProcess_CorrelateBiflowsWithFlowKeyFields(t *testing.T) {
+	vlanID, err := registry.GetInfoElement("vlanId", registry.IANAEnterpriseID)
+	require.NoError(t, err)
+	createMsg := func(srcIP, dstIP string, srcPort, dstPort uint16, vlan uint16, packets uint64) *entities.Message {
+		message := createUniflowMsg(t, srcIP, dstIP, srcPort, dstPort, packets, packets*100)
+		require.NoError(t, message.GetSet().GetRecords()[0].AddInfoElement(entities.NewUnsigned16InfoElement(vlanID, vlan)))
+		return message
+	}
+	ap, err := InitAggregationProcess(AggregationInput{
+		MessageChan:      make(chan *entities.Message),
+		WorkerNum:        1,
+		CorrelateBiflows: true,
+		FlowKeyFields:    []string{"sourceIPv4Address", "destinationIPv4Address", "protocolIdentifier", "sourceTransportPort", "destinationTransportPort", "vlanId"},
+	})
+	require.NoError(t, err)
+	messages := []*entities.Message{
+		createMsg("10.0.0.1", "10.0.0.2", 1234, 80, 10, 5),
+		createMsg("10.0.0.2", "10.0.0.1", 80, 1234, 10, 3),
+		// Not the reverse direction, since the VLAN does not match.
+		createMsg("10.0.0.2", "10.0.0.1", 80, 1234, 20, 1),
+	}
+	for _, message := range messages {
+		require.NoError(t, ap.AggregateMsgByFlowKey(message))
+	}
+	assert.Equal(t, int64(2), ap.GetNumFlows())
+
+	forwardKey := FlowKey{
+		SourceAddress:      "10.0.0.1",
+		DestinationAddress: "10.0.0.2",
+		Protocol:           6,
+		SourcePort:         1234,
+		DestinationPort:    80,
+		OtherKeyFields:     "vlanId=10",
+	}
+	aggRecord, exist := ap.flowKeyRecordMap[forwardKey]
+	require.True(t, exist)
+	biflow, err := entities.MergeBiflow(aggRecord.Record)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), biflow.Forward["packetDeltaCount"].GetUnsigned64Value())
+	assert.Equal(t, uint64(3), biflow.Reverse["packetDeltaCount"].GetUnsigned64Value())
+	otherKey := *reverseFlowKey(&forwardKey)
+	otherKey.OtherKeyFields = "vlanId=20"
+	_, exist = ap.flowKeyRecordMap[otherKey]
+	assert.True(t, exist)
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intermediate
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/vmware/go-ipfix/pkg/entities"
+)
+
+// MissingFlowKeyPolicy specifies what happens to the records missing some of
+// the elements configured with AggregationInput.FlowKeyFields.
+type MissingFlowKeyPolicy uint8
+
+const (
+	// MissingFlowKeyDrop drops the records missing some of the flow key
+	// elements.
+	MissingFlowKeyDrop MissingFlowKeyPolicy = iota
+	// MissingFlowKeyPassthrough passes the records missing some of the flow
+	// key elements to AggregationInput.PassthroughHandler, without
+	// aggregating them.
+	MissingFlowKeyPassthrough
+)
+
+// getConfiguredFlowKeyFromRecord returns the flow key formed by the given
+// elements of the data record, and whether the addresses in the key are IPv4
+// addresses.
+func getConfiguredFlowKeyFromRecord(record entities.Record, keyFields []string) (*FlowKey, bool, error) {
+	flowKey := &FlowKey{}
+	var otherKeyFields []string
+	isIPv4 := false
+	for _, name := range keyFields {
+		element, _, exist := record.GetInfoElementWithValue(name)
+		if !exist {
+			return nil, false, fmt.Errorf("flow key element %s does not exist", name)
+		}
+		switch name {
+		case "sourceIPv4Address", "sourceIPv6Address":
+			flowKey.SourceAddress = element.GetIPAddressValue().String()
+		case "destinationIPv4Address", "destinationIPv6Address":
+			flowKey.DestinationAddress = element.GetIPAddressValue().String()
+		case "sourceTransportPort":
+			flowKey.SourcePort = element.GetUnsigned16Value()
+		case "destinationTransportPort":
+			flowKey.DestinationPort = element.GetUnsigned16Value()
+		case "protocolIdentifier":
+			flowKey.Protocol = element.GetUnsigned8Value()
+		default:
+			otherKeyFields = append(otherKeyFields, name+"="+entities.FormatElementValue(element))
+		}
+		if name == "sourceIPv4Address" || name == "destinationIPv4Address" {
+			isIPv4 = true
+		}
+	}
+	flowKey.OtherKeyFields = strings.Join(otherKeyFields, ",")
+	return flowKey, isIPv4, nil
+}
+
+// handleRecordMissingFlowKey drops the record missing some of the flow key
+// elements or passes it through, according to missingFlowKeyPolicy.
+func (a *AggregationProcess) handleRecordMissingFlowKey(record entities.Record, err error) {
+	if a.missingFlowKeyPolicy == MissingFlowKeyPassthrough {
+		a.passthroughHandler(record)
+		return
+	}
+	klog.V(4).InfoS("Dropping record without flow key", "err", err)
+}
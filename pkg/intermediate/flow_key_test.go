@@ -0,0 +1,112 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intermediate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/go-ipfix/pkg/entities"
+	"github.com/vmware/go-ipfix/pkg/registry"
+)
+
+func TestInitAggregationProcess_FlowKeyFields(t *testing.T) {
+	input := AggregationInput{
+		MessageChan:   make(chan *entities.Message),
+		WorkerNum:     1,
+		FlowKeyFields: []string{"sourceIPv4Address", "unknownElement"},
+	}
+	_, err := InitAggregationProcess(input)
+	assert.ErrorContains(t, err, "flow key element unknownElement does not exist in the registry")
+
+	input.FlowKeyFields = []string{"sourceIPv4Address", "reverseOctetDeltaCount", "sourcePodName"}
+	input.MissingFlowKeyPolicy = MissingFlowKeyPassthrough
+	_, err = InitAggregationProcess(input)
+	assert.ErrorContains(t, err, "passthrough handler is required")
+
+	input.PassthroughHandler = func(record entities.Record) {}
+	_, err = InitAggregationProcess(input)
+	assert.NoError(t, err)
+}
+
+func TestAggregationProcess_FlowKeyFields(t *testing.T) {
+	ingressInterface, err := registry.GetInfoElement("ingressInterface", registry.IANAEnterpriseID)
+	require.NoError(t, err)
+	createMsg := func(srcPort uint16, ifIndex uint32, packets uint64) *entities.Message {
+		message := createUniflowMsg(t, "10.0.0.1", "10.0.0.2", srcPort, 80, packets, packets*100)
+		if ifIndex != 0 {
+			require.NoError(t, message.GetSet().GetRecords()[0].AddInfoElement(entities.NewUnsigned32InfoElement(ingressInterface, ifIndex)))
+		}
+		return message
+	}
+	// The ports are not part of the flow key.
+	keyFields := []string{"sourceIPv4Address", "destinationIPv4Address", "protocolIdentifier", "ingressInterface"}
+	messages := []*entities.Message{
+		createMsg(1234, 3, 5),
+		createMsg(4321, 3, 2),
+		createMsg(1234, 4, 1),
+		// Record without ingressInterface.
+		createMsg(1234, 0, 1),
+	}
+
+	t.Run("passthrough", func(t *testing.T) {
+		var passthroughRecords []entities.Record
+		ap, err := InitAggregationProcess(AggregationInput{
+			MessageChan:          make(chan *entities.Message),
+			WorkerNum:            1,
+			CorrelateBiflows:     true,
+			FlowKeyFields:        keyFields,
+			MissingFlowKeyPolicy: MissingFlowKeyPassthrough,
+			PassthroughHandler: func(record entities.Record) {
+				passthroughRecords = append(passthroughRecords, record)
+			},
+		})
+		require.NoError(t, err)
+		for _, message := range messages {
+			require.NoError(t, ap.AggregateMsgByFlowKey(message))
+		}
+		assert.Equal(t, int64(2), ap.GetNumFlows())
+		flowKey := FlowKey{
+			SourceAddress:      "10.0.0.1",
+			DestinationAddress: "10.0.0.2",
+			Protocol:           6,
+			OtherKeyFields:     "ingressInterface=3",
+		}
+		aggRecord, exist := ap.flowKeyRecordMap[flowKey]
+		require.True(t, exist)
+		assert.True(t, ap.IsAggregatedRecordIPv4(*aggRecord))
+		ieWithValue, _, _ := aggRecord.Record.GetInfoElementWithValue("packetDeltaCount")
+		assert.Equal(t, uint64(7), ieWithValue.GetUnsigned64Value())
+		flowKey.OtherKeyFields = "ingressInterface=4"
+		_, exist = ap.flowKeyRecordMap[flowKey]
+		assert.True(t, exist)
+		require.Len(t, passthroughRecords, 1)
+		assert.Equal(t, messages[3].GetSet().GetRecords()[0], passthroughRecords[0])
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		ap, err := InitAggregationProcess(AggregationInput{
+			MessageChan:      make(chan *entities.Message),
+			WorkerNum:        1,
+			CorrelateBiflows: true,
+			FlowKeyFields:    keyFields,
+		})
+		require.NoError(t, err)
+		require.NoError(t, ap.AggregateMsgByFlowKey(createMsg(1234, 0, 1)))
+		assert.Equal(t, int64(0), ap.GetNumFlows())
+	})
+}
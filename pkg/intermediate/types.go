@@ -22,6 +22,10 @@ type FlowKey struct {
 	Protocol           uint8
 	SourcePort         uint16
 	DestinationPort    uint16
+	// OtherKeyFields are the values of the flow key elements other than the
+	// 5-tuple when AggregationInput.FlowKeyFields is set, e.g.
+	// "ingressInterface=3".
+	OtherKeyFields string
 }
 
 type AggregationFlowRecord struct {
@@ -67,16 +67,36 @@ import (
 func loadIANARegistry() {
 `)
 
+	// find the columns of the units and data type semantics in the header
+	unitsColumn, semanticsColumn := -1, -1
+	if len(data) > 0 {
+		for i, column := range data[0] {
+			switch column {
+			case "Units":
+				unitsColumn = i
+			case "Data Type Semantics":
+				semanticsColumn = i
+			}
+		}
+	}
 	for idx, row := range data {
 		// skip header and reserved line
 		if idx == 0 || idx == 1 {
 			continue
 		}
 
-		writer.WriteString("	registerInfoElement(*entities.NewInfoElement(")
 		parameters := generateIEString(row[1], row[0], row[2], "0")
-		fmt.Fprintf(writer, parameters)
-		writer.WriteString("), ")
+		units := getColumn(row, unitsColumn)
+		semantics := getColumn(row, semanticsColumn)
+		if units == "" && semantics == "" {
+			writer.WriteString("	registerInfoElement(*entities.NewInfoElement(")
+			fmt.Fprintf(writer, parameters)
+			writer.WriteString("), ")
+		} else {
+			writer.WriteString("	registerInfoElement(*withMetadata(entities.NewInfoElement(")
+			fmt.Fprintf(writer, parameters)
+			fmt.Fprintf(writer, "), %q, %q), ", units, semantics)
+		}
 		fmt.Fprintf(writer, fmt.Sprint(registry.IANAEnterpriseID))
 		writer.WriteString(")\n")
 	}
@@ -163,6 +183,13 @@ func readCSVFromFile(name string) ([][]string, error) {
 	return data, nil
 }
 
+func getColumn(row []string, column int) string {
+	if column < 0 || column >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[column])
+}
+
 func generateIEString(name string, elementid string, datatype string, enterpriseid string) string {
 	elementID, _ := strconv.ParseUint(elementid, 10, 16)
 	enterpriseID, _ := strconv.ParseUint(enterpriseid, 10, 16)
@@ -61,11 +61,53 @@ const (
 // enum for flowEndReason field in IANA registry.
 // List of RFC supported reasons: https://www.iana.org/assignments/ipfix/ipfix.xhtml#ipfix-flow-end-reason
 const (
-	IdleTimeoutReason   = uint8(0x01)
-	ActiveTimeoutReason = uint8(0x02)
-	EndOfFlowReason     = uint8(0x03)
+	IdleTimeoutReason     = uint8(0x01)
+	ActiveTimeoutReason   = uint8(0x02)
+	EndOfFlowReason       = uint8(0x03)
+	ForcedEndReason       = uint8(0x04)
+	LackOfResourcesReason = uint8(0x05)
 )
 
+// AggregationSemantic specifies how the values of an information element in
+// multiple records of the same flow are combined when aggregating them.
+type AggregationSemantic uint8
+
+const (
+	// AggregationSemanticNone is used for elements without specific aggregation semantics.
+	AggregationSemanticNone AggregationSemantic = iota
+	// AggregationSemanticMinimum is used for elements holding the minimum of an observed value.
+	AggregationSemanticMinimum
+	// AggregationSemanticMaximum is used for elements holding the maximum of an observed value.
+	AggregationSemanticMaximum
+)
+
+// aggregationSemantics maps the names of IANA elements holding the minimum or
+// maximum of an observed value to their aggregation semantics.
+var aggregationSemantics = map[string]AggregationSemantic{
+	"minimumIpTotalLength":     AggregationSemanticMinimum,
+	"maximumIpTotalLength":     AggregationSemanticMaximum,
+	"minimumTTL":               AggregationSemanticMinimum,
+	"maximumTTL":               AggregationSemanticMaximum,
+	"minExportSeconds":         AggregationSemanticMinimum,
+	"maxExportSeconds":         AggregationSemanticMaximum,
+	"minFlowStartSeconds":      AggregationSemanticMinimum,
+	"minFlowStartMilliseconds": AggregationSemanticMinimum,
+	"minFlowStartMicroseconds": AggregationSemanticMinimum,
+	"minFlowStartNanoseconds":  AggregationSemanticMinimum,
+	"maxFlowEndSeconds":        AggregationSemanticMaximum,
+	"maxFlowEndMilliseconds":   AggregationSemanticMaximum,
+	"maxFlowEndMicroseconds":   AggregationSemanticMaximum,
+	"maxFlowEndNanoseconds":    AggregationSemanticMaximum,
+}
+
+// withMetadata sets the units and data type semantics of the element, as
+// listed in the IANA registry.
+func withMetadata(ie *entities.InfoElement, units string, semantics string) *entities.InfoElement {
+	ie.Units = units
+	ie.Semantics = semantics
+	return ie
+}
+
 var (
 	// globalRegistryByID shows mapping EnterpriseID -> Info element ID -> Info element
 	globalRegistryByID map[uint32]map[uint16]*entities.InfoElement
@@ -82,7 +124,28 @@ func InitNewRegistry(customEnterpriseID uint32) error {
 	return nil
 }
 
+// HasRegistry returns whether the registry with the given enterprise ID has
+// been initialized.
+func HasRegistry(enterpriseID uint32) bool {
+	_, exist := globalRegistryByID[enterpriseID]
+	return exist
+}
+
+// HasInfoElement returns whether an information element with the given name
+// exists in any of the registries.
+func HasInfoElement(name string) bool {
+	for _, elements := range globalRegistryByName {
+		if _, exist := elements[name]; exist {
+			return true
+		}
+	}
+	return false
+}
+
 func PutInfoElement(ie entities.InfoElement, enterpriseID uint32) error {
+	if ie.EnterpriseId != enterpriseID {
+		return fmt.Errorf("EnterpriseID %d of information element %s does not match registry with EnterpriseID %d", ie.EnterpriseId, ie.Name, enterpriseID)
+	}
 	if _, exist := globalRegistryByName[enterpriseID]; !exist {
 		return fmt.Errorf("Registry with EnterpriseID %d is not Initialized, Please use InitNewRegistry", ie.EnterpriseId)
 	} else if _, exist = globalRegistryByName[enterpriseID][ie.Name]; exist {
@@ -108,6 +171,9 @@ func LoadRegistry() {
 	loadAntreaRegistry()
 }
 
+// GetInfoElementFromID returns the information element with the given element
+// ID in the registry with the given enterprise ID. This is the lookup used by
+// the collecting process to resolve the fields of the templates it decodes.
 func GetInfoElementFromID(elementID uint16, enterpriseID uint32) (*entities.InfoElement, error) {
 	if _, exist := globalRegistryByID[enterpriseID]; !exist {
 		return nil, fmt.Errorf("Registry with EnterpriseID %d is not supported.", enterpriseID)
@@ -119,6 +185,8 @@ func GetInfoElementFromID(elementID uint16, enterpriseID uint32) (*entities.Info
 	}
 }
 
+// GetInfoElement returns the information element with the given name in the
+// registry with the given enterprise ID.
 func GetInfoElement(name string, enterpriseID uint32) (*entities.InfoElement, error) {
 	if _, exist := globalRegistryByName[enterpriseID]; !exist {
 		return nil, fmt.Errorf("Registry with EnterpriseID %d is not supported.", enterpriseID)
@@ -164,7 +232,10 @@ func getIANAReverseInfoElement(name string) (*entities.InfoElement, error) {
 	if ie.Name != "" {
 		reverseName += strings.ToUpper(ie.Name[:1]) + ie.Name[1:]
 	}
-	return entities.NewInfoElement(reverseName, ie.ElementId, ie.DataType, IANAReversedEnterpriseID, ie.Len), nil
+	reverseIE := entities.NewInfoElement(reverseName, ie.ElementId, ie.DataType, IANAReversedEnterpriseID, ie.Len)
+	reverseIE.Units = ie.Units
+	reverseIE.Semantics = ie.Semantics
+	return reverseIE, nil
 }
 
 // Non-reversible Information Elements follow Section 6.1 of RFC5103
@@ -199,3 +270,12 @@ var nonReversibleIEs = map[string]bool{
 func isReversible(name string) bool {
 	return !nonReversibleIEs[name]
 }
+
+// GetAggregationSemantic returns the aggregation semantics of the IANA element
+// with the given name, e.g. AggregationSemanticMinimum for minimumTTL.
+func GetAggregationSemantic(name string) AggregationSemantic {
+	if semantic, exist := aggregationSemantics[name]; exist {
+		return semantic
+	}
+	return AggregationSemanticNone
+}
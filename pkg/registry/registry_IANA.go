@@ -21,20 +21,20 @@ import (
 // AUTO GENERATED, DO NOT CHANGE
 
 func loadIANARegistry() {
-	registerInfoElement(*entities.NewInfoElement("octetDeltaCount", 1, 4, 0, 8), 0)
-	registerInfoElement(*entities.NewInfoElement("packetDeltaCount", 2, 4, 0, 8), 0)
-	registerInfoElement(*entities.NewInfoElement("deltaFlowCount", 3, 4, 0, 8), 0)
-	registerInfoElement(*entities.NewInfoElement("protocolIdentifier", 4, 1, 0, 1), 0)
-	registerInfoElement(*entities.NewInfoElement("ipClassOfService", 5, 1, 0, 1), 0)
-	registerInfoElement(*entities.NewInfoElement("tcpControlBits", 6, 2, 0, 2), 0)
-	registerInfoElement(*entities.NewInfoElement("sourceTransportPort", 7, 2, 0, 2), 0)
-	registerInfoElement(*entities.NewInfoElement("sourceIPv4Address", 8, 18, 0, 4), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("octetDeltaCount", 1, 4, 0, 8), "octets", "deltaCounter"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("packetDeltaCount", 2, 4, 0, 8), "packets", "deltaCounter"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("deltaFlowCount", 3, 4, 0, 8), "flows", "deltaCounter"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("protocolIdentifier", 4, 1, 0, 1), "", "identifier"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("ipClassOfService", 5, 1, 0, 1), "", "identifier"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("tcpControlBits", 6, 2, 0, 2), "", "flags"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("sourceTransportPort", 7, 2, 0, 2), "", "identifier"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("sourceIPv4Address", 8, 18, 0, 4), "", "default"), 0)
 	registerInfoElement(*entities.NewInfoElement("sourceIPv4PrefixLength", 9, 1, 0, 1), 0)
-	registerInfoElement(*entities.NewInfoElement("ingressInterface", 10, 3, 0, 4), 0)
-	registerInfoElement(*entities.NewInfoElement("destinationTransportPort", 11, 2, 0, 2), 0)
-	registerInfoElement(*entities.NewInfoElement("destinationIPv4Address", 12, 18, 0, 4), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("ingressInterface", 10, 3, 0, 4), "", "identifier"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("destinationTransportPort", 11, 2, 0, 2), "", "identifier"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("destinationIPv4Address", 12, 18, 0, 4), "", "default"), 0)
 	registerInfoElement(*entities.NewInfoElement("destinationIPv4PrefixLength", 13, 1, 0, 1), 0)
-	registerInfoElement(*entities.NewInfoElement("egressInterface", 14, 3, 0, 4), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("egressInterface", 14, 3, 0, 4), "", "identifier"), 0)
 	registerInfoElement(*entities.NewInfoElement("ipNextHopIPv4Address", 15, 18, 0, 4), 0)
 	registerInfoElement(*entities.NewInfoElement("bgpSourceAsNumber", 16, 3, 0, 4), 0)
 	registerInfoElement(*entities.NewInfoElement("bgpDestinationAsNumber", 17, 3, 0, 4), 0)
@@ -43,12 +43,12 @@ func loadIANARegistry() {
 	registerInfoElement(*entities.NewInfoElement("postMCastOctetDeltaCount", 20, 4, 0, 8), 0)
 	registerInfoElement(*entities.NewInfoElement("flowEndSysUpTime", 21, 3, 0, 4), 0)
 	registerInfoElement(*entities.NewInfoElement("flowStartSysUpTime", 22, 3, 0, 4), 0)
-	registerInfoElement(*entities.NewInfoElement("postOctetDeltaCount", 23, 4, 0, 8), 0)
-	registerInfoElement(*entities.NewInfoElement("postPacketDeltaCount", 24, 4, 0, 8), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("postOctetDeltaCount", 23, 4, 0, 8), "octets", "deltaCounter"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("postPacketDeltaCount", 24, 4, 0, 8), "packets", "deltaCounter"), 0)
 	registerInfoElement(*entities.NewInfoElement("minimumIpTotalLength", 25, 4, 0, 8), 0)
 	registerInfoElement(*entities.NewInfoElement("maximumIpTotalLength", 26, 4, 0, 8), 0)
-	registerInfoElement(*entities.NewInfoElement("sourceIPv6Address", 27, 19, 0, 16), 0)
-	registerInfoElement(*entities.NewInfoElement("destinationIPv6Address", 28, 19, 0, 16), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("sourceIPv6Address", 27, 19, 0, 16), "", "default"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("destinationIPv6Address", 28, 19, 0, 16), "", "default"), 0)
 	registerInfoElement(*entities.NewInfoElement("sourceIPv6PrefixLength", 29, 1, 0, 1), 0)
 	registerInfoElement(*entities.NewInfoElement("destinationIPv6PrefixLength", 30, 1, 0, 1), 0)
 	registerInfoElement(*entities.NewInfoElement("flowLabelIPv6", 31, 3, 0, 4), 0)
@@ -56,8 +56,8 @@ func loadIANARegistry() {
 	registerInfoElement(*entities.NewInfoElement("igmpType", 33, 1, 0, 1), 0)
 	registerInfoElement(*entities.NewInfoElement("samplingInterval", 34, 3, 0, 4), 0)
 	registerInfoElement(*entities.NewInfoElement("samplingAlgorithm", 35, 1, 0, 1), 0)
-	registerInfoElement(*entities.NewInfoElement("flowActiveTimeout", 36, 2, 0, 2), 0)
-	registerInfoElement(*entities.NewInfoElement("flowIdleTimeout", 37, 2, 0, 2), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("flowActiveTimeout", 36, 2, 0, 2), "seconds", "quantity"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("flowIdleTimeout", 37, 2, 0, 2), "seconds", "quantity"), 0)
 	registerInfoElement(*entities.NewInfoElement("engineType", 38, 1, 0, 1), 0)
 	registerInfoElement(*entities.NewInfoElement("engineId", 39, 1, 0, 1), 0)
 	registerInfoElement(*entities.NewInfoElement("exportedOctetTotalCount", 40, 4, 0, 8), 0)
@@ -72,8 +72,8 @@ func loadIANARegistry() {
 	registerInfoElement(*entities.NewInfoElement("samplerMode", 49, 1, 0, 1), 0)
 	registerInfoElement(*entities.NewInfoElement("samplerRandomInterval", 50, 3, 0, 4), 0)
 	registerInfoElement(*entities.NewInfoElement("classId", 51, 1, 0, 1), 0)
-	registerInfoElement(*entities.NewInfoElement("minimumTTL", 52, 1, 0, 1), 0)
-	registerInfoElement(*entities.NewInfoElement("maximumTTL", 53, 1, 0, 1), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("minimumTTL", 52, 1, 0, 1), "hops", "quantity"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("maximumTTL", 53, 1, 0, 1), "hops", "quantity"), 0)
 	registerInfoElement(*entities.NewInfoElement("fragmentIdentification", 54, 3, 0, 4), 0)
 	registerInfoElement(*entities.NewInfoElement("postIpClassOfService", 55, 1, 0, 1), 0)
 	registerInfoElement(*entities.NewInfoElement("sourceMacAddress", 56, 12, 0, 6), 0)
@@ -101,8 +101,8 @@ func loadIANARegistry() {
 	registerInfoElement(*entities.NewInfoElement("interfaceName", 82, 13, 0, 65535), 0)
 	registerInfoElement(*entities.NewInfoElement("interfaceDescription", 83, 13, 0, 65535), 0)
 	registerInfoElement(*entities.NewInfoElement("samplerName", 84, 13, 0, 65535), 0)
-	registerInfoElement(*entities.NewInfoElement("octetTotalCount", 85, 4, 0, 8), 0)
-	registerInfoElement(*entities.NewInfoElement("packetTotalCount", 86, 4, 0, 8), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("octetTotalCount", 85, 4, 0, 8), "octets", "totalCounter"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("packetTotalCount", 86, 4, 0, 8), "packets", "totalCounter"), 0)
 	registerInfoElement(*entities.NewInfoElement("flagsAndSamplerId", 87, 3, 0, 4), 0)
 	registerInfoElement(*entities.NewInfoElement("fragmentOffset", 88, 2, 0, 2), 0)
 	registerInfoElement(*entities.NewInfoElement("forwardingStatus", 89, 1, 0, 1), 0)
@@ -126,11 +126,11 @@ func loadIANARegistry() {
 	registerInfoElement(*entities.NewInfoElement("bgpPrevAdjacentAsNumber", 129, 3, 0, 4), 0)
 	registerInfoElement(*entities.NewInfoElement("exporterIPv4Address", 130, 18, 0, 4), 0)
 	registerInfoElement(*entities.NewInfoElement("exporterIPv6Address", 131, 19, 0, 16), 0)
-	registerInfoElement(*entities.NewInfoElement("droppedOctetDeltaCount", 132, 4, 0, 8), 0)
-	registerInfoElement(*entities.NewInfoElement("droppedPacketDeltaCount", 133, 4, 0, 8), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("droppedOctetDeltaCount", 132, 4, 0, 8), "octets", "deltaCounter"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("droppedPacketDeltaCount", 133, 4, 0, 8), "packets", "deltaCounter"), 0)
 	registerInfoElement(*entities.NewInfoElement("droppedOctetTotalCount", 134, 4, 0, 8), 0)
 	registerInfoElement(*entities.NewInfoElement("droppedPacketTotalCount", 135, 4, 0, 8), 0)
-	registerInfoElement(*entities.NewInfoElement("flowEndReason", 136, 1, 0, 1), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("flowEndReason", 136, 1, 0, 1), "", "identifier"), 0)
 	registerInfoElement(*entities.NewInfoElement("commonPropertiesId", 137, 4, 0, 8), 0)
 	registerInfoElement(*entities.NewInfoElement("observationPointId", 138, 4, 0, 8), 0)
 	registerInfoElement(*entities.NewInfoElement("icmpTypeCodeIPv6", 139, 2, 0, 2), 0)
@@ -142,12 +142,12 @@ func loadIANARegistry() {
 	registerInfoElement(*entities.NewInfoElement("templateId", 145, 2, 0, 2), 0)
 	registerInfoElement(*entities.NewInfoElement("wlanChannelId", 146, 1, 0, 1), 0)
 	registerInfoElement(*entities.NewInfoElement("wlanSSID", 147, 13, 0, 65535), 0)
-	registerInfoElement(*entities.NewInfoElement("flowId", 148, 4, 0, 8), 0)
-	registerInfoElement(*entities.NewInfoElement("observationDomainId", 149, 3, 0, 4), 0)
-	registerInfoElement(*entities.NewInfoElement("flowStartSeconds", 150, 14, 0, 4), 0)
-	registerInfoElement(*entities.NewInfoElement("flowEndSeconds", 151, 14, 0, 4), 0)
-	registerInfoElement(*entities.NewInfoElement("flowStartMilliseconds", 152, 15, 0, 8), 0)
-	registerInfoElement(*entities.NewInfoElement("flowEndMilliseconds", 153, 15, 0, 8), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("flowId", 148, 4, 0, 8), "", "identifier"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("observationDomainId", 149, 3, 0, 4), "", "identifier"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("flowStartSeconds", 150, 14, 0, 4), "seconds", "default"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("flowEndSeconds", 151, 14, 0, 4), "seconds", "default"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("flowStartMilliseconds", 152, 15, 0, 8), "milliseconds", "default"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("flowEndMilliseconds", 153, 15, 0, 8), "milliseconds", "default"), 0)
 	registerInfoElement(*entities.NewInfoElement("flowStartMicroseconds", 154, 16, 0, 8), 0)
 	registerInfoElement(*entities.NewInfoElement("flowEndMicroseconds", 155, 16, 0, 8), 0)
 	registerInfoElement(*entities.NewInfoElement("flowStartNanoseconds", 156, 17, 0, 8), 0)
@@ -155,7 +155,7 @@ func loadIANARegistry() {
 	registerInfoElement(*entities.NewInfoElement("flowStartDeltaMicroseconds", 158, 3, 0, 4), 0)
 	registerInfoElement(*entities.NewInfoElement("flowEndDeltaMicroseconds", 159, 3, 0, 4), 0)
 	registerInfoElement(*entities.NewInfoElement("systemInitTimeMilliseconds", 160, 15, 0, 8), 0)
-	registerInfoElement(*entities.NewInfoElement("flowDurationMilliseconds", 161, 3, 0, 4), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("flowDurationMilliseconds", 161, 3, 0, 4), "milliseconds", "quantity"), 0)
 	registerInfoElement(*entities.NewInfoElement("flowDurationMicroseconds", 162, 3, 0, 4), 0)
 	registerInfoElement(*entities.NewInfoElement("observedFlowTotalCount", 163, 4, 0, 8), 0)
 	registerInfoElement(*entities.NewInfoElement("ignoredPacketTotalCount", 164, 4, 0, 8), 0)
@@ -183,22 +183,22 @@ func loadIANARegistry() {
 	registerInfoElement(*entities.NewInfoElement("tcpWindowSize", 186, 2, 0, 2), 0)
 	registerInfoElement(*entities.NewInfoElement("tcpUrgentPointer", 187, 2, 0, 2), 0)
 	registerInfoElement(*entities.NewInfoElement("tcpHeaderLength", 188, 1, 0, 1), 0)
-	registerInfoElement(*entities.NewInfoElement("ipHeaderLength", 189, 1, 0, 1), 0)
-	registerInfoElement(*entities.NewInfoElement("totalLengthIPv4", 190, 2, 0, 2), 0)
-	registerInfoElement(*entities.NewInfoElement("payloadLengthIPv6", 191, 2, 0, 2), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("ipHeaderLength", 189, 1, 0, 1), "4-octet words", "quantity"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("totalLengthIPv4", 190, 2, 0, 2), "octets", "quantity"), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("payloadLengthIPv6", 191, 2, 0, 2), "octets", "quantity"), 0)
 	registerInfoElement(*entities.NewInfoElement("ipTTL", 192, 1, 0, 1), 0)
 	registerInfoElement(*entities.NewInfoElement("nextHeaderIPv6", 193, 1, 0, 1), 0)
 	registerInfoElement(*entities.NewInfoElement("mplsPayloadLength", 194, 3, 0, 4), 0)
 	registerInfoElement(*entities.NewInfoElement("ipDiffServCodePoint", 195, 1, 0, 1), 0)
 	registerInfoElement(*entities.NewInfoElement("ipPrecedence", 196, 1, 0, 1), 0)
 	registerInfoElement(*entities.NewInfoElement("fragmentFlags", 197, 1, 0, 1), 0)
-	registerInfoElement(*entities.NewInfoElement("octetDeltaSumOfSquares", 198, 4, 0, 8), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("octetDeltaSumOfSquares", 198, 4, 0, 8), "", "quantity"), 0)
 	registerInfoElement(*entities.NewInfoElement("octetTotalSumOfSquares", 199, 4, 0, 8), 0)
 	registerInfoElement(*entities.NewInfoElement("mplsTopLabelTTL", 200, 1, 0, 1), 0)
 	registerInfoElement(*entities.NewInfoElement("mplsLabelStackLength", 201, 3, 0, 4), 0)
 	registerInfoElement(*entities.NewInfoElement("mplsLabelStackDepth", 202, 3, 0, 4), 0)
 	registerInfoElement(*entities.NewInfoElement("mplsTopLabelExp", 203, 1, 0, 1), 0)
-	registerInfoElement(*entities.NewInfoElement("ipPayloadLength", 204, 3, 0, 4), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("ipPayloadLength", 204, 3, 0, 4), "octets", "quantity"), 0)
 	registerInfoElement(*entities.NewInfoElement("udpMessageLength", 205, 2, 0, 2), 0)
 	registerInfoElement(*entities.NewInfoElement("isMulticast", 206, 1, 0, 1), 0)
 	registerInfoElement(*entities.NewInfoElement("ipv4IHL", 207, 1, 0, 1), 0)
@@ -218,7 +218,7 @@ func loadIANARegistry() {
 	registerInfoElement(*entities.NewInfoElement("tcpPshTotalCount", 221, 4, 0, 8), 0)
 	registerInfoElement(*entities.NewInfoElement("tcpAckTotalCount", 222, 4, 0, 8), 0)
 	registerInfoElement(*entities.NewInfoElement("tcpUrgTotalCount", 223, 4, 0, 8), 0)
-	registerInfoElement(*entities.NewInfoElement("ipTotalLength", 224, 4, 0, 8), 0)
+	registerInfoElement(*withMetadata(entities.NewInfoElement("ipTotalLength", 224, 4, 0, 8), "octets", "quantity"), 0)
 	registerInfoElement(*entities.NewInfoElement("postNATSourceIPv4Address", 225, 18, 0, 4), 0)
 	registerInfoElement(*entities.NewInfoElement("postNATDestinationIPv4Address", 226, 18, 0, 4), 0)
 	registerInfoElement(*entities.NewInfoElement("postNAPTSourceTransportPort", 227, 2, 0, 2), 0)
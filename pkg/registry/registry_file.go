@@ -0,0 +1,149 @@
+// Copyright 2026 VMware, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/vmware/go-ipfix/pkg/entities"
+)
+
+// LoadRegistryFromFile registers the information elements defined in the CSV
+// file at path, so that they can be looked up with GetInfoElement and
+// GetInfoElementFromID, e.g. to decode proprietary elements. LoadRegistry must
+// be called first. Every row defines an element with the columns:
+//
+//	elementID,name,dataType,enterpriseID,length
+//
+// where dataType is an abstract data type name of RFC7012, e.g. "unsigned32",
+// and length may be empty to use the default length of the data type. An
+// optional header row starting with "elementID" is skipped. The registry of
+// an enterprise is initialized if needed. Elements whose ID or name is already
+// registered for the enterprise are rejected, and no element of the file is
+// registered if any row is invalid.
+func LoadRegistryFromFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error when opening registry file: %v", err)
+	}
+	defer file.Close()
+	elements, err := parseRegistryCSV(file)
+	if err != nil {
+		return fmt.Errorf("error when parsing registry file %s: %v", path, err)
+	}
+	return registerCustomInfoElements(elements)
+}
+
+func parseRegistryCSV(r io.Reader) ([]*entities.InfoElement, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 5
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+	elements := make([]*entities.InfoElement, 0)
+	for isFirstRow := true; ; isFirstRow = false {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if isFirstRow && strings.EqualFold(row[0], "elementID") {
+			continue
+		}
+		element, err := parseRegistryRow(row)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		elements = append(elements, element)
+	}
+	return elements, nil
+}
+
+func parseRegistryRow(row []string) (*entities.InfoElement, error) {
+	elementID, err := strconv.ParseUint(row[0], 10, 15)
+	if err != nil {
+		return nil, fmt.Errorf("invalid element ID %q", row[0])
+	}
+	name := row[1]
+	if name == "" {
+		return nil, fmt.Errorf("empty name for element ID %d", elementID)
+	}
+	dataType := entities.IENameToType(row[2])
+	if dataType == entities.InvalidDataType {
+		return nil, fmt.Errorf("invalid data type %q for element %s", row[2], name)
+	}
+	enterpriseID, err := strconv.ParseUint(row[3], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid enterprise ID %q for element %s", row[3], name)
+	}
+	length := entities.InfoElementLength[dataType]
+	if row[4] != "" {
+		value, err := strconv.ParseUint(row[4], 10, 16)
+		if err != nil || value == 0 {
+			return nil, fmt.Errorf("invalid length %q for element %s", row[4], name)
+		}
+		if length != entities.VariableLength && uint16(value) > length {
+			return nil, fmt.Errorf("length %d for element %s exceeds the length %d of its data type", value, name, length)
+		}
+		length = uint16(value)
+	}
+	return entities.NewInfoElement(name, uint16(elementID), dataType, uint32(enterpriseID), length), nil
+}
+
+// registerCustomInfoElements checks that none of the elements is already
+// registered, or defined twice, before registering them.
+func registerCustomInfoElements(elements []*entities.InfoElement) error {
+	type elementKey struct {
+		enterpriseID uint32
+		elementID    uint16
+	}
+	ids := make(map[elementKey]string, len(elements))
+	names := make(map[uint32]map[string]bool)
+	for _, element := range elements {
+		key := elementKey{element.EnterpriseId, element.ElementId}
+		if name, exist := ids[key]; exist {
+			return fmt.Errorf("duplicate element ID %d with enterprise ID %d for elements %s and %s", element.ElementId, element.EnterpriseId, name, element.Name)
+		}
+		if existing, err := GetInfoElementFromID(element.ElementId, element.EnterpriseId); err == nil {
+			return fmt.Errorf("element ID %d with enterprise ID %d of element %s is already registered for element %s", element.ElementId, element.EnterpriseId, element.Name, existing.Name)
+		}
+		if names[element.EnterpriseId] == nil {
+			names[element.EnterpriseId] = make(map[string]bool)
+		}
+		if _, err := GetInfoElement(element.Name, element.EnterpriseId); err == nil || names[element.EnterpriseId][element.Name] {
+			return fmt.Errorf("element %s with enterprise ID %d is already registered", element.Name, element.EnterpriseId)
+		}
+		ids[key] = element.Name
+		names[element.EnterpriseId][element.Name] = true
+	}
+	for _, element := range elements {
+		if !HasRegistry(element.EnterpriseId) {
+			if err := InitNewRegistry(element.EnterpriseId); err != nil {
+				return err
+			}
+		}
+		if err := PutInfoElement(*element, element.EnterpriseId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -15,9 +15,14 @@
 package registry
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/vmware/go-ipfix/pkg/entities"
 )
@@ -76,3 +81,209 @@ func TestGetInfoElementFromID(t *testing.T) {
 	assert.Equal(t, "destinationNodeName", ie.Name, "TestGetInfoElementFromID does not return correct Antrea ie.")
 	assert.Equal(t, AntreaEnterpriseID, ie.EnterpriseId, "TestGetInfoElementFromID does not return correct Antrea ie.")
 }
+
+func TestHasInfoElement(t *testing.T) {
+	assert.True(t, HasInfoElement("sourceIPv4Address"))
+	assert.True(t, HasInfoElement("reverseOctetDeltaCount"))
+	assert.True(t, HasInfoElement("sourcePodName"))
+	assert.False(t, HasInfoElement("unknownElement"))
+}
+
+func TestInfoElementMetadata(t *testing.T) {
+	ie, err := GetInfoElement("octetDeltaCount", IANAEnterpriseID)
+	require.NoError(t, err)
+	assert.Equal(t, "octets", ie.Units)
+	assert.Equal(t, "deltaCounter", ie.Semantics)
+	ie, err = GetInfoElement("reverseOctetDeltaCount", IANAReversedEnterpriseID)
+	require.NoError(t, err)
+	assert.Equal(t, "octets", ie.Units)
+	ie, err = GetInfoElement("sourcePodName", AntreaEnterpriseID)
+	require.NoError(t, err)
+	assert.Empty(t, ie.Units)
+	assert.Empty(t, ie.Semantics)
+}
+
+func TestGetAggregationSemantic(t *testing.T) {
+	assert.Equal(t, AggregationSemanticMinimum, GetAggregationSemantic("minimumTTL"))
+	assert.Equal(t, AggregationSemanticMaximum, GetAggregationSemantic("maximumTTL"))
+	assert.Equal(t, AggregationSemanticNone, GetAggregationSemantic("octetDeltaCount"))
+}
+
+func TestDecodeVirtualStationElements(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		id       uint16
+		dataType entities.IEDataType
+	}{
+		{"virtualStationInterfaceId", 347, entities.OctetArray},
+		{"virtualStationInterfaceName", 348, entities.String},
+		{"virtualStationUUID", 349, entities.OctetArray},
+		{"virtualStationName", 350, entities.String},
+	} {
+		ie, err := GetInfoElementFromID(tc.id, IANAEnterpriseID)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.name, ie.Name)
+		assert.Equal(t, tc.dataType, ie.DataType)
+		assert.Equal(t, entities.VariableLength, ie.Len)
+	}
+	ie, err := GetInfoElement("virtualStationName", IANAEnterpriseID)
+	assert.NoError(t, err)
+	element, err := entities.DecodeAndCreateInfoElementWithValue(ie, []byte("vnf-firewall-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "vnf-firewall-1", element.GetStringValue())
+}
+
+func TestDecodeSamplerElements(t *testing.T) {
+	samplerMode, err := GetInfoElementFromID(49, IANAEnterpriseID)
+	assert.NoError(t, err)
+	assert.Equal(t, "samplerMode", samplerMode.Name)
+	assert.Equal(t, entities.Unsigned8, samplerMode.DataType)
+	element, err := entities.DecodeAndCreateInfoElementWithValue(samplerMode, []byte{2})
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(2), element.GetUnsigned8Value())
+
+	samplerRandomInterval, err := GetInfoElementFromID(50, IANAEnterpriseID)
+	assert.NoError(t, err)
+	assert.Equal(t, "samplerRandomInterval", samplerRandomInterval.Name)
+	assert.Equal(t, entities.Unsigned32, samplerRandomInterval.DataType)
+	assert.Equal(t, uint16(4), samplerRandomInterval.Len)
+	element, err = entities.DecodeAndCreateInfoElementWithValue(samplerRandomInterval, []byte{0, 0, 0x3, 0xe8})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1000), element.GetUnsigned32Value())
+}
+
+func TestDecodeTransportCounterElements(t *testing.T) {
+	for name, elementID := range map[string]uint16{
+		"transportOctetDeltaCount":  401,
+		"transportPacketDeltaCount": 402,
+	} {
+		element, err := GetInfoElementFromID(elementID, IANAEnterpriseID)
+		require.NoError(t, err)
+		assert.Equal(t, name, element.Name)
+		assert.Equal(t, entities.Unsigned64, element.DataType)
+		assert.Equal(t, uint16(8), element.Len)
+		reverseElement, err := GetInfoElement("reverse"+strings.ToUpper(name[:1])+name[1:], IANAReversedEnterpriseID)
+		require.NoError(t, err)
+		assert.Equal(t, elementID, reverseElement.ElementId)
+	}
+	transportOctetDeltaCount, err := GetInfoElement("transportOctetDeltaCount", IANAEnterpriseID)
+	require.NoError(t, err)
+	ie, err := entities.DecodeAndCreateInfoElementWithValue(transportOctetDeltaCount, []byte{0, 0, 0, 1, 0, 0, 0, 0})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1)<<32, ie.GetUnsigned64Value())
+	// Reduced-size encoding of the counter on 4 bytes.
+	reducedSize := entities.NewInfoElement(transportOctetDeltaCount.Name, transportOctetDeltaCount.ElementId, transportOctetDeltaCount.DataType, IANAEnterpriseID, 4)
+	ie, err = entities.DecodeAndCreateInfoElementWithValue(reducedSize, []byte{0, 0, 0x05, 0xdc})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1500), ie.GetUnsigned64Value())
+}
+
+func TestDecodeIPLengthElements(t *testing.T) {
+	for name, tc := range map[string]struct {
+		elementID uint16
+		dataType  entities.IEDataType
+		length    uint16
+	}{
+		"ipHeaderLength":    {189, entities.Unsigned8, 1},
+		"totalLengthIPv4":   {190, entities.Unsigned16, 2},
+		"payloadLengthIPv6": {191, entities.Unsigned16, 2},
+		"ipPayloadLength":   {204, entities.Unsigned32, 4},
+	} {
+		element, err := GetInfoElementFromID(tc.elementID, IANAEnterpriseID)
+		require.NoError(t, err)
+		assert.Equal(t, name, element.Name)
+		assert.Equal(t, tc.dataType, element.DataType)
+		assert.Equal(t, tc.length, element.Len)
+		assert.Equal(t, "quantity", element.Semantics)
+	}
+	payloadLengthIPv6, err := GetInfoElement("payloadLengthIPv6", IANAEnterpriseID)
+	require.NoError(t, err)
+	assert.Equal(t, "octets", payloadLengthIPv6.Units)
+	ie, err := entities.DecodeAndCreateInfoElementWithValue(payloadLengthIPv6, []byte{0x05, 0xb4})
+	require.NoError(t, err)
+	assert.Equal(t, uint16(1460), ie.GetUnsigned16Value())
+	// Reduced-size encoding of the length on 1 byte.
+	reducedSize := entities.NewInfoElement(payloadLengthIPv6.Name, payloadLengthIPv6.ElementId, payloadLengthIPv6.DataType, IANAEnterpriseID, 1)
+	ie, err = entities.DecodeAndCreateInfoElementWithValue(reducedSize, []byte{0xc8})
+	require.NoError(t, err)
+	assert.Equal(t, uint16(200), ie.GetUnsigned16Value())
+}
+
+func TestPutInfoElement(t *testing.T) {
+	customEnterpriseID := uint32(12345)
+	ie := entities.NewInfoElement("httpRequestTarget", 461, entities.String, customEnterpriseID, entities.VariableLength)
+	assert.Error(t, PutInfoElement(*ie, customEnterpriseID), "Registry should be initialized first")
+	assert.False(t, HasRegistry(customEnterpriseID))
+	assert.NoError(t, InitNewRegistry(customEnterpriseID))
+	assert.True(t, HasRegistry(customEnterpriseID))
+	assert.Error(t, PutInfoElement(*ie, IANAEnterpriseID), "EnterpriseID of the element should match the registry")
+	assert.NoError(t, PutInfoElement(*ie, customEnterpriseID))
+	assert.Error(t, PutInfoElement(*ie, customEnterpriseID), "Element should not be registered twice")
+	element, err := GetInfoElementFromID(461, customEnterpriseID)
+	assert.NoError(t, err)
+	assert.Equal(t, "httpRequestTarget", element.Name)
+}
+
+func TestLoadRegistryFromFile(t *testing.T) {
+	writeFile := func(content string) string {
+		path := filepath.Join(t.TempDir(), "registry.csv")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	vendorEnterpriseID := uint32(32473)
+	require.NoError(t, LoadRegistryFromFile(writeFile(`elementID,name,dataType,enterpriseID,length
+# vendor elements
+1,vendorSessionId,unsigned64,32473,
+2,vendorTenantName,string,32473,
+3,vendorQueueDepth,unsigned32,32473,2
+`)))
+	element, err := GetInfoElement("vendorSessionId", vendorEnterpriseID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.NewInfoElement("vendorSessionId", 1, entities.Unsigned64, vendorEnterpriseID, 8), element)
+	element, err = GetInfoElementFromID(2, vendorEnterpriseID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.NewInfoElement("vendorTenantName", 2, entities.String, vendorEnterpriseID, entities.VariableLength), element)
+	element, err = GetInfoElementFromID(3, vendorEnterpriseID)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(2), element.Len)
+
+	for name, tc := range map[string]struct {
+		content string
+		err     string
+	}{
+		"registered ID":     {"1,vendorOtherId,unsigned64,32473,\n", "element ID 1 with enterprise ID 32473 of element vendorOtherId is already registered for element vendorSessionId"},
+		"duplicate ID":      {"10,vendorA,unsigned8,32473,\n10,vendorB,unsigned8,32473,\n", "duplicate element ID 10 with enterprise ID 32473 for elements vendorA and vendorB"},
+		"registered name":   {"11,vendorTenantName,string,32473,\n", "element vendorTenantName with enterprise ID 32473 is already registered"},
+		"invalid data type": {"12,vendorC,uint8,32473,\n", `line 1: invalid data type "uint8" for element vendorC`},
+		"invalid length":    {"13,vendorD,unsigned16,32473,4\n", "length 4 for element vendorD exceeds the length 2 of its data type"},
+		"missing column":    {"14,vendorE,unsigned16,32473\n", "wrong number of fields"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorContains(t, LoadRegistryFromFile(writeFile(tc.content)), tc.err)
+		})
+	}
+	// No element of an invalid file is registered.
+	_, err = GetInfoElementFromID(10, vendorEnterpriseID)
+	assert.Error(t, err)
+}
+
+func TestDecodeObservationTimeElements(t *testing.T) {
+	for name, dataType := range map[string]entities.IEDataType{
+		"observationTimeSeconds":      entities.DateTimeSeconds,
+		"observationTimeMilliseconds": entities.DateTimeMilliseconds,
+		"observationTimeMicroseconds": entities.DateTimeMicroseconds,
+		"observationTimeNanoseconds":  entities.DateTimeNanoseconds,
+	} {
+		ie, err := GetInfoElement(name, IANAEnterpriseID)
+		assert.NoError(t, err)
+		assert.Equal(t, dataType, ie.DataType, "Unexpected data type for %s", name)
+	}
+	ie, err := GetInfoElement("observationTimeMilliseconds", IANAEnterpriseID)
+	assert.NoError(t, err)
+	// 2020-10-29T07:15:30.123Z
+	element, err := entities.DecodeAndCreateInfoElementWithValue(ie, []byte{0x0, 0x0, 0x1, 0x75, 0x73, 0x36, 0x26, 0xcb})
+	assert.NoError(t, err)
+	observationTime, err := entities.GetTimeValue(element)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 10, 29, 7, 15, 30, 123000000, time.UTC), observationTime.UTC())
+}
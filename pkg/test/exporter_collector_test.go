@@ -287,3 +287,461 @@ func matchDataRecordElements(t *testing.T, record entities.Record, isSrcNode, is
 		}
 	}
 }
+
+func TestExporterSamplingOptions(t *testing.T) {
+	address, err := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	cp, err := collector.InitCollectingProcess(collector.CollectorInput{
+		Address:       address.String(),
+		Protocol:      address.Network(),
+		MaxBufferSize: 1024,
+	})
+	require.NoError(t, err)
+	go cp.Start()
+	defer cp.Stop()
+	waitForCollectorReady(t, cp)
+	export, err := exporter.InitExportingProcess(exporter.ExporterInput{
+		CollectorAddress:    cp.GetAddress().String(),
+		CollectorProtocol:   cp.GetAddress().Network(),
+		ObservationDomainID: 1,
+		SamplingConfig: &exporter.SamplingConfig{
+			SelectorID:   7,
+			SamplingRate: 100,
+		},
+	})
+	require.NoError(t, err)
+	defer export.CloseConnToCollector()
+
+	templateMsg := <-cp.GetMsgChan()
+	assert.Equal(t, entities.OptionsTemplate, templateMsg.GetSet().GetSetType())
+	templateElements := templateMsg.GetSet().GetRecords()[0].GetOrderedElementList()
+	require.Len(t, templateElements, 4)
+	assert.Equal(t, "selectorId", templateElements[0].GetName())
+
+	dataMsg := <-cp.GetMsgChan()
+	record := dataMsg.GetSet().GetRecords()[0]
+	selectorID, _, exist := record.GetInfoElementWithValue("selectorId")
+	require.True(t, exist)
+	assert.Equal(t, uint64(7), selectorID.GetUnsigned64Value())
+	algorithm, _, exist := record.GetInfoElementWithValue("flowSelectorAlgorithm")
+	require.True(t, exist)
+	assert.Equal(t, exporter.FlowSelectorAlgorithmSystematicCountBased, algorithm.GetUnsigned16Value())
+	interval, _, exist := record.GetInfoElementWithValue("samplingPacketInterval")
+	require.True(t, exist)
+	assert.Equal(t, uint32(1), interval.GetUnsigned32Value())
+	space, _, exist := record.GetInfoElementWithValue("samplingPacketSpace")
+	require.True(t, exist)
+	assert.Equal(t, uint32(99), space.GetUnsigned32Value())
+	// The first template ID is used for the sampling options template.
+	assert.Equal(t, uint16(257), export.NewTemplateID())
+}
+
+func TestExporterObservationPointOptions(t *testing.T) {
+	address, err := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	cp, err := collector.InitCollectingProcess(collector.CollectorInput{
+		Address:         address.String(),
+		Protocol:        address.Network(),
+		MaxBufferSize:   1024,
+		MessageChanSize: 4,
+	})
+	require.NoError(t, err)
+	go cp.Start()
+	defer cp.Stop()
+	waitForCollectorReady(t, cp)
+	export, err := exporter.InitExportingProcess(exporter.ExporterInput{
+		CollectorAddress:    cp.GetAddress().String(),
+		CollectorProtocol:   cp.GetAddress().Network(),
+		ObservationDomainID: 1,
+	})
+	require.NoError(t, err)
+	defer export.CloseConnToCollector()
+
+	export.SetObservationPoint(42, "cluster-a")
+	templateID := export.NewTemplateID()
+	ie, err := registry.GetInfoElement("sourceIPv4Address", registry.IANAEnterpriseID)
+	require.NoError(t, err)
+	element, err := entities.DecodeAndCreateInfoElementWithValue(ie, nil)
+	require.NoError(t, err)
+	set := entities.NewSet(false)
+	require.NoError(t, set.PrepareSet(entities.Template, templateID))
+	require.NoError(t, set.AddRecord([]entities.InfoElementWithValue{element}, templateID))
+	_, err = export.SendSet(set)
+	require.NoError(t, err)
+
+	// The observation point options are sent before the first set.
+	templateMsg := <-cp.GetMsgChan()
+	assert.Equal(t, entities.OptionsTemplate, templateMsg.GetSet().GetSetType())
+	assert.Equal(t, uint16(256), templateMsg.GetSet().GetRecords()[0].GetTemplateID())
+	scopeFieldCount, isOptionsTemplate := cp.GetScopeFieldCount(1, 256)
+	assert.True(t, isOptionsTemplate)
+	assert.Equal(t, 1, scopeFieldCount)
+	record := (<-cp.GetMsgChan()).GetSet().GetRecords()[0]
+	obsDomainID, _, exist := record.GetInfoElementWithValue("observationDomainId")
+	require.True(t, exist)
+	assert.Equal(t, uint32(1), obsDomainID.GetUnsigned32Value())
+	pointID, _, exist := record.GetInfoElementWithValue("observationPointId")
+	require.True(t, exist)
+	assert.Equal(t, uint64(42), pointID.GetUnsigned64Value())
+	domainName, _, exist := record.GetInfoElementWithValue("observationDomainName")
+	require.True(t, exist)
+	assert.Equal(t, "cluster-a", domainName.GetStringValue())
+	assert.Equal(t, entities.Template, (<-cp.GetMsgChan()).GetSet().GetSetType())
+
+	// The options are only sent once.
+	dataSet := entities.NewSet(false)
+	require.NoError(t, dataSet.PrepareSet(entities.Data, templateID))
+	require.NoError(t, dataSet.AddRecord([]entities.InfoElementWithValue{entities.NewIPAddressInfoElement(ie, net.IP{1, 2, 3, 4})}, templateID))
+	_, err = export.SendSet(dataSet)
+	require.NoError(t, err)
+	assert.Equal(t, entities.Data, (<-cp.GetMsgChan()).GetSet().GetSetType())
+}
+
+func TestExporterSubTemplateList(t *testing.T) {
+	address, err := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	cp, err := collector.InitCollectingProcess(collector.CollectorInput{
+		Address:         address.String(),
+		Protocol:        address.Network(),
+		MaxBufferSize:   1024,
+		MessageChanSize: 4,
+	})
+	require.NoError(t, err)
+	go cp.Start()
+	defer cp.Stop()
+	waitForCollectorReady(t, cp)
+	export, err := exporter.InitExportingProcess(exporter.ExporterInput{
+		CollectorAddress:    cp.GetAddress().String(),
+		CollectorProtocol:   cp.GetAddress().Network(),
+		ObservationDomainID: 1,
+	})
+	require.NoError(t, err)
+	defer export.CloseConnToCollector()
+
+	getElements := func(names ...string) []*entities.InfoElement {
+		elements := make([]*entities.InfoElement, len(names))
+		for i, name := range names {
+			elements[i], err = registry.GetInfoElement(name, registry.IANAEnterpriseID)
+			require.NoError(t, err)
+		}
+		return elements
+	}
+	sendTemplate := func(templateID uint16, elements []*entities.InfoElement) {
+		templateElements := make([]entities.InfoElementWithValue, len(elements))
+		for i, element := range elements {
+			templateElements[i], err = entities.DecodeAndCreateInfoElementWithValue(element, nil)
+			require.NoError(t, err)
+		}
+		set := entities.NewSet(false)
+		require.NoError(t, set.PrepareSet(entities.Template, templateID))
+		require.NoError(t, set.AddRecord(templateElements, templateID))
+		_, err := export.SendSet(set)
+		require.NoError(t, err)
+	}
+	subElements := getElements("sourceTransportPort", "interfaceName")
+	elements := getElements("sourceIPv4Address", "subTemplateList")
+	subTemplateID := export.NewTemplateID()
+	templateID := export.NewTemplateID()
+	subTemplateList := &entities.SubTemplateListValue{
+		Semantic:   entities.SemanticAllOf,
+		TemplateID: subTemplateID,
+		Records: [][]entities.InfoElementWithValue{
+			{entities.NewUnsigned16InfoElement(subElements[0], 80), entities.NewStringInfoElement(subElements[1], "eth0")},
+			{entities.NewUnsigned16InfoElement(subElements[0], 443), entities.NewStringInfoElement(subElements[1], "eth1")},
+		},
+	}
+	dataSet := entities.NewSet(false)
+	require.NoError(t, dataSet.PrepareSet(entities.Data, templateID))
+	require.NoError(t, dataSet.AddRecord([]entities.InfoElementWithValue{
+		entities.NewIPAddressInfoElement(elements[0], net.ParseIP("10.0.0.1")),
+		entities.NewSubTemplateListInfoElement(elements[1], subTemplateList),
+	}, templateID))
+
+	// The data record cannot be sent before the referenced template.
+	sendTemplate(templateID, elements)
+	_, err = export.SendSet(dataSet)
+	assert.ErrorContains(t, err, "referenced by element subTemplateList does not exist")
+	sendTemplate(subTemplateID, subElements)
+	_, err = export.SendSet(dataSet)
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.Template, (<-cp.GetMsgChan()).GetSet().GetSetType())
+	assert.Equal(t, entities.Template, (<-cp.GetMsgChan()).GetSet().GetSetType())
+	record := (<-cp.GetMsgChan()).GetSet().GetRecords()[0]
+	ie, _, exist := record.GetInfoElementWithValue("subTemplateList")
+	require.True(t, exist)
+	decoded := ie.GetSubTemplateListValue()
+	require.NotNil(t, decoded)
+	assert.Equal(t, entities.SemanticAllOf, decoded.Semantic)
+	assert.Equal(t, subTemplateID, decoded.TemplateID)
+	assert.Equal(t, []map[string]interface{}{
+		{"sourceTransportPort": uint16(80), "interfaceName": "eth0"},
+		{"sourceTransportPort": uint16(443), "interfaceName": "eth1"},
+	}, record.GetElementMap()["subTemplateList"])
+}
+
+func TestExporterOriginalExporterRoundTrip(t *testing.T) {
+	address, err := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	cp, err := collector.InitCollectingProcess(collector.CollectorInput{
+		Address:         address.String(),
+		Protocol:        address.Network(),
+		MaxBufferSize:   1024,
+		MessageChanSize: 4,
+	})
+	require.NoError(t, err)
+	go cp.Start()
+	defer cp.Stop()
+	waitForCollectorReady(t, cp)
+	initExporter := func(obsDomainID uint32) *exporter.ExportingProcess {
+		export, err := exporter.InitExportingProcess(exporter.ExporterInput{
+			CollectorAddress:    cp.GetAddress().String(),
+			CollectorProtocol:   cp.GetAddress().Network(),
+			ObservationDomainID: obsDomainID,
+		})
+		require.NoError(t, err)
+		return export
+	}
+	sendRecord := func(export *exporter.ExportingProcess, templateID uint16, elements []entities.InfoElementWithValue) {
+		templateElements := make([]entities.InfoElementWithValue, len(elements))
+		for i, element := range elements {
+			var err error
+			templateElements[i], err = entities.DecodeAndCreateInfoElementWithValue(element.GetInfoElement(), nil)
+			require.NoError(t, err)
+		}
+		templateSet := entities.NewSet(false)
+		require.NoError(t, templateSet.PrepareSet(entities.Template, templateID))
+		require.NoError(t, templateSet.AddRecord(templateElements, templateID))
+		_, err := export.SendSet(templateSet)
+		require.NoError(t, err)
+		dataSet := entities.NewSet(false)
+		require.NoError(t, dataSet.PrepareSet(entities.Data, templateID))
+		require.NoError(t, dataSet.AddRecord(elements, templateID))
+		_, err = export.SendSet(dataSet)
+		require.NoError(t, err)
+	}
+	receiveData := func() *entities.Message {
+		assert.Equal(t, entities.Template, (<-cp.GetMsgChan()).GetSet().GetSetType())
+		return <-cp.GetMsgChan()
+	}
+
+	// The original exporter sends a flow, which is decoded by the mediator.
+	originalExporter := initExporter(5)
+	defer originalExporter.CloseConnToCollector()
+	element, err := registry.GetInfoElement("sourceIPv4Address", registry.IANAEnterpriseID)
+	require.NoError(t, err)
+	sendRecord(originalExporter, originalExporter.NewTemplateID(), []entities.InfoElementWithValue{
+		entities.NewIPAddressInfoElement(element, net.ParseIP("10.0.0.1")),
+	})
+	message := receiveData()
+
+	// The mediator re-exports the flow, stamped with the original exporter.
+	mediator := initExporter(1)
+	defer mediator.CloseConnToCollector()
+	elements, err := exporter.AppendOriginalExporterElements(message.GetSet().GetRecords()[0].GetOrderedElementList(), message)
+	require.NoError(t, err)
+	sendRecord(mediator, mediator.NewTemplateID(), elements)
+	message = receiveData()
+	assert.Equal(t, uint32(1), message.GetObsDomainID())
+	record := message.GetSet().GetRecords()[0]
+	assert.Equal(t, map[string]interface{}{
+		"sourceIPv4Address":           net.ParseIP("10.0.0.1").To4(),
+		"originalExporterIPv4Address": net.ParseIP("127.0.0.1").To4(),
+		"originalObservationDomainId": uint32(5),
+	}, record.GetElementMap())
+	originalAddress, originalObsDomainID, err := entities.GetOriginalExporter(record)
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", originalAddress.String())
+	assert.Equal(t, uint32(5), originalObsDomainID)
+}
+
+func TestExporterTemplateIDRecycling(t *testing.T) {
+	address, err := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	cp, err := collector.InitCollectingProcess(collector.CollectorInput{
+		Address:         address.String(),
+		Protocol:        address.Network(),
+		MaxBufferSize:   1024,
+		MessageChanSize: 8,
+	})
+	require.NoError(t, err)
+	go cp.Start()
+	defer cp.Stop()
+	waitForCollectorReady(t, cp)
+	export, err := exporter.InitExportingProcess(exporter.ExporterInput{
+		CollectorAddress:    cp.GetAddress().String(),
+		CollectorProtocol:   cp.GetAddress().Network(),
+		ObservationDomainID: 1,
+	})
+	require.NoError(t, err)
+	defer export.CloseConnToCollector()
+
+	sendTemplate := func(templateID uint16, names ...string) {
+		elements := make([]entities.InfoElementWithValue, len(names))
+		for i, name := range names {
+			ie, err := registry.GetInfoElement(name, registry.IANAEnterpriseID)
+			require.NoError(t, err)
+			elements[i], err = entities.DecodeAndCreateInfoElementWithValue(ie, nil)
+			require.NoError(t, err)
+		}
+		set := entities.NewSet(false)
+		require.NoError(t, set.PrepareSet(entities.Template, templateID))
+		require.NoError(t, set.AddRecord(elements, templateID))
+		_, err := export.SendSet(set)
+		require.NoError(t, err)
+	}
+	sendData := func(templateID uint16, elements ...entities.InfoElementWithValue) {
+		set := entities.NewSet(false)
+		require.NoError(t, set.PrepareSet(entities.Data, templateID))
+		require.NoError(t, set.AddRecord(elements, templateID))
+		_, err := export.SendSet(set)
+		require.NoError(t, err)
+	}
+	srcIPElement, _ := registry.GetInfoElement("sourceIPv4Address", registry.IANAEnterpriseID)
+	srcPortElement, _ := registry.GetInfoElement("sourceTransportPort", registry.IANAEnterpriseID)
+	dstPortElement, _ := registry.GetInfoElement("destinationTransportPort", registry.IANAEnterpriseID)
+
+	templateID := export.NewTemplateID()
+	require.Equal(t, uint16(256), templateID)
+	sendTemplate(templateID, "sourceIPv4Address")
+	sendData(templateID, entities.NewIPAddressInfoElement(srcIPElement, net.ParseIP("10.0.0.1").To4()))
+	assert.Equal(t, entities.Template, (<-cp.GetMsgChan()).GetSet().GetSetType())
+	assert.Equal(t, entities.Data, (<-cp.GetMsgChan()).GetSet().GetSetType())
+
+	require.NoError(t, export.WithdrawTemplate(templateID))
+	assert.Error(t, export.WithdrawTemplate(templateID), "Template should not be withdrawn twice")
+	withdrawal := (<-cp.GetMsgChan()).GetSet().GetRecords()[0]
+	assert.Equal(t, templateID, withdrawal.GetTemplateID())
+	assert.Empty(t, withdrawal.GetOrderedElementList())
+
+	// The withdrawn template ID is reused for a new definition.
+	assert.Equal(t, templateID, export.NewTemplateID())
+	sendTemplate(templateID, "sourceTransportPort", "destinationTransportPort")
+	sendData(templateID, entities.NewUnsigned16InfoElement(srcPortElement, 1234), entities.NewUnsigned16InfoElement(dstPortElement, 80))
+	templateRecord := (<-cp.GetMsgChan()).GetSet().GetRecords()[0]
+	assert.Len(t, templateRecord.GetOrderedElementList(), 2)
+	record := (<-cp.GetMsgChan()).GetSet().GetRecords()[0]
+	srcPort, _, exist := record.GetInfoElementWithValue("sourceTransportPort")
+	require.True(t, exist)
+	assert.Equal(t, uint16(1234), srcPort.GetUnsigned16Value())
+	dstPort, _, exist := record.GetInfoElementWithValue("destinationTransportPort")
+	require.True(t, exist)
+	assert.Equal(t, uint16(80), dstPort.GetUnsigned16Value())
+
+	// Redefining an active template ID sends the withdrawal first.
+	sendTemplate(templateID, "sourceIPv4Address")
+	withdrawal = (<-cp.GetMsgChan()).GetSet().GetRecords()[0]
+	assert.Empty(t, withdrawal.GetOrderedElementList())
+	templateRecord = (<-cp.GetMsgChan()).GetSet().GetRecords()[0]
+	assert.Len(t, templateRecord.GetOrderedElementList(), 1)
+	assert.Equal(t, uint16(257), export.NewTemplateID())
+}
+
+func TestExporterExportTimePerMessage(t *testing.T) {
+	address, err := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	cp, err := collector.InitCollectingProcess(collector.CollectorInput{
+		Address:         address.String(),
+		Protocol:        address.Network(),
+		MaxBufferSize:   1024,
+		MessageChanSize: 2,
+	})
+	require.NoError(t, err)
+	go cp.Start()
+	defer cp.Stop()
+	waitForCollectorReady(t, cp)
+	export, err := exporter.InitExportingProcess(exporter.ExporterInput{
+		CollectorAddress:    cp.GetAddress().String(),
+		CollectorProtocol:   cp.GetAddress().Network(),
+		ObservationDomainID: 1,
+	})
+	require.NoError(t, err)
+	defer export.CloseConnToCollector()
+
+	templateID := export.NewTemplateID()
+	_, err = export.SendSet(createTemplateSet(templateID, false))
+	require.NoError(t, err)
+	time.Sleep(time.Second)
+	_, err = export.SendSet(createDataSet(templateID, true, false, false))
+	require.NoError(t, err)
+
+	templateMsg := <-cp.GetMsgChan()
+	dataMsg := <-cp.GetMsgChan()
+	assert.Greater(t, dataMsg.GetExportTime(), templateMsg.GetExportTime())
+	assert.InDelta(t, time.Now().Unix(), int64(dataMsg.GetExportTime()), 1)
+}
+
+func TestExporterSendMixedRecords(t *testing.T) {
+	address, err := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	cp, err := collector.InitCollectingProcess(collector.CollectorInput{
+		Address:         address.String(),
+		Protocol:        address.Network(),
+		MaxBufferSize:   1024,
+		MessageChanSize: 4,
+	})
+	require.NoError(t, err)
+	go cp.Start()
+	defer cp.Stop()
+	waitForCollectorReady(t, cp)
+	export, err := exporter.InitExportingProcess(exporter.ExporterInput{
+		CollectorAddress:    cp.GetAddress().String(),
+		CollectorProtocol:   cp.GetAddress().Network(),
+		ObservationDomainID: 1,
+	})
+	require.NoError(t, err)
+	defer export.CloseConnToCollector()
+
+	srcIPElement, err := registry.GetInfoElement("sourceIPv4Address", registry.IANAEnterpriseID)
+	require.NoError(t, err)
+	srcPortElement, err := registry.GetInfoElement("sourceTransportPort", registry.IANAEnterpriseID)
+	require.NoError(t, err)
+	sendTemplate := func(element entities.InfoElementWithValue) uint16 {
+		templateID := export.NewTemplateID()
+		set := entities.NewSet(false)
+		require.NoError(t, set.PrepareSet(entities.Template, templateID))
+		require.NoError(t, set.AddRecord([]entities.InfoElementWithValue{element}, templateID))
+		_, err := export.SendSet(set)
+		require.NoError(t, err)
+		return templateID
+	}
+	ipTemplateID := sendTemplate(entities.NewIPAddressInfoElement(srcIPElement, nil))
+	portTemplateID := sendTemplate(entities.NewUnsigned16InfoElement(srcPortElement, 0))
+	assert.Equal(t, entities.Template, (<-cp.GetMsgChan()).GetSet().GetSetType())
+	assert.Equal(t, entities.Template, (<-cp.GetMsgChan()).GetSet().GetSetType())
+
+	createRecord := func(templateID uint16, element entities.InfoElementWithValue) exporter.MixedRecord {
+		record := entities.NewDataRecord(templateID, 1, 0, false)
+		require.NoError(t, record.AddInfoElement(element))
+		return exporter.MixedRecord{TemplateID: templateID, Record: record}
+	}
+	_, err = export.SendMixedRecords([]exporter.MixedRecord{
+		createRecord(ipTemplateID, entities.NewIPAddressInfoElement(srcIPElement, net.IP{1, 2, 3, 4})),
+		createRecord(portTemplateID, entities.NewUnsigned16InfoElement(srcPortElement, 80)),
+		createRecord(ipTemplateID, entities.NewIPAddressInfoElement(srcIPElement, net.IP{5, 6, 7, 8})),
+	})
+	require.NoError(t, err)
+
+	// The collecting process delivers a message for each data set.
+	ipMsg := <-cp.GetMsgChan()
+	records := ipMsg.GetSet().GetRecords()
+	require.Len(t, records, 2)
+	assert.Equal(t, ipTemplateID, records[0].GetTemplateID())
+	srcIP, _, exist := records[0].GetInfoElementWithValue("sourceIPv4Address")
+	require.True(t, exist)
+	assert.Equal(t, net.IP{1, 2, 3, 4}, srcIP.GetIPAddressValue())
+	srcIP, _, exist = records[1].GetInfoElementWithValue("sourceIPv4Address")
+	require.True(t, exist)
+	assert.Equal(t, net.IP{5, 6, 7, 8}, srcIP.GetIPAddressValue())
+	portMsg := <-cp.GetMsgChan()
+	records = portMsg.GetSet().GetRecords()
+	require.Len(t, records, 1)
+	assert.Equal(t, portTemplateID, records[0].GetTemplateID())
+	srcPort, _, exist := records[0].GetInfoElementWithValue("sourceTransportPort")
+	require.True(t, exist)
+	assert.Equal(t, uint16(80), srcPort.GetUnsigned16Value())
+	// The sequence number of the second set accounts for the records of the
+	// first one.
+	assert.Equal(t, ipMsg.GetSequenceNum()+2, portMsg.GetSequenceNum())
+	assert.Equal(t, int64(0), cp.GetNumMissingRecords())
+}